@@ -45,7 +45,7 @@ func ListQuery[T any](db *gorm.DB, model T, option Option) (list []T, count int6
 		for index, column := range option.Likes {
 			// 对于第一个条件使用Where，后续条件使用Or来连接。
 			if index == 0 {
-				likeQuery.Where(fmt.Sprintf("%s like ?"), fmt.Sprintf("%%%s%%", option.PageInfo.Key))
+				likeQuery.Where(fmt.Sprintf("%s like ?", column), fmt.Sprintf("%%%s%%", option.PageInfo.Key))
 			} else {
 				likeQuery.Or(fmt.Sprintf("%s like ?", column), fmt.Sprintf("%%%s%%", option.PageInfo.Key))
 			}
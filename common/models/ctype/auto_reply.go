@@ -0,0 +1,86 @@
+package ctype
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// 自动回复的时间范围
+const (
+	AutoReplyClose   int8 = 0 // 关闭
+	AutoReplyAlways  int8 = 1 // 始终回复
+	AutoReplyOffWork int8 = 2 // 工作时间以外回复
+	AutoReplyAway    int8 = 3 // 离开或者忙碌的时候回复
+)
+
+// AutoReply 自动回复设置
+type AutoReply struct {
+	Mode      int8   `json:"mode"`      // 0 关闭 1 始终回复 2 工作时间以外回复 3 离开或者忙碌的时候回复
+	Content   string `json:"content"`   // 回复内容
+	StartTime string `json:"startTime"` // 工作时间开始 例如 09:00
+	EndTime   string `json:"endTime"`   // 工作时间结束 例如 18:00，比开始时间早表示工作时间跨天
+}
+
+// Scan 取出来的时候的数据
+func (c *AutoReply) Scan(val interface{}) error {
+	return json.Unmarshal(val.([]byte), c)
+}
+
+// Value 入库的数据
+func (c AutoReply) Value() (driver.Value, error) {
+	b, err := json.Marshal(c)
+	return string(b), err
+}
+
+// Period 判断现在是否需要自动回复，需要的时候返回当前这一段时间的标识
+// 同一段时间里同一个会话只自动回复一次：始终回复按天算，工作时间以外按每一段下班时间算，离开或者忙碌按每一次设置状态算
+// 参数:
+//
+//	now: 当前时间
+//	presence: 用户的状态 0 在线 1 离开 2 忙碌
+//	presenceAt: 设置状态的时间
+func (c AutoReply) Period(now time.Time, presence int8, presenceAt *time.Time) (period string, ok bool) {
+	if c.Content == "" {
+		return "", false
+	}
+	switch c.Mode {
+	case AutoReplyAlways:
+		return "d" + now.Format("20060102"), true
+	case AutoReplyOffWork:
+		start, err1 := time.ParseInLocation("15:04", c.StartTime, now.Location())
+		end, err2 := time.ParseInLocation("15:04", c.EndTime, now.Location())
+		if err1 != nil || err2 != nil {
+			return "", false
+		}
+		minute := now.Hour()*60 + now.Minute()
+		startMinute := start.Hour()*60 + start.Minute()
+		endMinute := end.Hour()*60 + end.Minute()
+		if startMinute <= endMinute {
+			if minute >= startMinute && minute < endMinute {
+				return "", false
+			}
+			// 上班之前的这段时间属于前一天下班之后
+			if minute < startMinute {
+				return "o" + now.AddDate(0, 0, -1).Format("20060102"), true
+			}
+			return "o" + now.Format("20060102"), true
+		}
+		// 工作时间跨天，下班时间在同一天里
+		if minute >= startMinute || minute < endMinute {
+			return "", false
+		}
+		return "o" + now.Format("20060102"), true
+	case AutoReplyAway:
+		if presence == 0 {
+			return "", false
+		}
+		var at int64
+		if presenceAt != nil {
+			at = presenceAt.Unix()
+		}
+		return fmt.Sprintf("a%d", at), true
+	}
+	return "", false
+}
@@ -24,34 +24,54 @@ const (
 	TipMsgType
 	FriendOnlineMsgType
 	ImageTextMsgType
+	UserInfoChangeMsgType
+	NotificationMsgType
+	PreferenceChangeMsgType
+	ReadReceiptMsgType
+	AckMsgType
+	TypingMsgType
+	EditMsgType
+	ReactionMsgType
 )
 
 type Msg struct {
-	Type            MsgType          `json:"type"`                      // 消息类型 和msgType一模一样
-	TextMsg         *TextMsg         `json:"textMsg,omitempty"`         // 文本消息
-	ImageMsg        *ImageMsg        `json:"imageMsg,omitempty"`        // 图片消息
-	VideoMsg        *VideoMsg        `json:"videoMsg,omitempty"`        // 视频消息
-	FileMsg         *FileMsg         `json:"fileMsg,omitempty"`         // 文件消息
-	VoiceMsg        *VoiceMsg        `json:"voiceMsg,omitempty"`        // 语音消息
-	VoiceCallMsg    *VoiceCallMsg    `json:"voiceCallMsg,omitempty"`    // 语音通话
-	VideoCallMsg    *VideoCallMsg    `json:"videoCallMsg,omitempty"`    // 视频通话
-	WithdrawMsg     *WithdrawMsg     `json:"withdrawMsg,omitempty"`     // 撤回消息
-	ReplyMsg        *ReplyMsg        `json:"replyMsg,omitempty"`        // 回复消息
-	QuoteMsg        *QuoteMsg        `json:"quoteMsg,omitempty"`        // 引用消息
-	AtMsg           *AtMsg           `json:"atMsg,omitempty"`           // @用户的消息 群聊才有
-	TipMsg          *TipMsg          `json:"tipMsg,omitempty"`          // 提示消息 一般是不入库的
-	FriendOnlineMsg *FriendOnlineMsg `json:"friendOnlineMsg,omitempty"` // 好友上线提醒 不入库的
-	ImageTextMsg    *ImageTextMsg    `json:"imageTextMsg,omitempty"`    // 图文消息
+	Type                MsgType              `json:"type"`                          // 消息类型 和msgType一模一样
+	TextMsg             *TextMsg             `json:"textMsg,omitempty"`             // 文本消息
+	ImageMsg            *ImageMsg            `json:"imageMsg,omitempty"`            // 图片消息
+	VideoMsg            *VideoMsg            `json:"videoMsg,omitempty"`            // 视频消息
+	FileMsg             *FileMsg             `json:"fileMsg,omitempty"`             // 文件消息
+	VoiceMsg            *VoiceMsg            `json:"voiceMsg,omitempty"`            // 语音消息
+	VoiceCallMsg        *VoiceCallMsg        `json:"voiceCallMsg,omitempty"`        // 语音通话
+	VideoCallMsg        *VideoCallMsg        `json:"videoCallMsg,omitempty"`        // 视频通话
+	WithdrawMsg         *WithdrawMsg         `json:"withdrawMsg,omitempty"`         // 撤回消息
+	ReplyMsg            *ReplyMsg            `json:"replyMsg,omitempty"`            // 回复消息
+	QuoteMsg            *QuoteMsg            `json:"quoteMsg,omitempty"`            // 引用消息
+	AtMsg               *AtMsg               `json:"atMsg,omitempty"`               // @用户的消息 群聊才有
+	TipMsg              *TipMsg              `json:"tipMsg,omitempty"`              // 提示消息 一般是不入库的
+	FriendOnlineMsg     *FriendOnlineMsg     `json:"friendOnlineMsg,omitempty"`     // 好友上线提醒 不入库的
+	ImageTextMsg        *ImageTextMsg        `json:"imageTextMsg,omitempty"`        // 图文消息
+	UserInfoChangeMsg   *UserInfoChangeMsg   `json:"userInfoChangeMsg,omitempty"`   // 用户资料变更通知 不入库的
+	NotificationMsg     *NotificationMsg     `json:"notificationMsg,omitempty"`     // 通知中心的通知 不入库的
+	PreferenceChangeMsg *PreferenceChangeMsg `json:"preferenceChangeMsg,omitempty"` // 偏好设置变更 不入库的
+	ReadReceiptMsg      *ReadReceiptMsg      `json:"readReceiptMsg,omitempty"`      // 已读回执 不入库的
+	AckMsg              *AckMsg              `json:"ackMsg,omitempty"`              // 发送消息的ACK 不入库的
+	TypingMsg           *TypingMsg           `json:"typingMsg,omitempty"`           // 正在输入 不入库的
+	EditMsg             *EditMsg             `json:"editMsg,omitempty"`             // 编辑消息 编辑之后的内容写回原消息，自己不入库
+	ReactionMsg         *ReactionMsg         `json:"reactionMsg,omitempty"`         // 表情回应 回应单独入库，自己不入库
 }
 
 func (msg Msg) MsgPreview() string {
 	switch msg.Type {
 	case 1:
-		var runes = []rune(msg.TextMsg.Content)
+		content := msg.TextMsg.Content
+		var runes = []rune(content)
 		if len(runes) > 30 {
-			return string(runes[:30])
+			content = string(runes[:30])
 		}
-		return msg.TextMsg.Content
+		if msg.TextMsg.AutoReply {
+			return "[自动回复] " + content
+		}
+		return content
 	case 2:
 		return "[图片消息] - " + msg.ImageMsg.Title
 	case 3:
@@ -74,6 +94,16 @@ func (msg Msg) MsgPreview() string {
 		return "[@消息] - " + msg.AtMsg.Content
 	case 14:
 		return "[图文消息]"
+	case 21:
+		if msg.EditMsg != nil && msg.EditMsg.Msg != nil {
+			return "[编辑消息] - " + msg.EditMsg.Msg.MsgPreview()
+		}
+		return "[编辑消息]"
+	case 22:
+		if msg.ReactionMsg != nil {
+			return "[表情回应] " + msg.ReactionMsg.Emoji
+		}
+		return "[表情回应]"
 	}
 	return "[未知消息]"
 }
@@ -104,7 +134,16 @@ func (msg Msg) Validate() error {
 			return errors.New("图文消息不能为空")
 		}
 		return msg.ImageTextMsg.Validate()
-
+	case EditMsgType:
+		if msg.EditMsg == nil {
+			return errors.New("编辑消息不能为空")
+		}
+		return msg.EditMsg.Validate()
+	case ReactionMsgType:
+		if msg.ReactionMsg == nil {
+			return errors.New("表情回应不能为空")
+		}
+		return msg.ReactionMsg.Validate()
 	}
 	return nil
 }
@@ -118,8 +157,16 @@ func (c Msg) Value() (driver.Value, error) {
 	return string(b), err
 }
 
+// Equal 两条消息的内容是否一样，按入库的json比较
+func (c Msg) Equal(other Msg) bool {
+	b1, _ := json.Marshal(c)
+	b2, _ := json.Marshal(other)
+	return string(b1) == string(b2)
+}
+
 type TextMsg struct {
-	Content string `json:"content"`
+	Content   string `json:"content"`
+	AutoReply bool   `json:"autoReply,omitempty"` // 是否为自动回复，由服务端发送，客户端传的会被忽略
 }
 
 func (t TextMsg) Validate() error {
@@ -243,6 +290,120 @@ type FriendOnlineMsg struct {
 	Content  string `json:"content"`  // 内容
 	FriendID uint   `json:"friendID"` // 好友id
 }
+type UserInfoChangeMsg struct {
+	UserID   uint   `json:"userID"`   // 资料变更的用户id
+	NickName string `json:"nickName"` // 新的昵称
+	Avatar   string `json:"avatar"`   // 新的头像
+}
+type NotificationMsg struct {
+	ID          uint   `json:"id"`          // 通知id
+	Type        int8   `json:"type"`        // 通知类型 1 好友请求 2 好友请求处理结果 3 加群申请 4 加群申请处理结果 5 系统通知
+	SourceID    uint   `json:"sourceID"`    // 来源id 好友验证id或者群验证id
+	SendUserID  uint   `json:"sendUserID"`  // 触发通知的用户id 系统通知为0
+	GroupID     uint   `json:"groupID"`     // 相关的群id
+	Title       string `json:"title"`       // 标题
+	Content     string `json:"content"`     // 内容
+	UnreadCount int64  `json:"unreadCount"` // 当前未读通知总数
+}
+type PreferenceChangeMsg struct {
+	Namespace string           `json:"namespace"` // 命名空间
+	Version   int64            `json:"version"`   // 修改之后的偏好版本，和本地版本一样的设备就是发起修改的设备
+	Items     []PreferenceItem `json:"items"`     // 修改和删除的配置项
+}
+type PreferenceItem struct {
+	Key       string `json:"key"`       // 配置项
+	Value     string `json:"value"`     // 配置值
+	IsRemoved bool   `json:"isRemoved"` // 是否删除
+}
+type ReadReceiptMsg struct {
+	UserID         uint   `json:"userID"`         // 已读的用户id
+	TargetID       uint   `json:"targetID"`       // 会话的对方用户id
+	ConversationID string `json:"conversationID"` // 私聊会话id
+	Seq            int64  `json:"seq"`            // 已读到的消息序号
+}
+type AckMsg struct {
+	ClientMsgID    string `json:"clientMsgID"`    // 客户端生成的消息id
+	Status         string `json:"status"`         // 状态  success 成功  error 失败
+	Content        string `json:"content"`        // 失败的原因
+	MsgID          uint   `json:"msgID"`          // 服务端的消息id
+	ConversationID string `json:"conversationID"` // 私聊会话id
+	Seq            int64  `json:"seq"`            // 会话内的消息序号
+}
+type TypingMsg struct {
+	UserID  uint `json:"userID"`  // 正在输入的用户id
+	GroupID uint `json:"groupID"` // 群聊的时候为群id
+	Typing  bool `json:"typing"`  // true 正在输入 false 停止输入
+	Expire  int  `json:"expire"`  // 超过多少秒没有新的事件，客户端自动隐藏正在输入
+}
+
+// EditMsg 编辑消息，只能编辑文本消息和图文消息，不能修改消息的类型
+type EditMsg struct {
+	MsgID    uint      `json:"msgID"`    // 需要编辑的消息id 入参必填
+	Msg      *Msg      `json:"msg"`      // 编辑之后的消息 入参必填
+	Revision int       `json:"revision"` // 编辑之后的版本号，第一次编辑为1
+	EditedAt time.Time `json:"editedAt"` // 编辑的时间
+	IsLatest bool      `json:"isLatest"` // 是否是会话的最后一条消息，是的时候客户端同时刷新会话列表的消息预览
+	Seq      int64     `json:"seq"`      // 这次编辑分配的会话序号，和新消息共用一个序号，离线同步的时候按它返回编辑过的消息
+}
+
+func (t EditMsg) Validate() error {
+	if t.MsgID == 0 {
+		return errors.New("编辑消息id不能为空")
+	}
+	if t.Msg == nil {
+		return errors.New("请输入编辑之后的消息")
+	}
+	if t.Msg.Type != TextMsgType && t.Msg.Type != ImageTextMsgType {
+		return errors.New("只能编辑文本消息和图文消息")
+	}
+	return t.Msg.Validate()
+}
+
+// ReactionMsg 表情回应，添加或取消之后推送这条消息最新的回应汇总
+type ReactionMsg struct {
+	MsgID     uint       `json:"msgID"`     // 回应的消息id 入参必填
+	Emoji     string     `json:"emoji"`     // 表情 入参必填
+	IsRemove  bool       `json:"isRemove"`  // true 取消回应 false 添加回应
+	UserID    uint       `json:"userID"`    // 回应的用户id
+	Reactions []Reaction `json:"reactions"` // 这条消息最新的回应汇总
+}
+
+func (t ReactionMsg) Validate() error {
+	if t.MsgID == 0 {
+		return errors.New("回应的消息id不能为空")
+	}
+	if t.Emoji == "" {
+		return errors.New("请选择表情")
+	}
+	if len(t.Emoji) > 32 {
+		return errors.New("表情的格式错误")
+	}
+	return nil
+}
+
+// Reaction 一条消息上同一个表情的回应汇总
+type Reaction struct {
+	Emoji      string `json:"emoji"`      // 表情
+	Count      int    `json:"count"`      // 回应的人数
+	UserIDList []uint `json:"userIDList"` // 回应的用户id，按回应的先后
+}
+
+// AppendReaction 把一个用户的回应汇总进列表，表情按第一次出现的先后排列
+func AppendReaction(list []Reaction, emoji string, userID uint) []Reaction {
+	for i := range list {
+		if list[i].Emoji == emoji {
+			list[i].Count++
+			list[i].UserIDList = append(list[i].UserIDList, userID)
+			return list
+		}
+	}
+	return append(list, Reaction{
+		Emoji:      emoji,
+		Count:      1,
+		UserIDList: []uint{userID},
+	})
+}
+
 type ImageTextMsg struct {
 	Content string `json:"content"` // 内容
 }
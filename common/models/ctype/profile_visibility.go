@@ -0,0 +1,38 @@
+package ctype
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// ProfileVisibility 扩展资料的可见范围 0 所有人可见 1 仅好友可见 2 仅自己可见
+type ProfileVisibility struct {
+	Gender     int8 `json:"gender"`
+	Birthday   int8 `json:"birthday"`
+	Region     int8 `json:"region"`
+	Signature  int8 `json:"signature"`
+	CoverImage int8 `json:"coverImage"`
+	Addr       int8 `json:"addr"` // IP归属地
+}
+
+// Scan 取出来的时候的数据
+func (c *ProfileVisibility) Scan(val interface{}) error {
+	return json.Unmarshal(val.([]byte), c)
+}
+
+// Value 入库的数据
+func (c ProfileVisibility) Value() (driver.Value, error) {
+	b, err := json.Marshal(c)
+	return string(b), err
+}
+
+// Visible 判断某个可见范围对查看者是否可见
+func (c ProfileVisibility) Visible(level int8, isFriend bool) bool {
+	switch level {
+	case 0:
+		return true
+	case 1:
+		return isFriend
+	}
+	return false
+}
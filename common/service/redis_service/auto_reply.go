@@ -0,0 +1,34 @@
+package redis_service
+
+import (
+	"fmt"
+	"github.com/go-redis/redis"
+	"strings"
+	"time"
+)
+
+// MarkAutoReply 记录这个会话在这段时间里已经自动回复过，同一段时间里只有第一次调用返回true
+// 按天和按下班时间算的时间段不超过一天，记录保留24小时
+// 离开和忙碌的时间段可能持续很多天，记录一直保留到用户修改状态的时候由 ClearAwayAutoReply 清除
+// 参数:
+// - client: Redis客户端实例。
+// - revUserID: 设置了自动回复的用户ID。
+// - sendUserID: 发消息过来的用户ID。
+// - period: 时间段的标识，离开和忙碌的时间段以 "a" 开头。
+func MarkAutoReply(client *redis.Client, revUserID, sendUserID uint, period string) bool {
+	if strings.HasPrefix(period, "a") {
+		ok, err := client.HSetNX(fmt.Sprintf("auto_reply_away:%d", revUserID), fmt.Sprintf("%d", sendUserID), period).Result()
+		return err == nil && ok
+	}
+	key := fmt.Sprintf("auto_reply:%d:%d:%s", revUserID, sendUserID, period)
+	ok, err := client.SetNX(key, 1, 24*time.Hour).Result()
+	return err == nil && ok
+}
+
+// ClearAwayAutoReply 用户修改状态之后清除离开和忙碌时的自动回复记录，下一次离开或者忙碌重新回复
+// 参数:
+// - client: Redis客户端实例。
+// - userID: 修改状态的用户ID。
+func ClearAwayAutoReply(client *redis.Client, userID uint) {
+	client.Del(fmt.Sprintf("auto_reply_away:%d", userID))
+}
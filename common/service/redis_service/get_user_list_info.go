@@ -0,0 +1,121 @@
+package redis_service
+
+import (
+	"context"
+	"encoding/json"
+	"fim/common/models/ctype"
+	"fim/fim_user/user_rpc/types/user_rpc"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/syncx"
+)
+
+// userListInfoFlight 合并并发的相同批量查询，比如一个群的多个成员同时拉取历史消息
+var userListInfoFlight = syncx.NewSingleFlight()
+
+// GetUserListInfo 批量获取用户基本信息
+// 先用pipeline一次性从Redis读取缓存，没有命中的用户通过一次UserListInfo调用从数据库查询，再回写缓存
+// 返回的map中不包含不存在的用户
+func GetUserListInfo(client *redis.Client, userRpc user_rpc.UsersClient, userIDList []uint) (userInfoMap map[uint]ctype.UserInfo, err error) {
+	// 去重
+	var idList []uint
+	idSet := map[uint]bool{}
+	for _, userID := range userIDList {
+		if idSet[userID] {
+			continue
+		}
+		idSet[userID] = true
+		idList = append(idList, userID)
+	}
+	userInfoMap = make(map[uint]ctype.UserInfo, len(idList))
+	if len(idList) == 0 {
+		return
+	}
+
+	// pipeline批量读取缓存
+	pipe := client.Pipeline()
+	cmdList := make([]*redis.StringCmd, len(idList))
+	for i, userID := range idList {
+		cmdList[i] = pipe.Get(fmt.Sprintf("fim_server_uers_%d", userID))
+	}
+	pipe.Exec()
+
+	var missList []uint
+	for i, cmd := range cmdList {
+		str, err1 := cmd.Result()
+		if err1 != nil {
+			missList = append(missList, idList[i])
+			continue
+		}
+		var userInfo ctype.UserInfo
+		if json.Unmarshal([]byte(str), &userInfo) != nil {
+			missList = append(missList, idList[i])
+			continue
+		}
+		userInfoMap[idList[i]] = userInfo
+	}
+	if len(missList) == 0 {
+		return
+	}
+
+	missMap, err := getUserListInfoFromRpc(client, userRpc, missList)
+	if err != nil {
+		return
+	}
+	for userID, userInfo := range missMap {
+		userInfoMap[userID] = userInfo
+	}
+	return
+}
+
+// getUserListInfoFromRpc 查询缓存没有命中的用户，相同的查询同时只会执行一次
+func getUserListInfoFromRpc(client *redis.Client, userRpc user_rpc.UsersClient, missList []uint) (map[uint]ctype.UserInfo, error) {
+	sort.Slice(missList, func(i, j int) bool { return missList[i] < missList[j] })
+	var keyList []string
+	for _, userID := range missList {
+		keyList = append(keyList, fmt.Sprint(userID))
+	}
+
+	val, err := userListInfoFlight.Do(strings.Join(keyList, ","), func() (any, error) {
+		var idList []uint32
+		for _, userID := range missList {
+			idList = append(idList, uint32(userID))
+		}
+		response, err := userRpc.UserListInfo(context.Background(), &user_rpc.UserListInfoRequest{
+			UserIdList: idList,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		missMap := make(map[uint]ctype.UserInfo, len(response.UserInfo))
+		if len(response.UserInfo) == 0 {
+			return missMap, nil
+		}
+		pipe := client.Pipeline()
+		for userID, info := range response.UserInfo {
+			userInfo := ctype.UserInfo{
+				ID:       uint(userID),
+				NickName: info.NickName,
+				Avatar:   info.Avatar,
+			}
+			missMap[uint(userID)] = userInfo
+			byteData, _ := json.Marshal(userInfo)
+			pipe.Set(fmt.Sprintf("fim_server_uers_%d", userID), string(byteData), time.Hour)
+		}
+		_, err = pipe.Exec()
+		if err != nil {
+			logx.Error(err)
+		}
+		return missMap, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return val.(map[uint]ctype.UserInfo), nil
+}
@@ -0,0 +1,39 @@
+package redis_service
+
+import (
+	"fmt"
+	"github.com/go-redis/redis"
+	"github.com/zeromicro/go-zero/core/logx"
+	"strconv"
+	"time"
+)
+
+// SetLogout 让用户在这之前签发的登录全部失效，例如停用和封禁账号的时候
+// 记录的是失效的时间，恢复账号之后也不删除，之前签发的token一直无效，只有重新登录拿到的token可以使用
+// 参数:
+// - client: Redis客户端实例。
+// - userID: 用户ID。
+func SetLogout(client *redis.Client, userID uint) {
+	err := client.Set(fmt.Sprintf("logout:%d", userID), time.Now().Unix(), 0).Err()
+	if err != nil {
+		logx.Error(err)
+	}
+}
+
+// IsLogout 判断token是否已经失效，在用户最近一次失效时间之前（同一秒也算）签发的token都失效
+// 参数:
+// - client: Redis客户端实例。
+// - userID: 用户ID。
+// - issuedAt: token的签发时间，没有签发时间的旧token按失效处理。
+func IsLogout(client *redis.Client, userID uint, issuedAt time.Time) bool {
+	val, err := client.Get(fmt.Sprintf("logout:%d", userID)).Result()
+	if err != nil {
+		return false
+	}
+	logoutAt, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		// 以前写入的标记没有时间，按全部失效处理
+		return true
+	}
+	return issuedAt.Unix() <= logoutAt
+}
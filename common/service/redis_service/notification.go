@@ -0,0 +1,50 @@
+package redis_service
+
+import (
+	"encoding/json"
+	"fim/common/models/ctype"
+	"github.com/go-redis/redis"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// notificationChannel 通知中心的发布订阅频道
+const notificationChannel = "fim_notification"
+
+type notificationPayload struct {
+	UserID uint                  `json:"userID"`
+	Msg    ctype.NotificationMsg `json:"msg"`
+}
+
+// PublishNotification 发布一条新的通知，由持有用户websocket连接的节点推送给用户
+// 参数:
+// - client: Redis客户端实例。
+// - userID: 接收通知的用户ID。
+// - msg: 通知内容。
+func PublishNotification(client *redis.Client, userID uint, msg ctype.NotificationMsg) {
+	byteData, _ := json.Marshal(notificationPayload{
+		UserID: userID,
+		Msg:    msg,
+	})
+	err := client.Publish(notificationChannel, string(byteData)).Err()
+	if err != nil {
+		logx.Error(err)
+	}
+}
+
+// SubscribeNotification 订阅通知，每收到一条通知调用一次handler，会一直阻塞
+// 参数:
+// - client: Redis客户端实例。
+// - handler: 处理通知的函数。
+func SubscribeNotification(client *redis.Client, handler func(userID uint, msg ctype.NotificationMsg)) {
+	pubSub := client.Subscribe(notificationChannel)
+	defer pubSub.Close()
+	for msg := range pubSub.Channel() {
+		var payload notificationPayload
+		err := json.Unmarshal([]byte(msg.Payload), &payload)
+		if err != nil {
+			logx.Error(err)
+			continue
+		}
+		handler(payload.UserID, payload.Msg)
+	}
+}
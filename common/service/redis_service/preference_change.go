@@ -0,0 +1,50 @@
+package redis_service
+
+import (
+	"encoding/json"
+	"fim/common/models/ctype"
+	"github.com/go-redis/redis"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// preferenceChangeChannel 偏好设置变更的发布订阅频道
+const preferenceChangeChannel = "fim_preference_change"
+
+type preferenceChangePayload struct {
+	UserID uint                      `json:"userID"`
+	Msg    ctype.PreferenceChangeMsg `json:"msg"`
+}
+
+// PublishPreferenceChange 用户的偏好设置变更后，通知所有节点推送给这个用户在线的设备
+// 参数:
+// - client: Redis客户端实例。
+// - userID: 偏好设置变更的用户ID。
+// - msg: 变更的内容。
+func PublishPreferenceChange(client *redis.Client, userID uint, msg ctype.PreferenceChangeMsg) {
+	byteData, _ := json.Marshal(preferenceChangePayload{
+		UserID: userID,
+		Msg:    msg,
+	})
+	err := client.Publish(preferenceChangeChannel, string(byteData)).Err()
+	if err != nil {
+		logx.Error(err)
+	}
+}
+
+// SubscribePreferenceChange 订阅偏好设置变更，每收到一次变更调用一次handler，会一直阻塞
+// 参数:
+// - client: Redis客户端实例。
+// - handler: 处理偏好设置变更的函数。
+func SubscribePreferenceChange(client *redis.Client, handler func(userID uint, msg ctype.PreferenceChangeMsg)) {
+	pubSub := client.Subscribe(preferenceChangeChannel)
+	defer pubSub.Close()
+	for msg := range pubSub.Channel() {
+		var payload preferenceChangePayload
+		err := json.Unmarshal([]byte(msg.Payload), &payload)
+		if err != nil {
+			logx.Error(err)
+			continue
+		}
+		handler(payload.UserID, payload.Msg)
+	}
+}
@@ -0,0 +1,50 @@
+package redis_service
+
+import (
+	"encoding/json"
+	"fim/common/models/ctype"
+	"github.com/go-redis/redis"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// readReceiptChannel 已读回执的发布订阅频道
+const readReceiptChannel = "fim_read_receipt"
+
+type readReceiptPayload struct {
+	UserID uint                 `json:"userID"`
+	Msg    ctype.ReadReceiptMsg `json:"msg"`
+}
+
+// PublishReadReceipt 发布一条已读回执，由持有用户websocket连接的节点推送给用户
+// 参数:
+// - client: Redis客户端实例。
+// - userID: 接收回执的用户ID，可以是消息的发送者，也可以是已读用户自己的其他设备。
+// - msg: 已读回执。
+func PublishReadReceipt(client *redis.Client, userID uint, msg ctype.ReadReceiptMsg) {
+	byteData, _ := json.Marshal(readReceiptPayload{
+		UserID: userID,
+		Msg:    msg,
+	})
+	err := client.Publish(readReceiptChannel, string(byteData)).Err()
+	if err != nil {
+		logx.Error(err)
+	}
+}
+
+// SubscribeReadReceipt 订阅已读回执，每收到一条回执调用一次handler，会一直阻塞
+// 参数:
+// - client: Redis客户端实例。
+// - handler: 处理回执的函数。
+func SubscribeReadReceipt(client *redis.Client, handler func(userID uint, msg ctype.ReadReceiptMsg)) {
+	pubSub := client.Subscribe(readReceiptChannel)
+	defer pubSub.Close()
+	for msg := range pubSub.Channel() {
+		var payload readReceiptPayload
+		err := json.Unmarshal([]byte(msg.Payload), &payload)
+		if err != nil {
+			logx.Error(err)
+			continue
+		}
+		handler(payload.UserID, payload.Msg)
+	}
+}
@@ -0,0 +1,40 @@
+package redis_service
+
+import (
+	"fmt"
+	"github.com/go-redis/redis"
+	"github.com/zeromicro/go-zero/core/logx"
+	"strconv"
+)
+
+// userInfoChangeChannel 用户资料变更的发布订阅频道
+const userInfoChangeChannel = "fim_user_info_change"
+
+// PublishUserInfoChange 用户资料变更后清除用户信息缓存，并通知所有节点刷新内存中的用户信息
+// 参数:
+// - client: Redis客户端实例。
+// - userID: 资料变更的用户ID。
+func PublishUserInfoChange(client *redis.Client, userID uint) {
+	client.Del(fmt.Sprintf("fim_server_uers_%d", userID))
+	err := client.Publish(userInfoChangeChannel, strconv.Itoa(int(userID))).Err()
+	if err != nil {
+		logx.Error(err)
+	}
+}
+
+// SubscribeUserInfoChange 订阅用户资料变更，每收到一个用户ID调用一次handler，会一直阻塞
+// 参数:
+// - client: Redis客户端实例。
+// - handler: 处理资料变更的函数。
+func SubscribeUserInfoChange(client *redis.Client, handler func(userID uint)) {
+	pubSub := client.Subscribe(userInfoChangeChannel)
+	defer pubSub.Close()
+	for msg := range pubSub.Channel() {
+		userID, err := strconv.Atoi(msg.Payload)
+		if err != nil {
+			logx.Error(err)
+			continue
+		}
+		handler(uint(userID))
+	}
+}
@@ -0,0 +1,168 @@
+package redis_service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redis"
+	"github.com/zeromicro/go-zero/core/logx"
+	"os"
+	"time"
+)
+
+// wsRouteExpire 用户连接路由的过期时间，节点异常退出的时候路由最多残留这么久
+// 转发到已经退出的节点不会有任何副作用，只是没有节点接收
+const wsRouteExpire = 3 * time.Minute
+
+// wsRoutePayload 转发给一个节点的推送，同一份内容发给这个节点上的多个用户的时候只发布一次
+type wsRoutePayload struct {
+	UserIDList []uint          `json:"userIDList"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// WsRouter websocket连接的路由，记录每个用户的连接在哪些节点上，把推送转发到持有连接的节点
+// 每个节点有自己的订阅频道，聊天服务和群聊服务的连接分开记录
+type WsRouter struct {
+	client  *redis.Client
+	service string
+	NodeID  string // 当前节点的id，每次启动都不一样
+}
+
+// NewWsRouter 创建当前节点的路由
+// 参数:
+// - client: Redis客户端实例。
+// - service: 服务名，例如 chat group。
+func NewWsRouter(client *redis.Client, service string) *WsRouter {
+	hostname, _ := os.Hostname()
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return &WsRouter{
+		client:  client,
+		service: service,
+		NodeID:  fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(b)),
+	}
+}
+
+func (r *WsRouter) routeKey(userID uint) string {
+	return fmt.Sprintf("ws_route:%s:%d", r.service, userID)
+}
+
+func (r *WsRouter) channel(nodeID string) string {
+	return fmt.Sprintf("fim_ws_node:%s:%s", r.service, nodeID)
+}
+
+// Online 用户在当前节点上建立了第一个连接
+func (r *WsRouter) Online(userID uint) {
+	key := r.routeKey(userID)
+	pipe := r.client.Pipeline()
+	pipe.SAdd(key, r.NodeID)
+	pipe.Expire(key, wsRouteExpire)
+	_, err := pipe.Exec()
+	if err != nil {
+		logx.Error(err)
+	}
+}
+
+// Offline 用户在当前节点上的连接全部断开
+// 返回值:
+// - online: 用户在其他节点上是否还有连接
+func (r *WsRouter) Offline(userID uint) (online bool) {
+	key := r.routeKey(userID)
+	err := r.client.SRem(key, r.NodeID).Err()
+	if err != nil {
+		logx.Error(err)
+	}
+	count, _ := r.client.SCard(key).Result()
+	return count > 0
+}
+
+// IsOnline 用户在任意节点上是否有连接
+func (r *WsRouter) IsOnline(userID uint) bool {
+	count, _ := r.client.SCard(r.routeKey(userID)).Result()
+	return count > 0
+}
+
+// KeepAlive 定时刷新当前节点上在线用户的路由过期时间，会一直阻塞
+// 参数:
+// - userIDList: 返回当前节点上在线的用户id。
+func (r *WsRouter) KeepAlive(userIDList func() []uint) {
+	ticker := time.NewTicker(wsRouteExpire / 3)
+	defer ticker.Stop()
+	for range ticker.C {
+		pipe := r.client.Pipeline()
+		for _, userID := range userIDList() {
+			key := r.routeKey(userID)
+			pipe.SAdd(key, r.NodeID)
+			pipe.Expire(key, wsRouteExpire)
+		}
+		_, err := pipe.Exec()
+		if err != nil && err != redis.Nil {
+			logx.Error(err)
+		}
+	}
+}
+
+// Forward 把推送转发给其他节点上这些用户的连接，当前节点上的连接由调用方直接推送
+// 所有用户的路由用一次pipeline查出来，按节点分组之后每个节点只发布一次，群聊推送的时候不会按成员数放大
+// 参数:
+// - userIDList: 接收推送的用户id。
+// - byteData: 推送的内容。
+func (r *WsRouter) Forward(userIDList []uint, byteData []byte) {
+	if len(userIDList) == 0 {
+		return
+	}
+	pipe := r.client.Pipeline()
+	cmdList := make([]*redis.StringSliceCmd, 0, len(userIDList))
+	for _, userID := range userIDList {
+		cmdList = append(cmdList, pipe.SMembers(r.routeKey(userID)))
+	}
+	_, err := pipe.Exec()
+	if err != nil && err != redis.Nil {
+		logx.Error(err)
+		return
+	}
+	var nodeUserMap = map[string][]uint{}
+	for i, cmd := range cmdList {
+		for _, nodeID := range cmd.Val() {
+			if nodeID == r.NodeID {
+				continue
+			}
+			nodeUserMap[nodeID] = append(nodeUserMap[nodeID], userIDList[i])
+		}
+	}
+	if len(nodeUserMap) == 0 {
+		return
+	}
+	pipe = r.client.Pipeline()
+	for nodeID, nodeUserIDList := range nodeUserMap {
+		payload, _ := json.Marshal(wsRoutePayload{
+			UserIDList: nodeUserIDList,
+			Data:       byteData,
+		})
+		pipe.Publish(r.channel(nodeID), string(payload))
+	}
+	_, err = pipe.Exec()
+	if err != nil {
+		logx.Error(err)
+	}
+}
+
+// Subscribe 订阅其他节点转发给当前节点的推送，每收到一条调用一次handler，会一直阻塞
+// 参数:
+// - handler: 把推送发给当前节点上该用户的所有连接，一条推送里有多个用户的时候每个用户调用一次。
+func (r *WsRouter) Subscribe(handler func(userID uint, byteData []byte)) {
+	pubSub := r.client.Subscribe(r.channel(r.NodeID))
+	defer pubSub.Close()
+	for msg := range pubSub.Channel() {
+		var payload wsRoutePayload
+		err := json.Unmarshal([]byte(msg.Payload), &payload)
+		if err != nil {
+			logx.Error(err)
+			continue
+		}
+		for _, userID := range payload.UserIDList {
+			handler(userID, payload.Data)
+		}
+	}
+}
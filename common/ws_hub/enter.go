@@ -0,0 +1,274 @@
+package ws_hub
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"sync"
+	"time"
+)
+
+// Config websocket连接的参数，没有配置的字段使用默认值
+type Config struct {
+	SendQueueSize  int           `json:",default=256"`   // 每个连接待发送消息的队列长度，队列满了说明客户端读得太慢，直接断开
+	MaxMessageSize int64         `json:",default=65536"` // 客户端单条消息的最大字节数
+	WriteWait      time.Duration `json:",default=10s"`   // 单次写入的超时时间
+	PongWait       time.Duration `json:",default=60s"`   // 这么久没有收到客户端的任何数据就认为连接已经断开
+	PingPeriod     time.Duration `json:",default=50s"`   // 发送ping的间隔，必须小于PongWait
+}
+
+func (c Config) withDefault() Config {
+	if c.SendQueueSize <= 0 {
+		c.SendQueueSize = 256
+	}
+	if c.MaxMessageSize <= 0 {
+		c.MaxMessageSize = 65536
+	}
+	if c.WriteWait <= 0 {
+		c.WriteWait = 10 * time.Second
+	}
+	if c.PongWait <= 0 {
+		c.PongWait = 60 * time.Second
+	}
+	if c.PingPeriod <= 0 || c.PingPeriod >= c.PongWait {
+		c.PingPeriod = c.PongWait * 9 / 10
+	}
+	return c
+}
+
+// Client 一个websocket连接
+// gorilla的连接不允许并发写，所有的写操作都放进发送队列，由这个连接自己的写协程按顺序写出
+type Client struct {
+	UserID    uint
+	Addr      string
+	conn      *websocket.Conn
+	conf      Config
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Send 把消息放进发送队列，不会阻塞
+// 连接已经关闭的时候返回false，队列满了的时候断开这个连接并返回false
+func (c *Client) Send(byteData []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+	select {
+	case c.send <- byteData:
+		return true
+	default:
+		// 客户端读得太慢，不能让它拖住其他的推送
+		c.Close()
+		return false
+	}
+}
+
+// SendJSON 把v序列化成json之后放进发送队列
+func (c *Client) SendJSON(v any) bool {
+	byteData, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	return c.Send(byteData)
+}
+
+// ReadMessage 读取客户端的下一条消息，收到任何消息都会延长读超时
+// 只能在一个协程里调用
+func (c *Client) ReadMessage() ([]byte, error) {
+	_, p, err := c.conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	c.conn.SetReadDeadline(time.Now().Add(c.conf.PongWait))
+	return p, nil
+}
+
+// Close 关闭连接，可以重复调用
+// 连接关闭之后读消息会返回错误，由读消息的协程负责从Hub里注销
+func (c *Client) Close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
+
+// writeLoop 写协程，发送队列里的消息和定时的ping都在这里写出
+func (c *Client) writeLoop() {
+	ticker := time.NewTicker(c.conf.PingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.Close()
+	}()
+	for {
+		select {
+		case byteData := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(c.conf.WriteWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, byteData); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(c.conf.WriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+type userClients[T any] struct {
+	info      T
+	clientMap map[string]*Client
+}
+
+// Hub 管理当前节点上所有用户的websocket连接，可以在多个协程里并发使用
+// T 是每个用户附带的信息，例如用户的昵称和头像
+type Hub[T any] struct {
+	conf    Config
+	lock    sync.RWMutex
+	userMap map[uint]*userClients[T]
+}
+
+// NewHub 创建连接管理
+func NewHub[T any](conf Config) *Hub[T] {
+	return &Hub[T]{
+		conf:    conf.withDefault(),
+		userMap: map[uint]*userClients[T]{},
+	}
+}
+
+// Register 注册一个新的连接，设置读限制和心跳，并启动这个连接的写协程
+// 返回值:
+// - client: 连接，后续的读写都通过它进行。
+// - first: 是否是这个用户在当前节点上的第一个连接。
+func (h *Hub[T]) Register(userID uint, info T, conn *websocket.Conn) (client *Client, first bool) {
+	client = &Client{
+		UserID: userID,
+		Addr:   conn.RemoteAddr().String(),
+		conn:   conn,
+		conf:   h.conf,
+		send:   make(chan []byte, h.conf.SendQueueSize),
+		done:   make(chan struct{}),
+	}
+	conn.SetReadLimit(h.conf.MaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(h.conf.PongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(h.conf.PongWait))
+	})
+
+	h.lock.Lock()
+	user, ok := h.userMap[userID]
+	if !ok {
+		user = &userClients[T]{
+			clientMap: map[string]*Client{},
+		}
+		h.userMap[userID] = user
+	}
+	user.info = info
+	if old, ok1 := user.clientMap[client.Addr]; ok1 {
+		old.Close()
+	}
+	user.clientMap[client.Addr] = client
+	h.lock.Unlock()
+
+	go client.writeLoop()
+	return client, !ok
+}
+
+// Unregister 注销并关闭连接
+// 返回值:
+// - last: 这个用户在当前节点上是否已经没有连接了。
+func (h *Hub[T]) Unregister(client *Client) (last bool) {
+	client.Close()
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	user, ok := h.userMap[client.UserID]
+	if !ok {
+		return false
+	}
+	// 同一个地址重连的时候，旧连接已经被新连接替换了
+	if user.clientMap[client.Addr] != client {
+		return false
+	}
+	delete(user.clientMap, client.Addr)
+	if len(user.clientMap) == 0 {
+		delete(h.userMap, client.UserID)
+		return true
+	}
+	return false
+}
+
+// SendToUser 把消息发给这个用户在当前节点上的所有连接
+// 返回值: 用户在当前节点上是否有连接。
+func (h *Hub[T]) SendToUser(userID uint, byteData []byte) bool {
+	clientList, ok := h.clientList(userID)
+	for _, client := range clientList {
+		client.Send(byteData)
+	}
+	return ok
+}
+
+// CloseUser 关闭这个用户在当前节点上的所有连接，例如账号被封禁的时候
+// 连接由各自读消息的协程注销
+func (h *Hub[T]) CloseUser(userID uint) {
+	clientList, _ := h.clientList(userID)
+	for _, client := range clientList {
+		client.Close()
+	}
+}
+
+// clientList 复制出用户在当前节点上的连接，发送和关闭都在锁外面进行
+func (h *Hub[T]) clientList(userID uint) (clientList []*Client, ok bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	user, ok := h.userMap[userID]
+	if !ok {
+		return nil, false
+	}
+	for _, client := range user.clientMap {
+		clientList = append(clientList, client)
+	}
+	return clientList, true
+}
+
+// IsOnline 用户在当前节点上是否有连接
+func (h *Hub[T]) IsOnline(userID uint) bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	_, ok := h.userMap[userID]
+	return ok
+}
+
+// Info 获取用户附带的信息，用户在当前节点上没有连接的时候ok为false
+func (h *Hub[T]) Info(userID uint) (info T, ok bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	user, ok := h.userMap[userID]
+	if !ok {
+		return
+	}
+	return user.info, true
+}
+
+// SetInfo 更新用户附带的信息，用户在当前节点上没有连接的时候不做处理
+func (h *Hub[T]) SetInfo(userID uint, info T) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	user, ok := h.userMap[userID]
+	if ok {
+		user.info = info
+	}
+}
+
+// UserIDList 当前节点上在线的用户id
+func (h *Hub[T]) UserIDList() (userIDList []uint) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	for userID := range h.userMap {
+		userIDList = append(userIDList, userID)
+	}
+	return
+}
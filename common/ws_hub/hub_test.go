@@ -0,0 +1,160 @@
+package ws_hub
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newTestServer 启动一个websocket服务，连接按用户1注册到hub里，和chat、group的处理方式一样读到错误之后注销
+// 每注销一个连接往返回的通道里写一次Unregister的返回值
+func newTestServer(t *testing.T, hub *Hub[string]) (url string, unregister chan bool) {
+	unregister = make(chan bool, 8)
+	upGrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upGrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client, _ := hub.Register(1, "张三", conn)
+		for {
+			_, err = client.ReadMessage()
+			if err != nil {
+				break
+			}
+		}
+		unregister <- hub.Unregister(client)
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http"), unregister
+}
+
+// dial 连接测试服务，等到连接在hub里注册之后再返回
+func dial(t *testing.T, url string, hub *Hub[string], count int) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		clientList, _ := hub.clientList(1)
+		if len(clientList) == count {
+			return conn
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("连接没有注册到hub")
+	return nil
+}
+
+func TestSendToUser(t *testing.T) {
+	hub := NewHub[string](Config{})
+	url, _ := newTestServer(t, hub)
+	conn1 := dial(t, url, hub, 1)
+	conn2 := dial(t, url, hub, 2)
+
+	if !hub.SendToUser(1, []byte("hello")) {
+		t.Error("用户1在线")
+	}
+	for i, conn := range []*websocket.Conn{conn1, conn2} {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, p, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("第%d个连接没有收到消息 %s", i+1, err)
+		}
+		if string(p) != "hello" {
+			t.Errorf("第%d个连接收到的消息 = %s", i+1, p)
+		}
+	}
+	if hub.SendToUser(2, []byte("hello")) {
+		t.Error("用户2不在线")
+	}
+	if info, ok := hub.Info(1); !ok || info != "张三" {
+		t.Errorf("Info = %s %v", info, ok)
+	}
+}
+
+func TestSendQueueFull(t *testing.T) {
+	hub := NewHub[string](Config{})
+	url, unregister := newTestServer(t, hub)
+	dial(t, url, hub, 1)
+	clientList, _ := hub.clientList(1)
+	client := clientList[0]
+
+	// 同一个连接换成没有写协程消费的发送队列，模拟客户端读得太慢
+	slow := &Client{
+		UserID: client.UserID,
+		Addr:   client.Addr,
+		conn:   client.conn,
+		conf:   client.conf,
+		send:   make(chan []byte, 1),
+		done:   make(chan struct{}),
+	}
+	if !slow.Send([]byte("1")) {
+		t.Error("队列没满的时候应该发送成功")
+	}
+	if slow.Send([]byte("2")) {
+		t.Error("队列满了的时候应该发送失败")
+	}
+	select {
+	case <-slow.done:
+	default:
+		t.Error("队列满了之后连接应该被关闭")
+	}
+	if slow.Send([]byte("3")) {
+		t.Error("连接关闭之后不能再发送")
+	}
+	select {
+	case last := <-unregister:
+		if !last {
+			t.Error("用户唯一的连接关闭之后应该是最后一个连接")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("连接关闭之后没有注销")
+	}
+	if hub.IsOnline(1) {
+		t.Error("连接注销之后用户不在线")
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	hub := NewHub[string](Config{})
+	url, unregister := newTestServer(t, hub)
+	conn1 := dial(t, url, hub, 1)
+	dial(t, url, hub, 2)
+
+	// 客户端断开，服务端读到错误之后注销
+	conn1.Close()
+	select {
+	case last := <-unregister:
+		if last {
+			t.Error("还有一个连接，不是最后一个")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("客户端断开之后没有注销")
+	}
+	if !hub.IsOnline(1) {
+		t.Error("还有一个连接，用户仍然在线")
+	}
+
+	// 服务端主动关闭用户的所有连接
+	hub.CloseUser(1)
+	select {
+	case last := <-unregister:
+		if !last {
+			t.Error("应该是最后一个连接")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("关闭之后没有注销")
+	}
+	if hub.IsOnline(1) || len(hub.UserIDList()) != 0 {
+		t.Error("所有连接注销之后用户不在线")
+	}
+}
@@ -24,6 +24,9 @@ type Config struct {
 		AppKey   string
 		Redirect string
 	}
+	IPRegion struct {
+		Path string `json:",default=ip2region.xdb"` // ip2region的xdb数据文件
+	}
 	UserRpc   zrpc.RpcClientConf
 	Etcd      string
 	WhiteList []string //白名单
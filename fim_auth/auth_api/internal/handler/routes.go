@@ -32,6 +32,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/auth/open_login",
 				Handler: open_loginHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/auth/reactivate",
+				Handler: reactivateHandler(serverCtx),
+			},
 		},
 	)
 }
@@ -3,11 +3,12 @@ package logic
 import (
 	"context"
 	"errors"
+	"fim/common/service/redis_service"
 	"fim/fim_auth/auth_api/internal/svc"
 	"fim/fim_auth/auth_api/internal/types"
 	"fim/utils"
 	"fim/utils/jwts"
-	"fmt"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -61,9 +62,12 @@ func (l *AuthenticationLogic) Authentication(req *types.AuthenticationRequest) (
 		return
 	}
 
-	// 检查用户是否已登出。如果已登出，则返回认证失败的错误。
-	_, err = l.svcCtx.Redis.Get(fmt.Sprintf("logout:%d", payload.UserID)).Result()
-	if err == nil {
+	// 检查用户是否已登出。账号停用或者封禁之前签发的token都失效，则返回认证失败的错误。
+	var issuedAt time.Time
+	if payload.IssuedAt != nil {
+		issuedAt = payload.IssuedAt.Time
+	}
+	if redis_service.IsLogout(l.svcCtx.Redis, payload.UserID, issuedAt) {
 		logx.Error("用户已退出")
 		err = errors.New("认证失败")
 		return
@@ -6,8 +6,13 @@ import (
 	"fim/fim_auth/auth_api/internal/svc"
 	"fim/fim_auth/auth_api/internal/types"
 	auth_models "fim/fim_auth/auth_models"
+	"fim/fim_user/user_models"
+	"fim/utils/handles"
 	"fim/utils/jwts"
+	"strconv"
+
 	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
 )
 
 type LoginLogic struct {
@@ -33,14 +38,18 @@ func NewLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LoginLogic
 // resp - 成功登录时返回的类型为`types.LoginResponse`的指针，包含生成的访问令牌。
 // err  - 登录过程中遇到的任何错误。
 func (l *LoginLogic) Login(req *types.LoginRequest) (resp *types.LoginResponse, err error) {
-	var user auth_models.UserModel
-	// 从数据库中查找与用户名匹配的用户记录
-	err = l.svcCtx.DB.Take(&user, "id=?", req.UserName).Error
+	// 从数据库中查找与用户名匹配的用户记录，用户名可以是用户id或者用户号
+	user, err := findLoginUser(l.svcCtx.DB, req.UserName)
 	if err != nil {
 		// 如果查找失败，设置错误信息并返回
 		err = errors.New("用户名或密码错误")
 		return
 	}
+	// 停用和注销中的账号不能直接登录，需要先恢复账号
+	err = checkUserStatus(user)
+	if err != nil {
+		return
+	}
 
 	// 生成JWT令牌
 	token, err := jwts.GenerateToken(jwts.JwtPayLoad{
@@ -54,8 +63,59 @@ func (l *LoginLogic) Login(req *types.LoginRequest) (resp *types.LoginResponse,
 		err = errors.New("服务内部错误")
 		return
 	}
+	recordLogin(l.svcCtx, user.ID, "password", req.ClientIP, req.UserAgent)
 	// 登录成功，返回生成的令牌
 	return &types.LoginResponse{
 		Token: token,
 	}, nil
 }
+
+// checkUserStatus 检查账号状态是否允许登录
+func checkUserStatus(user auth_models.UserModel) error {
+	switch user.Status {
+	case 1:
+		return errors.New("账号已停用，请先恢复账号")
+	case 2:
+		return errors.New("账号注销中，请先恢复账号")
+	case 3:
+		return errors.New("用户名或密码错误")
+	case 4:
+		return errors.New("账号已被封禁")
+	}
+	return nil
+}
+
+// findLoginUser 按用户id或者用户号查找登录的用户，用户号不区分大小写
+func findLoginUser(db *gorm.DB, userName string) (user auth_models.UserModel, err error) {
+	if _, err1 := strconv.ParseUint(userName, 10, 64); err1 == nil {
+		err = db.Take(&user, "id = ?", userName).Error
+		return
+	}
+	err = db.Take(&user, "handle = ?", handles.Normalize(userName)).Error
+	return
+}
+
+// recordLogin 登录成功后更新用户最近一次登录的IP和归属地，并写入登录记录，失败只记录日志
+func recordLogin(svcCtx *svc.ServiceContext, userID uint, source, clientIP, userAgent string) {
+	addr := svcCtx.IPRegion.Addr(clientIP)
+	err := svcCtx.DB.Model(&auth_models.UserModel{}).Where("id = ?", userID).Updates(map[string]any{
+		"ip":   clientIP,
+		"addr": addr,
+	}).Error
+	if err != nil {
+		logx.Error(err)
+	}
+	if runes := []rune(userAgent); len(runes) > 256 {
+		userAgent = string(runes[:256])
+	}
+	err = svcCtx.DB.Create(&user_models.LoginLogModel{
+		UserID:    userID,
+		IP:        clientIP,
+		Addr:      addr,
+		Source:    source,
+		UserAgent: userAgent,
+	}).Error
+	if err != nil {
+		logx.Error(err)
+	}
+}
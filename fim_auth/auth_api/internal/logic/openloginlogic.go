@@ -92,6 +92,18 @@ func (l *Open_loginLogic) Open_login(req *types.OpenLoginRequest) (resp *types.L
 		user.Role = 2
 		user.Nickname = info.Nickname
 	}
+	// 没有密码的账号只能通过第三方登录恢复，第三方验证通过就直接恢复停用和注销中的账号
+	if user.Pwd == "" && (user.Status == 1 || user.Status == 2) {
+		err = reactivateUser(l.svcCtx, user)
+		if err != nil {
+			return nil, err
+		}
+		user.Status = 0
+	}
+	err = checkUserStatus(user)
+	if err != nil {
+		return nil, err
+	}
 
 	// 生成登录令牌
 	// 登录逻辑
@@ -107,6 +119,7 @@ func (l *Open_loginLogic) Open_login(req *types.OpenLoginRequest) (resp *types.L
 		return nil, err1
 	}
 
+	recordLogin(l.svcCtx, user.ID, req.Flag, req.ClientIP, req.UserAgent)
 	// 返回登录响应，包含生成的令牌
 	return &types.LoginResponse{Token: token}, nil
 
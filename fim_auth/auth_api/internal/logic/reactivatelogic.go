@@ -0,0 +1,93 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/common/service/redis_service"
+	"fim/fim_auth/auth_api/internal/svc"
+	"fim/fim_auth/auth_api/internal/types"
+	auth_models "fim/fim_auth/auth_models"
+	"fim/utils/jwts"
+	"fim/utils/pwd"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ReactivateLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewReactivateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReactivateLogic {
+	return &ReactivateLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Reactivate 恢复已停用或注销中的账号，恢复成功后直接登录
+// 注销中的账号只能在注销生效之前恢复，已注销的账号无法恢复
+func (l *ReactivateLogic) Reactivate(req *types.LoginRequest) (resp *types.LoginResponse, err error) {
+	user, err := findLoginUser(l.svcCtx.DB, req.UserName)
+	if err != nil {
+		err = errors.New("用户名或密码错误")
+		return
+	}
+	// 第三方登录注册的账号没有密码，只能重新通过第三方登录恢复
+	if user.Pwd == "" {
+		err = errors.New("该账号未设置密码，请通过第三方登录恢复账号")
+		return
+	}
+	if !pwd.CheckPwd(user.Pwd, req.Password) {
+		err = errors.New("用户名或密码错误")
+		return
+	}
+	switch user.Status {
+	case 0:
+		err = errors.New("账号状态正常，无需恢复")
+		return
+	case 3:
+		err = errors.New("用户名或密码错误")
+		return
+	case 4:
+		err = errors.New("账号已被封禁")
+		return
+	}
+
+	err = reactivateUser(l.svcCtx, user)
+	if err != nil {
+		return
+	}
+
+	token, err := jwts.GenerateToken(jwts.JwtPayLoad{
+		UserID:   user.ID,
+		NickName: user.Nickname,
+		Role:     user.Role,
+	}, l.svcCtx.Config.Auth.AccessSecret, l.svcCtx.Config.Auth.AccessExpire)
+	if err != nil {
+		logx.Error(err)
+		err = errors.New("服务内部错误")
+		return
+	}
+	recordLogin(l.svcCtx, user.ID, "reactivate", req.ClientIP, req.UserAgent)
+	return &types.LoginResponse{
+		Token: token,
+	}, nil
+}
+
+// reactivateUser 把已停用或注销中的账号恢复为正常状态
+func reactivateUser(svcCtx *svc.ServiceContext, user auth_models.UserModel) error {
+	err := svcCtx.DB.Model(&user).Updates(map[string]any{
+		"status":    0,
+		"delete_at": nil,
+	}).Error
+	if err != nil {
+		logx.Error(err)
+		return errors.New("账号恢复失败")
+	}
+	// 停用时写入的下线标记保留，停用之前签发的token仍然无效，恢复之后只能用新的token
+	redis_service.PublishUserInfoChange(svcCtx.Redis, user.ID)
+	return nil
+}
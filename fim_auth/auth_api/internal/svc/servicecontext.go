@@ -7,7 +7,9 @@ import (
 	"fim/fim_auth/auth_api/internal/config"
 	"fim/fim_user/user_rpc/types/user_rpc"
 	"fim/fim_user/user_rpc/users"
+	"fim/utils/ips"
 	"github.com/go-redis/redis"
+	"github.com/zeromicro/go-zero/core/logx"
 	"github.com/zeromicro/go-zero/zrpc"
 	"gorm.io/gorm"
 )
@@ -15,10 +17,11 @@ import (
 // ServiceContext 定义了服务上下文结构体，包含了服务运行所需的各种依赖
 // 这些依赖包括配置信息、数据库连接、Redis客户端以及用户RPC服务客户端
 type ServiceContext struct {
-	Config  config.Config        // 配置信息，包含了数据库、Redis和用户RPC服务的配置
-	DB      *gorm.DB             // 数据库连接
-	Redis   *redis.Client        // Redis客户端
-	UserRpc user_rpc.UsersClient // 用户RPC服务客户端
+	Config   config.Config        // 配置信息，包含了数据库、Redis和用户RPC服务的配置
+	DB       *gorm.DB             // 数据库连接
+	Redis    *redis.Client        // Redis客户端
+	UserRpc  user_rpc.UsersClient // 用户RPC服务客户端
+	IPRegion *ips.Region          // IP归属地查询，数据文件加载失败时为nil，只能识别内网地址
 }
 
 // NewServiceContext 根据配置信息初始化服务上下文
@@ -32,11 +35,17 @@ func NewServiceContext(c config.Config) *ServiceContext {
 	client := core.InitRedis(c.Redis.Addr, c.Redis.Password, c.Redis.DB)
 	// 创建用户RPC服务客户端
 	userRpc := users.NewUsers(zrpc.MustNewClient(c.UserRpc))
+	// 加载IP归属地数据，加载失败不影响登录，只是查不到归属地
+	ipRegion, err := ips.NewRegion(c.IPRegion.Path)
+	if err != nil {
+		logx.Errorf("加载IP归属地数据失败 %s", err)
+	}
 	// 返回初始化后的服务上下文
 	return &ServiceContext{
-		Config:  c,
-		DB:      mysqlDb,
-		Redis:   client,
-		UserRpc: userRpc,
+		Config:   c,
+		DB:       mysqlDb,
+		Redis:    client,
+		UserRpc:  userRpc,
+		IPRegion: ipRegion,
 	}
 }
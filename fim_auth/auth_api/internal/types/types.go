@@ -12,8 +12,10 @@ type AuthenticationResponse struct {
 }
 
 type LoginRequest struct {
-	UserName string `json:"username"` // 用户名
-	Password string `json:"password"` // 密码
+	UserName  string `json:"username"`              // 用户名
+	Password  string `json:"password"`              // 密码
+	ClientIP  string `header:"Client-IP,optional"`  // 客户端IP，由网关设置
+	UserAgent string `header:"User-Agent,optional"` // 客户端的User-Agent
 }
 
 type LoginResponse struct {
@@ -27,6 +29,8 @@ type OpenLoginInfoRespone struct {
 }
 
 type OpenLoginRequest struct {
-	Code string `json:"code"` //授权码
-	Flag string `json:"flag"` //登录标识，区分登录类型
+	Code      string `json:"code"`                  //授权码
+	Flag      string `json:"flag"`                  //登录标识，区分登录类型
+	ClientIP  string `header:"Client-IP,optional"`  // 客户端IP，由网关设置
+	UserAgent string `header:"User-Agent,optional"` // 客户端的User-Agent
 }
@@ -5,14 +5,16 @@ import "fim/common/models"
 // UserModel 用户表
 type UserModel struct {
 	models.Model
-	Pwd            string `gorm:"size:64" json:"-"`
-	Nickname       string `gorm:"size:32" json:"nickname"`
-	Abstract       string `gorm:"size:128" json:"abstract"`
-	Avatar         string `gorm:"size:256" json:"avatar"`
-	IP             string `gorm:"size:32" json:"ip"`
-	Addr           string `gorm:"size:64" json:"addr"`
-	Role           int8   `json:"role"`                          // 角色 1 管理员  2 普通用户
-	OpenID         string `gorm:"size:64" json:"-"`              // 第三方平台登录的凭证
-	RegisterSource string `gorm:"size:16" json:"registerSource"` // 注册来源
+	Pwd            string  `gorm:"size:64" json:"-"`
+	Nickname       string  `gorm:"size:32" json:"nickname"`
+	Handle         *string `gorm:"size:20" json:"handle"` // 用户号，可以用来登录
+	Abstract       string  `gorm:"size:128" json:"abstract"`
+	Avatar         string  `gorm:"size:256" json:"avatar"`
+	IP             string  `gorm:"size:32" json:"ip"`
+	Addr           string  `gorm:"size:64" json:"addr"`
+	Role           int8    `json:"role"`                          // 角色 1 管理员  2 普通用户
+	OpenID         string  `gorm:"size:64" json:"-"`              // 第三方平台登录的凭证
+	RegisterSource string  `gorm:"size:16" json:"registerSource"` // 注册来源
+	Status         int8    `json:"status"`                        // 账号状态 0 正常 1 已停用 2 注销中 3 已注销 4 已封禁
 
 }
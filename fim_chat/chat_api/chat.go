@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 
+	"fim/common/models/ctype"
+	"fim/common/service/redis_service"
 	"fim/fim_chat/chat_api/internal/config"
 	"fim/fim_chat/chat_api/internal/handler"
 	"fim/fim_chat/chat_api/internal/svc"
@@ -25,6 +27,27 @@ func main() {
 
 	ctx := svc.NewServiceContext(c)
 	handler.RegisterHandlers(server, ctx)
+	// 接收其他节点转发过来的推送，并定时刷新这个节点上在线用户的路由
+	go ctx.WsRouter.Subscribe(func(userID uint, byteData []byte) {
+		handler.SendLocalMsg(ctx, userID, byteData)
+	})
+	go ctx.WsRouter.KeepAlive(ctx.WsHub.UserIDList)
+	// 订阅用户资料变更，刷新在线用户的信息并推送给相关的在线用户
+	go redis_service.SubscribeUserInfoChange(ctx.Redis, func(userID uint) {
+		handler.UserInfoChange(ctx, userID)
+	})
+	// 订阅通知中心的新通知，推送给这个节点上在线的用户
+	go redis_service.SubscribeNotification(ctx.Redis, func(userID uint, msg ctype.NotificationMsg) {
+		handler.Notification(ctx, userID, msg)
+	})
+	// 订阅偏好设置变更，同步到用户在这个节点上的其他设备
+	go redis_service.SubscribePreferenceChange(ctx.Redis, func(userID uint, msg ctype.PreferenceChangeMsg) {
+		handler.PreferenceChange(ctx, userID, msg)
+	})
+	// 订阅已读回执，推送给消息的发送者和已读用户自己的其他设备
+	go redis_service.SubscribeReadReceipt(ctx.Redis, func(userID uint, msg ctype.ReadReceiptMsg) {
+		handler.ReadReceipt(ctx, userID, msg)
+	})
 
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
 	server.Start()
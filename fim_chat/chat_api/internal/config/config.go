@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fim/common/ws_hub"
 	"github.com/zeromicro/go-zero/rest"
 	"github.com/zeromicro/go-zero/zrpc"
 )
@@ -18,4 +19,6 @@ type Config struct {
 		Pwd  string
 		DB   int
 	}
+	Ws             ws_hub.Config `json:",optional"`   // websocket连接的队列长度、心跳和读限制
+	MsgEditMinutes int           `json:",default=15"` // 消息发出之后多少分钟之内可以编辑
 }
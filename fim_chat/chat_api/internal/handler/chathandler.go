@@ -6,12 +6,15 @@ import (
 	"fim/common/models/ctype"
 	"fim/common/response"
 	"fim/common/service/redis_service"
+	"fim/common/ws_hub"
+	"fim/fim_chat/chat_api/internal/logic"
 	"fim/fim_chat/chat_api/internal/svc"
 	"fim/fim_chat/chat_api/internal/types"
 	"fim/fim_chat/chat_models"
 	"fim/fim_file/file_rpc/types/file_rpc"
 	"fim/fim_user/user_models"
 	"fim/fim_user/user_rpc/types/user_rpc"
+	"fim/utils/typings"
 	"fmt"
 	"github.com/gorilla/websocket"
 	"github.com/zeromicro/go-zero/core/logx"
@@ -23,22 +26,18 @@ import (
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
-type UserWsInfo struct {
-	UserInfo    user_models.UserModel      //用户信息
-	WsClientMap map[string]*websocket.Conn //这个用户管理所有ws连接
-	currentConn *websocket.Conn            //当前连接
-}
+var typingTracker = typings.NewTracker(3*time.Second, 8*time.Second) //正在输入的状态，3秒之内只转发一次，8秒没有新事件自动过期
 
-var UserOnlineWsMap = map[uint]*UserWsInfo{} //用户id和ws信息的映射
-var VideoCallMap = map[string]time.Time{}    //音视频通话
 type ChatResponse struct {
-	ID         uint           `json:"id"`
-	IsMe       bool           `json:"is_me"`
-	RevUser    ctype.UserInfo `json:"revUser"`
-	SendUser   ctype.UserInfo `json:"sendUser"`
-	Msg        ctype.Msg      `json:"msg"`
-	CreatedAt  time.Time      `json:"created_at"`
-	MsgPreview string         `json:"msg_preview"`
+	ID             uint           `json:"id"`
+	IsMe           bool           `json:"is_me"`
+	RevUser        ctype.UserInfo `json:"revUser"`
+	SendUser       ctype.UserInfo `json:"sendUser"`
+	Msg            ctype.Msg      `json:"msg"`
+	CreatedAt      time.Time      `json:"created_at"`
+	MsgPreview     string         `json:"msg_preview"`
+	ConversationID string         `json:"conversationID,omitempty"` // 私聊会话id
+	Seq            int64          `json:"seq,omitempty"`            // 会话内的消息序号，客户端发现和本地最大序号不连续的时候调用同步接口补齐
 }
 
 // chatHandler 处理聊天请求的HTTP函数。
@@ -67,31 +66,13 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			return
 		}
 
-		// 获取并记录连接的远程地址，用于后续的连接管理。
-		add := conn.RemoteAddr().String()
-		defer func() {
-			err := conn.Close()
-			if err != nil {
-				return
-			}
-			// 管理用户WebSocket连接的在线状态。
-			userWsInfo, ok := UserOnlineWsMap[req.UserID]
-			if ok {
-				delete(userWsInfo.WsClientMap, add)
-			}
-			if userWsInfo != nil && len(userWsInfo.WsClientMap) == 0 {
-				delete(UserOnlineWsMap, req.UserID)
-				svcCtx.Redis.HDel("online", fmt.Sprintf("%d", req.UserID))
-			}
-		}()
-
-		// 调用RPC服务获取用户信息。
+		// 调用RPC服务获取用户信息。连接已经升级，出错的时候只能直接关闭连接。
 		res, err := svcCtx.UserRpc.UserInfo(context.Background(), &user_rpc.UserInfoRequest{
 			UserId: uint32(req.UserID),
 		})
 		if err != nil {
 			logx.Error(err)
-			response.Response(r, w, nil, err)
+			conn.Close()
 			return
 		}
 
@@ -100,27 +81,21 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 		err = json.Unmarshal(res.Data, &userInfo)
 		if err != nil {
 			logx.Error(err)
-			response.Response(r, w, nil, err)
+			conn.Close()
 			return
 		}
 
-		// 管理用户的在线状态。
-		userWsinfo, ok := UserOnlineWsMap[req.UserID]
-		if !ok {
-			userWsinfo = &UserWsInfo{
-				UserInfo: userInfo,
-				WsClientMap: map[string]*websocket.Conn{
-					add: conn,
-				},
-				currentConn: conn,
-			}
-			UserOnlineWsMap[req.UserID] = userWsinfo
-		}
-		_, ok1 := userWsinfo.WsClientMap[add]
-		if !ok1 {
-			UserOnlineWsMap[req.UserID].WsClientMap[add] = conn
-			UserOnlineWsMap[req.UserID].currentConn = conn
+		// 注册连接，之后对这个连接的写操作都通过client放进发送队列，由连接自己的写协程写出。
+		client, first := svcCtx.WsHub.Register(req.UserID, userInfo, conn)
+		if first {
+			svcCtx.WsRouter.Online(req.UserID)
 		}
+		defer func() {
+			// 这个节点上该用户的连接全部断开，其他节点上还有连接的时候仍然是在线状态
+			if svcCtx.WsHub.Unregister(client) && !svcCtx.WsRouter.Offline(req.UserID) {
+				svcCtx.Redis.HDel("online", fmt.Sprintf("%d", req.UserID))
+			}
+		}()
 		svcCtx.Redis.HSet("online", fmt.Sprintf("%d", req.UserID), req.UserID)
 
 		// 获取用户的朋友列表。
@@ -129,57 +104,97 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 		})
 		if err != nil {
 			logx.Error(err)
-			response.Response(r, w, nil, err)
 			return
 		}
 
 		// 记录用户上线信息。
 		logx.Infof("用户上线，%s 用户id；%d", userInfo.Nickname, req.UserID)
 
-		// 通知用户的朋友关于其上线的消息。
+		// 通知开启了好友上线提醒的朋友，朋友的连接可能在其他节点上。
+		var friendIDList []uint
 		for _, info := range friendRes.FriendList {
 			if uint(info.UserId) == req.UserID {
 				continue
 			}
-			friend, ok := UserOnlineWsMap[uint(info.UserId)]
-			if ok {
-				text := fmt.Sprintf("好友%s上线了", UserOnlineWsMap[req.UserID].UserInfo.Nickname)
-				if friend.UserInfo.UserConfModel.FriendOnline {
-					resp := ChatResponse{
-						Msg: ctype.Msg{
-							Type: ctype.FriendOnlineMsgType,
-							FriendOnlineMsg: &ctype.FriendOnlineMsg{
-								NickName: userInfo.Nickname,
-								Avatar:   userInfo.Avatar,
-								Content:  text,
-								FriendID: userInfo.ID,
-							},
-						},
-						CreatedAt: time.Now(),
-					}
-					byteData, _ := json.Marshal(resp)
-					sendMapMsg(friend.WsClientMap, byteData)
-				}
+			friendIDList = append(friendIDList, uint(info.UserId))
+		}
+		var noticeIDList []uint
+		if len(friendIDList) > 0 {
+			svcCtx.DB.Model(&user_models.UserConfModel{}).
+				Where("user_id in ? and friend_online = ?", friendIDList, true).
+				Pluck("user_id", &noticeIDList)
+		}
+		resp := ChatResponse{
+			Msg: ctype.Msg{
+				Type: ctype.FriendOnlineMsgType,
+				FriendOnlineMsg: &ctype.FriendOnlineMsg{
+					NickName: userInfo.Nickname,
+					Avatar:   userInfo.Avatar,
+					Content:  fmt.Sprintf("好友%s上线了", userInfo.Nickname),
+					FriendID: userInfo.ID,
+				},
+			},
+			CreatedAt: time.Now(),
+		}
+		byteData, _ := json.Marshal(resp)
+		for _, friendID := range noticeIDList {
+			// 存在拉黑关系的好友不推送上线提醒
+			var block user_models.UserBlockModel
+			if block.IsBlock(svcCtx.DB, req.UserID, friendID) {
+				continue
 			}
+			sendUserMsg(svcCtx, friendID, byteData)
 		}
 
 		// 循环读取并处理WebSocket的消息。
 		for {
-			_, p, err1 := conn.ReadMessage()
+			p, err1 := client.ReadMessage()
 			if err1 != nil {
 				fmt.Println(err1)
 				break
 			}
+			// 用户信息在资料变更的时候会刷新，每条消息都重新取一次，限制聊天对已经建立的连接也立即生效
+			if info, ok := svcCtx.WsHub.Info(req.UserID); ok {
+				userInfo = info
+			}
 			if userInfo.UserConfModel.CurtailChat {
 				// 如果用户被限制聊天，则发送提示消息。
-				SendTipErrMsg(conn, "你已被限制聊天，请联系客服")
+				SendTipErrMsg(client, "你已被限制聊天，请联系客服")
 				continue
 			}
 			var request Chatquest
 			err2 := json.Unmarshal(p, &request)
 			if err2 != nil {
 				logx.Error(err2)
-				SendTipErrMsg(conn, "参数解析失败")
+				SendTipErrMsg(client, "参数解析失败")
+				continue
+			}
+			if len(request.ClientMsgID) > 64 {
+				SendTipErrMsg(client, "客户端消息id过长")
+				continue
+			}
+			// 客户端重发的消息，已经入库过的直接回复ACK，不重复入库和推送
+			if request.ClientMsgID != "" {
+				chatModel, ok := chat_models.FindClientMsg(svcCtx.DB, req.UserID, request.ClientMsgID)
+				if ok {
+					SendAckMsg(client, request.ClientMsgID, chatModel)
+					continue
+				}
+			}
+			// 标记会话已读，不是聊天消息，不用走下面的校验和入库
+			if request.Msg.Type == ctype.ReadReceiptMsgType {
+				var seq int64
+				if request.Msg.ReadReceiptMsg != nil {
+					seq = request.Msg.ReadReceiptMsg.Seq
+				}
+				_, err3 := logic.NewChatReadLogic(context.Background(), svcCtx).ChatRead(&types.ChatReadRequest{
+					UserID:   req.UserID,
+					FriendID: request.RevUserID,
+					Seq:      seq,
+				})
+				if err3 != nil {
+					SendAckErrMsg(client, request.ClientMsgID, err3.Error())
+				}
 				continue
 			}
 			if request.RevUserID != req.UserID {
@@ -189,23 +204,44 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 				})
 				if err != nil {
 					logx.Error(err)
-					SendTipErrMsg(conn, "用户服务错误")
+					SendAckErrMsg(client, request.ClientMsgID, "用户服务错误")
 					continue
 				}
 				if !isFriendRes.IsFriend {
-					SendTipErrMsg(conn, "你不是好友")
+					SendAckErrMsg(client, request.ClientMsgID, "你不是好友")
+					continue
+				}
+				// 存在拉黑关系的不能发送消息
+				var block user_models.UserBlockModel
+				if block.IsBlock(svcCtx.DB, req.UserID, request.RevUserID) {
+					if block.UserID == req.UserID {
+						SendAckErrMsg(client, request.ClientMsgID, "你已将对方拉黑，请先移出黑名单")
+						continue
+					}
+					SendAckErrMsg(client, request.ClientMsgID, "消息已发出，但被对方拒收了")
 					continue
 				}
 			}
-			if !(request.Msg.Type >= 1 && request.Msg.Type <= 14) {
-				SendTipErrMsg(conn, "消息类型错误")
+			// 正在输入的事件只转发给在线的对方，不入库
+			if request.Msg.Type == ctype.TypingMsgType {
+				if request.Msg.TypingMsg != nil && request.RevUserID != req.UserID {
+					relayTyping(svcCtx, req.UserID, request.RevUserID, request.Msg.TypingMsg.Typing)
+				}
+				continue
+			}
+			if !(request.Msg.Type >= 1 && request.Msg.Type <= 14) && request.Msg.Type != ctype.EditMsgType && request.Msg.Type != ctype.ReactionMsgType {
+				SendAckErrMsg(client, request.ClientMsgID, "消息类型错误")
 				continue
 			}
 			msgValidateErr := request.Msg.Validate()
 			if msgValidateErr != nil {
-				SendTipErrMsg(conn, msgValidateErr.Error())
+				SendAckErrMsg(client, request.ClientMsgID, msgValidateErr.Error())
 				continue
 			}
+			// 自动回复只能由服务端发送
+			if request.Msg.TextMsg != nil {
+				request.Msg.TextMsg.AutoReply = false
+			}
 			switch request.Msg.Type {
 			case ctype.TextMsgType:
 			case ctype.FileMsgType:
@@ -213,7 +249,7 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 				nameList := strings.Split(request.Msg.FileMsg.Src, "/")
 				// 如果无法获取文件名，则提示用户并跳过当前循环
 				if len(nameList) == 0 {
-					SendTipErrMsg(conn, "请上传文件")
+					SendAckErrMsg(client, request.ClientMsgID, "请上传文件")
 					continue
 				}
 				// 提取文件ID，即文件名部分
@@ -225,7 +261,7 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 				// 如果获取文件信息失败，则记录错误并提示用户
 				if err3 != nil {
 					logx.Error(err3)
-					SendTipErrMsg(conn, err3.Error())
+					SendAckErrMsg(client, request.ClientMsgID, err3.Error())
 					continue
 				}
 				// 更新文件消息的标题、大小和类型
@@ -237,35 +273,35 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			case ctype.WithdrawMsgType:
 				// 检查撤回消息的ID是否为空
 				if request.Msg.WithdrawMsg == nil {
-					SendTipErrMsg(conn, "撤回消息id不能为空")
+					SendAckErrMsg(client, request.ClientMsgID, "撤回消息id不能为空")
 					continue
 				}
 				if request.Msg.WithdrawMsg.MsgID == 0 {
-					SendTipErrMsg(conn, "撤回消息id不能为空")
+					SendAckErrMsg(client, request.ClientMsgID, "撤回消息id不能为空")
 					continue
 				}
 				// 只能撤回自己发送的消息，先找到消息的发送者
 				var msgModel chat_models.ChatModel
 				err = svcCtx.DB.Take(&msgModel, request.Msg.WithdrawMsg.MsgID).Error
 				if err != nil {
-					SendTipErrMsg(conn, "消息不存在")
+					SendAckErrMsg(client, request.ClientMsgID, "消息不存在")
 					continue
 				}
 				// 撤回的消息不能再次撤回
 				if msgModel.MsgType == ctype.WithdrawMsgType {
-					SendTipErrMsg(conn, "撤回消息不能再撤回")
+					SendAckErrMsg(client, request.ClientMsgID, "撤回消息不能再撤回")
 					continue
 				}
 				// 判断消息是否是当前用户发送的
 				if msgModel.SendUserID != req.UserID {
-					SendTipErrMsg(conn, "只能撤回自己发的消息")
+					SendAckErrMsg(client, request.ClientMsgID, "只能撤回自己发的消息")
 					continue
 				}
 				// 只能撤回两分钟内的消息
 				now := time.Now()
 				subTime := now.Sub(msgModel.CreatedAt)
 				if subTime >= time.Minute*2 {
-					SendTipErrMsg(conn, "只能撤回2分钟内的消息")
+					SendAckErrMsg(client, request.ClientMsgID, "只能撤回2分钟内的消息")
 					continue
 				}
 
@@ -296,36 +332,117 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 					},
 				})
 
+			// 编辑消息，编辑之后的内容写回原消息，编辑事件和撤回一样推送给双方
+			case ctype.EditMsgType:
+				editMsg := request.Msg.EditMsg
+				var msgModel chat_models.ChatModel
+				err = svcCtx.DB.Take(&msgModel, "id = ? and rev_user_id = ?", editMsg.MsgID, request.RevUserID).Error
+				if err != nil {
+					SendAckErrMsg(client, request.ClientMsgID, "消息不存在")
+					continue
+				}
+				if msgModel.SendUserID != req.UserID {
+					SendAckErrMsg(client, request.ClientMsgID, "只能编辑自己发的消息")
+					continue
+				}
+				if msgModel.MsgType == ctype.WithdrawMsgType {
+					SendAckErrMsg(client, request.ClientMsgID, "消息已被撤回")
+					continue
+				}
+				if msgModel.SystemMsg != nil {
+					SendAckErrMsg(client, request.ClientMsgID, "消息已被系统拦截")
+					continue
+				}
+				if msgModel.MsgType != editMsg.Msg.Type {
+					SendAckErrMsg(client, request.ClientMsgID, "不能修改消息的类型")
+					continue
+				}
+				if time.Since(msgModel.CreatedAt) >= time.Duration(svcCtx.Config.MsgEditMinutes)*time.Minute {
+					SendAckErrMsg(client, request.ClientMsgID, fmt.Sprintf("只能编辑%d分钟内的消息", svcCtx.Config.MsgEditMinutes))
+					continue
+				}
+				if editMsg.Msg.TextMsg != nil {
+					editMsg.Msg.TextMsg.AutoReply = false
+				}
+				// 内容没有变化的编辑不再记录版本，重发的编辑按成功回复ACK
+				if msgModel.Msg.Equal(*editMsg.Msg) {
+					SendAckMsg(client, request.ClientMsgID, chat_models.ChatModel{
+						ConversationID: msgModel.ConversationID,
+						Seq:            msgModel.EditSeq,
+					})
+					continue
+				}
+				err = msgModel.Edit(svcCtx.DB, *editMsg.Msg)
+				if err != nil {
+					logx.Error(err)
+					SendAckErrMsg(client, request.ClientMsgID, err.Error())
+					continue
+				}
+				editMsg.Revision = msgModel.EditCount
+				editMsg.EditedAt = *msgModel.EditedAt
+				editMsg.IsLatest = msgModel.IsLatest(svcCtx.DB)
+				editMsg.Seq = msgModel.EditSeq
+
+			// 表情回应，回应单独入库，把这条消息最新的回应汇总推送给双方
+			case ctype.ReactionMsgType:
+				reactionMsg := request.Msg.ReactionMsg
+				var msgModel chat_models.ChatModel
+				err = svcCtx.DB.Take(&msgModel, "id = ? and ((send_user_id = ? and rev_user_id = ?) or (send_user_id = ? and rev_user_id = ?))",
+					reactionMsg.MsgID, req.UserID, request.RevUserID, request.RevUserID, req.UserID).Error
+				if err != nil {
+					SendAckErrMsg(client, request.ClientMsgID, "消息不存在")
+					continue
+				}
+				if msgModel.MsgType == ctype.WithdrawMsgType {
+					SendAckErrMsg(client, request.ClientMsgID, "消息已被撤回")
+					continue
+				}
+				if reactionMsg.IsRemove {
+					err = chat_models.RemoveChatReaction(svcCtx.DB, msgModel.ID, req.UserID, reactionMsg.Emoji)
+				} else {
+					err = chat_models.AddChatReaction(svcCtx.DB, msgModel.ID, req.UserID, reactionMsg.Emoji)
+				}
+				if err != nil {
+					logx.Error(err)
+					SendAckErrMsg(client, request.ClientMsgID, "表情回应失败")
+					continue
+				}
+				reactionMsg.UserID = req.UserID
+				reactionMsg.Reactions = chat_models.ChatReactionMap(svcCtx.DB, []uint{msgModel.ID})[msgModel.ID]
+				if reactionMsg.Reactions == nil {
+					reactionMsg.Reactions = make([]ctype.Reaction, 0)
+				}
+
 			// 处理回复消息类型的情况
 			case ctype.ReplyMsgType:
 				// 检查回复消息的ID是否有效
 				if request.Msg.ReplyMsg == nil || request.Msg.ReplyMsg.MsgID == 0 {
-					SendTipErrMsg(conn, "回复消息id不能为空")
+					SendAckErrMsg(client, request.ClientMsgID, "回复消息id不能为空")
 					continue
 				}
 				// 从数据库中获取被回复的消息模型
 				var msgModel chat_models.ChatModel
 				err = svcCtx.DB.Take(&msgModel, request.Msg.ReplyMsg.MsgID).Error
 				if err != nil {
-					SendTipErrMsg(conn, "消息不存在")
+					SendAckErrMsg(client, request.ClientMsgID, "消息不存在")
 					continue
 				}
 				// 检查消息是否已被撤回
 				if msgModel.MsgType == ctype.WithdrawMsgType {
-					SendTipErrMsg(conn, "消息已被撤回")
+					SendAckErrMsg(client, request.ClientMsgID, "消息已被撤回")
 					continue
 				}
 				// 确保用户只能回复自己或对方的消息
 				if !((msgModel.SendUserID == req.UserID && msgModel.RevUserID == request.RevUserID) ||
 					(msgModel.SendUserID == request.RevUserID && msgModel.RevUserID == req.UserID)) {
-					SendTipErrMsg(conn, "只能回复自己的消息或对方的消息")
+					SendAckErrMsg(client, request.ClientMsgID, "只能回复自己的消息或对方的消息")
 					continue
 				}
 				// 获取发送用户的基本信息
 				userBaseInfo, err5 := redis_service.GetUserBaseInfo(svcCtx.Redis, svcCtx.UserRpc, msgModel.SendUserID)
 				if err5 != nil {
 					logx.Error(err5)
-					SendTipErrMsg(conn, err5.Error())
+					SendAckErrMsg(client, request.ClientMsgID, err5.Error())
 					continue
 				}
 				// 更新回复消息的信息，包括消息内容、发送用户ID、昵称和原始发送时间
@@ -338,31 +455,31 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 
 			case ctype.QuoteMsgType:
 				if request.Msg.QuoteMsg == nil || request.Msg.QuoteMsg.MsgID == 0 {
-					SendTipErrMsg(conn, "请选择要引用的消息")
+					SendAckErrMsg(client, request.ClientMsgID, "请选择要引用的消息")
 					continue
 				}
 				var msgModel chat_models.ChatModel
 				err = svcCtx.DB.Take(&msgModel, request.Msg.QuoteMsg.MsgID).Error
 
 				if err != nil {
-					SendTipErrMsg(conn, "消息不存在")
+					SendAckErrMsg(client, request.ClientMsgID, "消息不存在")
 					continue
 				}
 				if msgModel.MsgType == ctype.WithdrawMsgType {
-					SendTipErrMsg(conn, "消息已被撤回")
+					SendAckErrMsg(client, request.ClientMsgID, "消息已被撤回")
 					continue
 				}
 
 				if !((msgModel.SendUserID == req.UserID && msgModel.RevUserID == request.RevUserID) ||
 					(msgModel.SendUserID == request.RevUserID && msgModel.RevUserID == req.UserID)) {
-					SendTipErrMsg(conn, "只能引用自己的消息或对方的消息")
+					SendAckErrMsg(client, request.ClientMsgID, "只能引用自己的消息或对方的消息")
 					continue
 				}
 
 				userBaseInfo, err5 := redis_service.GetUserBaseInfo(svcCtx.Redis, svcCtx.UserRpc, msgModel.SendUserID)
 				if err5 != nil {
 					logx.Error(err5)
-					SendTipErrMsg(conn, err5.Error())
+					SendAckErrMsg(client, request.ClientMsgID, err5.Error())
 					continue
 				}
 
@@ -373,35 +490,33 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 
 			case ctype.VideoCallMsgType:
 				data := request.Msg.VideoCallMsg
-				_, ok2 := UserOnlineWsMap[request.RevUserID]
-				if !ok2 {
-					SendTipErrMsg(conn, "对方不在线")
+				if !svcCtx.WsRouter.IsOnline(request.RevUserID) {
+					SendAckErrMsg(client, request.ClientMsgID, "对方不在线")
 					continue
 				}
 				key := fmt.Sprintf("video_call_%d_%d", req.UserID, request.RevUserID)
 				switch data.Flag {
 				case 0:
 					//	给自己页面展示等待对方接听的弹框
-					err := conn.WriteJSON(ChatResponse{
+					if !client.SendJSON(ChatResponse{
 						Msg: ctype.Msg{
 							Type: ctype.VideoCallMsgType,
 							VideoCallMsg: &ctype.VideoCallMsg{
 								Flag: 1,
 							},
 						},
-					})
-					if err != nil {
+					}) {
 						return
 					}
 
-					sendRevUserMsg(request.RevUserID, req.UserID, ctype.Msg{
+					sendRevUserMsg(svcCtx, request.RevUserID, req.UserID, ctype.Msg{
 						Type: ctype.VideoCallMsgType,
 						VideoCallMsg: &ctype.VideoCallMsg{
 							Flag: 2,
 						},
 					})
 				case 1: //自己挂断
-					sendRevUserMsg(request.RevUserID, req.UserID, ctype.Msg{
+					sendRevUserMsg(svcCtx, request.RevUserID, req.UserID, ctype.Msg{
 						Type: ctype.VideoCallMsgType,
 						VideoCallMsg: &ctype.VideoCallMsg{
 							Flag: 3,
@@ -409,7 +524,7 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 						},
 					})
 				case 2: //对方挂断
-					sendRevUserMsg(request.RevUserID, req.UserID, ctype.Msg{
+					sendRevUserMsg(svcCtx, request.RevUserID, req.UserID, ctype.Msg{
 						Type: ctype.VideoCallMsgType,
 						VideoCallMsg: &ctype.VideoCallMsg{
 							Flag: 4,
@@ -417,7 +532,7 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 						},
 					})
 				case 3: //对方接听
-					sendRevUserMsg(request.RevUserID, req.UserID, ctype.Msg{
+					sendRevUserMsg(svcCtx, request.RevUserID, req.UserID, ctype.Msg{
 						Type: ctype.VideoCallMsgType,
 						VideoCallMsg: &ctype.VideoCallMsg{
 							Flag: 5,
@@ -426,8 +541,8 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 					})
 
 				case 4: // 正常挂断
-					// 根据通话双方的ID获取通话开始时间
-					startTime, ok3 := VideoCallMap[key]
+					// 根据通话双方的ID获取通话开始时间，通话的另一方可能连接在其他节点上
+					startTime, ok3 := getVideoCall(svcCtx, key)
 					// 定义发送方和接收方的用户ID
 					var sendUserID = req.UserID
 					var revUserID = request.RevUserID
@@ -437,11 +552,11 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 					var endReason int8
 					// 如果当前通话双方的开始时间不存在，则尝试交换双方ID后再次查找
 					if !ok3 {
-						key = fmt.Sprintf("%d %d", sendUserID, revUserID)
-						_startTime, ok4 := VideoCallMap[key]
+						key = fmt.Sprintf("video_call_%d_%d", request.RevUserID, req.UserID)
+						_startTime, ok4 := getVideoCall(svcCtx, key)
 						// 如果交换ID后仍找不到开始时间，则提示错误并继续下一轮循环
 						if !ok4 {
-							SendTipErrMsg(conn, "通话起始时间错误")
+							SendAckErrMsg(client, request.ClientMsgID, "通话起始时间错误")
 							continue
 						}
 						// 交换通话双方ID
@@ -463,26 +578,31 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 					request.Msg.VideoCallMsg.EndTime = time.Now()
 					request.Msg.VideoCallMsg.EndReason = endReason
 					// 发送挂断消息给通话双方
-					sendRevUserMsg(sendUserID, revUserID, ctype.Msg{
+					sendRevUserMsg(svcCtx, sendUserID, revUserID, ctype.Msg{
 						Type: ctype.VideoCallMsgType,
 						VideoCallMsg: &ctype.VideoCallMsg{
 							Flag: 6,
 							Msg:  "正常挂断",
 						},
 					})
-					// 将通话记录插入数据库，并获取消息ID
-					msgID := InsertMsgByChat(svcCtx.DB, revUserID, sendUserID, request.Msg)
+					// 将通话记录插入数据库，并获取消息ID和会话序号
+					chatModel, err4 := InsertMsgByChat(svcCtx.DB, revUserID, sendUserID, request.Msg, "")
+					if err4 != nil {
+						// 通话记录保留，客户端可以重新挂断
+						SendTipErrMsg(client, "消息入库失败")
+						continue
+					}
 					// 根据用户ID发送消息
-					SendMsgByUser(svcCtx, revUserID, sendUserID, request.Msg, msgID)
-					// 从通话映射中删除当前通话记录
-					delete(VideoCallMap, key)
+					SendMsgByUser(svcCtx, revUserID, sendUserID, request.Msg, chatModel.ID, chatModel.Seq)
+					// 删除当前通话记录
+					svcCtx.Redis.Del(key)
 
 				case 5:
 					//对方挂断
-					key = fmt.Sprintf("%d_%d", request.RevUserID, userInfo.ID)
-					startTime, ok3 := VideoCallMap[key]
+					key = fmt.Sprintf("video_call_%d_%d", request.RevUserID, userInfo.ID)
+					startTime, ok3 := getVideoCall(svcCtx, key)
 					if !ok3 {
-						SendTipErrMsg(conn, "通话起始时间错误")
+						SendAckErrMsg(client, request.ClientMsgID, "通话起始时间错误")
 						continue
 					}
 					subTime := time.Now().Sub(startTime)
@@ -492,19 +612,19 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 				switch data.Type {
 				case "offer": // 处理发起通话请求
 					// 向接收用户发送通话请求消息
-					sendRevUserMsg(request.RevUserID, req.UserID, ctype.Msg{
+					sendRevUserMsg(svcCtx, request.RevUserID, req.UserID, ctype.Msg{
 						Type: ctype.VideoCallMsgType,
 						VideoCallMsg: &ctype.VideoCallMsg{
 							Type: "offer",
 							Data: data.Data,
 						},
 					})
-					// 更新通话请求时间
-					VideoCallMap[key] = time.Now()
+					// 更新通话请求时间，通话记录放在redis里，挂断的一方可能连接在其他节点上
+					svcCtx.Redis.Set(key, time.Now().UnixNano(), 24*time.Hour)
 					fmt.Println("offer", key)
 				case "answer": // 处理通话应答
 					// 向接收用户发送通话应答消息
-					sendRevUserMsg(request.RevUserID, req.UserID, ctype.Msg{
+					sendRevUserMsg(svcCtx, request.RevUserID, req.UserID, ctype.Msg{
 						Type: ctype.WithdrawMsgType,
 						VideoCallMsg: &ctype.VideoCallMsg{
 							Type: "answer",
@@ -513,7 +633,7 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 					})
 				case "offer_ice": // 处理通话请求的ICE候选
 					// 向接收用户发送ICE候选消息
-					sendRevUserMsg(request.RevUserID, req.UserID, ctype.Msg{
+					sendRevUserMsg(svcCtx, request.RevUserID, req.UserID, ctype.Msg{
 						Type: ctype.VideoCallMsgType,
 						VideoCallMsg: &ctype.VideoCallMsg{
 							Type: "offer_ice",
@@ -522,7 +642,7 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 					})
 				case "answer_ice": // 处理通话应答的ICE候选
 					// 向接收用户发送ICE候选消息
-					sendRevUserMsg(request.RevUserID, req.UserID, ctype.Msg{
+					sendRevUserMsg(svcCtx, request.RevUserID, req.UserID, ctype.Msg{
 						Type: ctype.VideoCallMsgType,
 						VideoCallMsg: &ctype.VideoCallMsg{
 							Type: "answer_ice",
@@ -534,15 +654,66 @@ func chatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 				continue
 			}
 
-			msgID := InsertMsgByChat(svcCtx.DB, request.RevUserID, req.UserID, request.Msg)
-			SendMsgByUser(svcCtx, request.RevUserID, req.UserID, request.Msg, msgID)
+			chatModel, err4 := InsertMsgByChat(svcCtx.DB, request.RevUserID, req.UserID, request.Msg, request.ClientMsgID)
+			if err4 != nil {
+				// 两次重试同时到达的时候，另一次已经入库，这次撞上客户端消息id的唯一索引，按重复消息回复ACK
+				if request.ClientMsgID != "" {
+					storedModel, ok := chat_models.FindClientMsg(svcCtx.DB, req.UserID, request.ClientMsgID)
+					if ok {
+						SendAckMsg(client, request.ClientMsgID, storedModel)
+						continue
+					}
+				}
+				SendAckErrMsg(client, request.ClientMsgID, "消息入库失败")
+				continue
+			}
+			SendAckMsg(client, request.ClientMsgID, chatModel)
+			// 消息发出去之后对方的客户端会隐藏正在输入，这里只清掉状态
+			typingTracker.Stop(fmt.Sprintf("%d_%d", req.UserID, request.RevUserID))
+			SendMsgByUser(svcCtx, request.RevUserID, req.UserID, request.Msg, chatModel.ID, chatModel.Seq)
+			if request.Msg.Type != ctype.WithdrawMsgType && request.Msg.Type != ctype.EditMsgType && request.Msg.Type != ctype.ReactionMsgType {
+				autoReply(svcCtx, req.UserID, request.RevUserID)
+			}
 		}
 	}
 }
 
+// autoReply 接收者设置了自动回复的时候，以接收者的身份回复一条自动回复消息
+// 同一个会话在同一段时间里只回复一次，和普通消息一样入库和推送
+func autoReply(svcCtx *svc.ServiceContext, sendUserID, revUserID uint) {
+	if sendUserID == revUserID {
+		return
+	}
+	var conf user_models.UserConfModel
+	err := svcCtx.DB.Select("auto_reply", "presence", "presence_update_at").Take(&conf, "user_id = ?", revUserID).Error
+	if err != nil || conf.AutoReply == nil {
+		return
+	}
+	period, ok := conf.AutoReply.Period(time.Now(), conf.Presence, conf.PresenceUpdateAt)
+	if !ok {
+		return
+	}
+	if !redis_service.MarkAutoReply(svcCtx.Redis, revUserID, sendUserID, period) {
+		return
+	}
+	msg := ctype.Msg{
+		Type: ctype.TextMsgType,
+		TextMsg: &ctype.TextMsg{
+			Content:   conf.AutoReply.Content,
+			AutoReply: true,
+		},
+	}
+	chatModel, err := InsertMsgByChat(svcCtx.DB, sendUserID, revUserID, msg, "")
+	if err != nil {
+		return
+	}
+	SendMsgByUser(svcCtx, sendUserID, revUserID, msg, chatModel.ID, chatModel.Seq)
+}
+
 type Chatquest struct {
-	RevUserID uint      `json:"revUserID"`
-	Msg       ctype.Msg `json:"msg"`
+	RevUserID   uint      `json:"revUserID"`
+	ClientMsgID string    `json:"clientMsgID"` // 客户端生成的消息id，重发的时候不变，服务端按它去重并在ACK里带回
+	Msg         ctype.Msg `json:"msg"`
 }
 
 // SendMsgByUser 根据用户ID发送消息。
@@ -551,88 +722,80 @@ type Chatquest struct {
 // sendUserID: 发送消息的用户ID。
 // msg: 要发送的消息内容。
 // msgID: 消息的唯一ID。
-func SendMsgByUser(svcCtx *svc.ServiceContext, revUserID uint, sendUserID uint, msg ctype.Msg, msgID uint) {
-	// 从在线用户WebSocket映射中获取接收者和发送者的连接。
-	revUser, ok1 := UserOnlineWsMap[revUserID]
-	sendUser, ok2 := UserOnlineWsMap[sendUserID]
-
+// seq: 消息在会话内的序号，没有入库的消息为0。
+func SendMsgByUser(svcCtx *svc.ServiceContext, revUserID uint, sendUserID uint, msg ctype.Msg, msgID uint, seq int64) {
 	// 构建聊天响应对象。
 	resp := ChatResponse{
 		ID:         msgID,
 		Msg:        msg,
 		MsgPreview: msg.MsgPreview(), // 提取消息预览。
 		CreatedAt:  time.Now(),       // 记录当前时间作为创建时间。
+		Seq:        seq,
+	}
+	if seq > 0 {
+		resp.ConversationID = chat_models.ConversationID(revUserID, sendUserID)
 	}
 
-	// 当接收者、发送者都在在线映射中且不是自言自语时，构建详细的用户信息并发送消息。
-	if ok1 && ok2 && sendUserID == revUserID {
-		resp.RevUser = ctype.UserInfo{
-			ID:       revUserID,
-			NickName: revUser.UserInfo.Nickname,
-			Avatar:   revUser.UserInfo.Avatar,
-		}
-		resp.SendUser = ctype.UserInfo{
-			ID:       sendUserID,
-			NickName: sendUser.UserInfo.Nickname,
-			Avatar:   sendUser.UserInfo.Avatar,
-		}
-		resp.IsMe = true // 标记为是自己发送的消息。
-
-		// 将响应对象序列化为字节切片并发送给接收者的WebSocket客户端。
-		byteData, _ := json.Marshal(resp)
-		sendWsMapMsg(revUser.WsClientMap, byteData)
+	// 获取双方的用户信息，自动回复这类由服务端代发的消息，发送者可能不在线。
+	revUser, err := getUserInfo(svcCtx, revUserID)
+	if err != nil {
+		logx.Error(err)
 		return
 	}
-	// 处理接收者不在线的情况。
-	if !ok1 {
-		userBaseInfo, err := redis_service.GetUserBaseInfo(svcCtx.Redis, svcCtx.UserRpc, revUserID)
-		if err != nil {
-			logx.Error(err)
-			return
-		}
-		resp.RevUser = ctype.UserInfo{
-			ID:       revUserID,
-			NickName: userBaseInfo.NickName,
-			Avatar:   userBaseInfo.Avatar,
-		}
-	} else {
-		resp.RevUser = ctype.UserInfo{
-			ID:       revUserID,
-			NickName: revUser.UserInfo.Nickname,
-			Avatar:   revUser.UserInfo.Avatar,
-		}
-	}
-	// 设置发送用户信息。
-	resp.SendUser = ctype.UserInfo{
-		ID:       sendUserID,
-		NickName: sendUser.UserInfo.Nickname,
-		Avatar:   sendUser.UserInfo.Avatar,
+	sendUser, err := getUserInfo(svcCtx, sendUserID)
+	if err != nil {
+		logx.Error(err)
+		return
 	}
+	resp.RevUser = revUser
+	resp.SendUser = sendUser
+
+	// 发给发送者的所有设备，包括其他节点上的连接。
 	resp.IsMe = true
 	byteData, _ := json.Marshal(resp)
-	sendWsMapMsg(sendUser.WsClientMap, byteData)
-	// 如果接收者在线，则发送消息给接收者。
-	if ok1 {
-		resp.IsMe = false
-		byteData, _ := json.Marshal(resp)
-		sendWsMapMsg(revUser.WsClientMap, byteData)
+	sendUserMsg(svcCtx, sendUserID, byteData)
+	if sendUserID == revUserID {
+		return
 	}
+
+	// 发给接收者，接收者不在线的时候没有任何连接，不做处理。
+	resp.IsMe = false
+	byteData, _ = json.Marshal(resp)
+	sendUserMsg(svcCtx, revUserID, byteData)
 }
 
-// sendWsMapMsg 向所有在wsMap中的WebSocket连接发送数据。
-//
-// 参数:
-// wsMap - 一个映射，其中包含所有需要发送数据的WebSocket连接，键为连接的标识符，值为连接对象。
-// byteData - 要发送的数据，类型为字节切片。
-//
-// 该函数遍历wsMap中的所有连接，并向每个连接发送byteData数据。发送的数据类型为文本消息。
-func sendWsMapMsg(wsMap map[string]*websocket.Conn, byteData []byte) {
-	for _, conn := range wsMap {
-		err := conn.WriteMessage(websocket.TextMessage, byteData)
-		if err != nil {
-			return
-		}
+// getUserInfo 获取用户的昵称和头像，用户在这个节点上在线的时候直接取连接里的信息，否则走缓存
+func getUserInfo(svcCtx *svc.ServiceContext, userID uint) (ctype.UserInfo, error) {
+	if userInfo, ok := svcCtx.WsHub.Info(userID); ok {
+		return ctype.UserInfo{
+			ID:       userID,
+			NickName: userInfo.Nickname,
+			Avatar:   userInfo.Avatar,
+		}, nil
+	}
+	userInfo, err := redis_service.GetUserBaseInfo(svcCtx.Redis, svcCtx.UserRpc, userID)
+	userInfo.ID = userID
+	return userInfo, err
+}
+
+// sendUserMsg 把推送发给用户的所有连接，这个节点上的连接直接发送，其他节点上的连接通过路由转发
+func sendUserMsg(svcCtx *svc.ServiceContext, userID uint, byteData []byte) {
+	svcCtx.WsHub.SendToUser(userID, byteData)
+	svcCtx.WsRouter.Forward([]uint{userID}, byteData)
+}
+
+// SendLocalMsg 把推送发给这个节点上该用户的所有连接，用户不在这个节点上的时候不做处理
+func SendLocalMsg(svcCtx *svc.ServiceContext, userID uint, byteData []byte) {
+	svcCtx.WsHub.SendToUser(userID, byteData)
+}
+
+// getVideoCall 获取通话的开始时间
+func getVideoCall(svcCtx *svc.ServiceContext, key string) (time.Time, bool) {
+	nano, err := svcCtx.Redis.Get(key).Int64()
+	if err != nil {
+		return time.Time{}, false
 	}
+	return time.Unix(0, nano), true
 }
 
 // InsertMsgByChat 根据聊天内容插入消息到数据库。
@@ -641,107 +804,131 @@ func sendWsMapMsg(wsMap map[string]*websocket.Conn, byteData []byte) {
 // revUserID: 接收用户的ID。
 // sendUserID: 发送用户的ID。
 // msg: 待插入的消息对象。
+// clientMsgID: 客户端生成的消息id，服务端代发的消息为空。
 // 返回值:
-// msgID: 插入消息的ID，若操作失败则返回0。
-func InsertMsgByChat(db *gorm.DB, revUserID uint, sendUserID uint, msg ctype.Msg) (msgID uint) {
-	// 处理撤回消息的特殊情况，撤回消息通常不需要存入数据库。
-	if msg.Type == ctype.WithdrawMsgType {
+// chatModel: 入库之后的消息，包含消息ID、会话序号和入库时间，撤回消息不入库，返回空的消息。
+// err: 入库失败时返回错误，由调用方通知发送用户。
+func InsertMsgByChat(db *gorm.DB, revUserID uint, sendUserID uint, msg ctype.Msg, clientMsgID string) (chatModel chat_models.ChatModel, err error) {
+	// 编辑消息修改的是原消息，自己不入库，带回编辑分配的会话序号，ACK和推送里的序号用它
+	if msg.Type == ctype.EditMsgType {
+		chatModel.ConversationID = chat_models.ConversationID(sendUserID, revUserID)
+		chatModel.Seq = msg.EditMsg.Seq
+		return
+	}
+	// 处理撤回消息和表情回应的特殊情况，它们修改的是原消息，自己不需要存入数据库。
+	if msg.Type == ctype.WithdrawMsgType || msg.Type == ctype.ReactionMsgType {
 		fmt.Println("撤回消息自己是不入库的")
 		return
 	}
 
 	// 初始化ChatModel对象，准备将消息数据存入数据库。
-	chatModel := chat_models.ChatModel{
+	chatModel = chat_models.ChatModel{
 		SendUserID: sendUserID,
 		RevUserID:  revUserID,
 		MsgType:    msg.Type,
 		Msg:        msg,
 	}
+	if clientMsgID != "" {
+		chatModel.ClientMsgID = &clientMsgID
+	}
 
 	// 生成消息预览并赋值给ChatModel。
 	chatModel.MsgPreview = chatModel.MsgPreviewMethod()
 
-	// 尝试将消息数据插入数据库。
-	err := db.Create(&chatModel).Error
+	// 分配会话序号并将消息数据插入数据库。
+	err = chatModel.Create(db)
 	if err != nil {
-		// 记录错误日志，并尝试通知发送用户消息入库失败。
 		logx.Error(err)
-		sendUser, ok := UserOnlineWsMap[sendUserID]
-		if !ok {
-			return
-		}
-		SendTipErrMsg(sendUser.currentConn, "消息入库失败")
-	}
-
-	// 返回成功插入消息的ID。
-	return chatModel.ID
-}
-
-// sendMapMsg 向websocket连接映射中的所有连接发送字节数据。
-// wsMap: 连接映射，键为连接标识，值为websocket连接对象。
-// byteData: 需要发送的字节数据。
-func sendMapMsg(wsMap map[string]*websocket.Conn, byteData []byte) {
-	// 遍历映射中的所有连接
-	for _, conn := range wsMap {
-		// 向每个连接发送字节数据
-		err := conn.WriteMessage(websocket.TextMessage, byteData)
-		if err != nil {
-			return
-		}
+		return chatModel, err
 	}
+	return chatModel, nil
 }
 
-// sendRevUserMsg 向指定接收者发送消息
+// sendRevUserMsg 向指定接收者发送消息，用于音视频通话的信令，不入库
 // 参数:
+// svcCtx - 服务上下文
 // revUserID - 接收消息的用户ID
 // sendUserID - 发送消息的用户ID
 // msg - 要发送的消息对象
-func sendRevUserMsg(revUserID uint, sendUserID uint, msg ctype.Msg) {
-	// 尝试从在线用户映射中获取接收者的信息
-	userRes, ok := UserOnlineWsMap[revUserID]
-	// 如果接收者不在线，则直接返回
-	if !ok {
+func sendRevUserMsg(svcCtx *svc.ServiceContext, revUserID uint, sendUserID uint, msg ctype.Msg) {
+	// 获取双方的用户信息，接收者可能连接在其他节点上
+	revUserInfo, err := getUserInfo(svcCtx, revUserID)
+	if err != nil {
+		logx.Error(err)
+		return
+	}
+	sendUserInfo, err := getUserInfo(svcCtx, sendUserID)
+	if err != nil {
+		logx.Error(err)
 		return
 	}
 
-	// 尝试从在线用户映射中获取发送者的信息
-	sendUser, ok1 := UserOnlineWsMap[sendUserID]
-	var sendUserInfo ctype.UserInfo
-	// 如果发送者在线，则构建发送者的用户信息
-	if ok1 {
-		sendUserInfo = ctype.UserInfo{
-			ID:       sendUser.UserInfo.ID,
-			NickName: sendUser.UserInfo.Nickname,
-			Avatar:   sendUser.UserInfo.Avatar,
-		}
+	// 构建并发送聊天响应
+	byteData, _ := json.Marshal(ChatResponse{
+		SendUser:   sendUserInfo,
+		RevUser:    revUserInfo,
+		MsgPreview: msg.MsgPreview(),
+		Msg:        msg,
+		CreatedAt:  time.Now(),
+	})
+	sendUserMsg(svcCtx, revUserID, byteData)
+}
+
+// SendAckMsg 消息入库成功之后回复ACK，只发给发消息的这个连接
+// clientMsgID: 客户端生成的消息id，为空的时候客户端不需要ACK，不做处理。
+// chatModel: 入库之后的消息，撤回消息、编辑消息和表情回应没有入库，消息ID和序号为0。
+func SendAckMsg(client *ws_hub.Client, clientMsgID string, chatModel chat_models.ChatModel) {
+	if clientMsgID == "" {
+		return
+	}
+	createdAt := chatModel.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
 	}
+	resp := ChatResponse{
+		ID: chatModel.ID,
+		Msg: ctype.Msg{
+			Type: ctype.AckMsgType,
+			AckMsg: &ctype.AckMsg{
+				ClientMsgID:    clientMsgID,
+				Status:         "success",
+				MsgID:          chatModel.ID,
+				ConversationID: chatModel.ConversationID,
+				Seq:            chatModel.Seq,
+			},
+		},
+		ConversationID: chatModel.ConversationID,
+		Seq:            chatModel.Seq,
+		CreatedAt:      createdAt,
+	}
+	client.SendJSON(resp)
+}
 
-	// 遍历接收者的WebSocket连接并发送消息
-	for _, conn := range userRes.WsClientMap {
-		// 构建并发送聊天响应
-		err := conn.WriteJSON(ChatResponse{
-			SendUser: sendUserInfo,
-			RevUser: ctype.UserInfo{
-				ID:       userRes.UserInfo.ID,
-				NickName: userRes.UserInfo.Nickname,
-				Avatar:   userRes.UserInfo.Avatar,
+// SendAckErrMsg 消息发送失败的时候回复失败的ACK，客户端可以用同一个clientMsgID重发
+// 没有clientMsgID的时候退化为普通的错误提示
+func SendAckErrMsg(client *ws_hub.Client, clientMsgID string, msg string) {
+	if clientMsgID == "" {
+		SendTipErrMsg(client, msg)
+		return
+	}
+	resp := ChatResponse{
+		Msg: ctype.Msg{
+			Type: ctype.AckMsgType,
+			AckMsg: &ctype.AckMsg{
+				ClientMsgID: clientMsgID,
+				Status:      "error",
+				Content:     msg,
 			},
-			MsgPreview: msg.MsgPreview(),
-			Msg:        msg,
-			CreatedAt:  time.Now(),
-		})
-		if err != nil {
-			return
-		}
-		// 发送完一条消息后即退出循环，这里可能需要解释为什么这样做
-		break
+		},
+		CreatedAt: time.Now(),
 	}
+	client.SendJSON(resp)
 }
 
 // SendTipErrMsg 向指定的websocket连接发送提示错误消息。
-// conn: 需要发送消息的websocket连接对象。
+// client: 需要发送消息的websocket连接。
 // msg: 错误提示内容。
-func SendTipErrMsg(conn *websocket.Conn, msg string) {
+func SendTipErrMsg(client *ws_hub.Client, msg string) {
 	// 构建响应消息，包含错误提示信息
 	resp := ChatResponse{
 		Msg: ctype.Msg{
@@ -753,11 +940,155 @@ func SendTipErrMsg(conn *websocket.Conn, msg string) {
 		},
 		CreatedAt: time.Now(),
 	}
-	// 将响应消息序列化为JSON字节数据
-	byteData, _ := json.Marshal(resp)
-	// 向连接发送序列化后的错误提示消息
-	err := conn.WriteMessage(websocket.TextMessage, byteData)
+	// 放进连接的发送队列
+	client.SendJSON(resp)
+}
+
+// UserInfoChange 处理用户资料变更
+// 刷新这个节点上该用户连接里保存的用户信息，并把新的昵称和头像推送给在线的好友和自己的其他设备
+func UserInfoChange(svcCtx *svc.ServiceContext, userID uint) {
+	res, err := svcCtx.UserRpc.UserInfo(context.Background(), &user_rpc.UserInfoRequest{
+		UserId: uint32(userID),
+	})
+	if err != nil {
+		logx.Error(err)
+		return
+	}
+	var userInfo user_models.UserModel
+	err = json.Unmarshal(res.Data, &userInfo)
 	if err != nil {
+		logx.Error(err)
 		return
 	}
+	// 账号被封禁、停用或者注销之后所有的登录都已经失效，断开这个节点上该用户的连接
+	if !userInfo.IsActive() {
+		svcCtx.WsHub.CloseUser(userID)
+		return
+	}
+	userBaseInfo, err := redis_service.GetUserBaseInfo(svcCtx.Redis, svcCtx.UserRpc, userID)
+	if err != nil {
+		logx.Error(err)
+		return
+	}
+
+	resp := ChatResponse{
+		SendUser: userBaseInfo,
+		Msg: ctype.Msg{
+			Type: ctype.UserInfoChangeMsgType,
+			UserInfoChangeMsg: &ctype.UserInfoChangeMsg{
+				UserID:   userID,
+				NickName: userBaseInfo.NickName,
+				Avatar:   userBaseInfo.Avatar,
+			},
+		},
+		CreatedAt: time.Now(),
+	}
+	byteData, _ := json.Marshal(resp)
+
+	svcCtx.WsHub.SetInfo(userID, userInfo)
+	svcCtx.WsHub.SendToUser(userID, byteData)
+
+	var friend user_models.FriendModel
+	var block user_models.UserBlockModel
+	for _, model := range friend.Friends(svcCtx.DB, userID) {
+		friendID := model.SendUserID
+		if friendID == userID {
+			friendID = model.RevUserID
+		}
+		if !svcCtx.WsHub.IsOnline(friendID) || block.IsBlock(svcCtx.DB, userID, friendID) {
+			continue
+		}
+		svcCtx.WsHub.SendToUser(friendID, byteData)
+	}
+}
+
+// Notification 把通知推送给这个节点上该用户的所有连接，用户不在线的时候不做处理
+func Notification(svcCtx *svc.ServiceContext, userID uint, msg ctype.NotificationMsg) {
+	if !svcCtx.WsHub.IsOnline(userID) {
+		return
+	}
+	resp := ChatResponse{
+		Msg: ctype.Msg{
+			Type:            ctype.NotificationMsgType,
+			NotificationMsg: &msg,
+		},
+		CreatedAt: time.Now(),
+	}
+	if msg.SendUserID != 0 {
+		userBaseInfo, err := redis_service.GetUserBaseInfo(svcCtx.Redis, svcCtx.UserRpc, msg.SendUserID)
+		if err == nil {
+			resp.SendUser = userBaseInfo
+		}
+	}
+	byteData, _ := json.Marshal(resp)
+	svcCtx.WsHub.SendToUser(userID, byteData)
+}
+
+// PreferenceChange 把偏好设置的变更推送给这个节点上该用户的所有连接，客户端根据版本号忽略自己发起的修改
+func PreferenceChange(svcCtx *svc.ServiceContext, userID uint, msg ctype.PreferenceChangeMsg) {
+	if !svcCtx.WsHub.IsOnline(userID) {
+		return
+	}
+	resp := ChatResponse{
+		Msg: ctype.Msg{
+			Type:                ctype.PreferenceChangeMsgType,
+			PreferenceChangeMsg: &msg,
+		},
+		CreatedAt: time.Now(),
+	}
+	byteData, _ := json.Marshal(resp)
+	svcCtx.WsHub.SendToUser(userID, byteData)
+}
+
+// ReadReceipt 把已读回执推送给这个节点上该用户的所有连接
+// 推给消息的发送者是已读回执，推给已读用户自己是同步其他设备的未读数
+func ReadReceipt(svcCtx *svc.ServiceContext, userID uint, msg ctype.ReadReceiptMsg) {
+	if !svcCtx.WsHub.IsOnline(userID) {
+		return
+	}
+	resp := ChatResponse{
+		Msg: ctype.Msg{
+			Type:           ctype.ReadReceiptMsgType,
+			ReadReceiptMsg: &msg,
+		},
+		ConversationID: msg.ConversationID,
+		Seq:            msg.Seq,
+		CreatedAt:      time.Now(),
+	}
+	byteData, _ := json.Marshal(resp)
+	svcCtx.WsHub.SendToUser(userID, byteData)
+}
+
+// relayTyping 转发正在输入的状态
+// 同一个会话限制转发的频率，没有收到停止输入的时候自动按停止输入转发
+func relayTyping(svcCtx *svc.ServiceContext, userID, revUserID uint, typing bool) {
+	key := fmt.Sprintf("%d_%d", userID, revUserID)
+	if typing {
+		if !typingTracker.Start(key, func() {
+			sendTypingMsg(svcCtx, userID, revUserID, false)
+		}) {
+			return
+		}
+	} else if !typingTracker.Stop(key) {
+		return
+	}
+	sendTypingMsg(svcCtx, userID, revUserID, typing)
+}
+
+// sendTypingMsg 把正在输入的状态推送给对方的所有连接，对方不在线的时候不做处理
+func sendTypingMsg(svcCtx *svc.ServiceContext, userID, revUserID uint, typing bool) {
+	resp := ChatResponse{
+		SendUser: ctype.UserInfo{ID: userID},
+		Msg: ctype.Msg{
+			Type: ctype.TypingMsgType,
+			TypingMsg: &ctype.TypingMsg{
+				UserID: userID,
+				Typing: typing,
+				Expire: int(typingTracker.Expire() / time.Second),
+			},
+		},
+		CreatedAt: time.Now(),
+	}
+	byteData, _ := json.Marshal(resp)
+	sendUserMsg(svcCtx, revUserID, byteData)
 }
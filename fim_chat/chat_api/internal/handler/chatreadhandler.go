@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"fim/common/response"
+	"fim/fim_chat/chat_api/internal/logic"
+	"fim/fim_chat/chat_api/internal/svc"
+	"fim/fim_chat/chat_api/internal/types"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func chatReadHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ChatReadRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			response.Response(r, w, nil, err)
+			return
+		}
+
+		l := logic.NewChatReadLogic(r.Context(), svcCtx)
+		resp, err := l.ChatRead(&req)
+		response.Response(r, w, resp, err)
+
+	}
+}
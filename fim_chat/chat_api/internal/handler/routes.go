@@ -22,11 +22,26 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/chat/history",
 				Handler: chatHistoryHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/chat/read",
+				Handler: chatReadHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/chat/revision",
+				Handler: chatRevisionHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/api/chat/session",
 				Handler: chatSessionHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/chat/sync",
+				Handler: chatSyncHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodPost,
 				Path:    "/api/chat/user_top",
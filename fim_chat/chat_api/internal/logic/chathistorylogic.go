@@ -6,6 +6,7 @@ import (
 	"fim/common/list_query"
 	"fim/common/models"
 	"fim/common/models/ctype"
+	"fim/common/service/redis_service"
 	"fim/fim_chat/chat_models"
 	"fim/fim_user/user_rpc/types/user_rpc"
 	"fim/utils"
@@ -24,6 +25,7 @@ type ChatHistoryLogic struct {
 }
 type ChatHistory struct {
 	ID        uint             `json:"id"`
+	Seq       int64            `json:"seq"`
 	SendUser  ctype.UserInfo   `json:"sendUser"`
 	RecvUser  ctype.UserInfo   `json:"recvUser"`
 	IsMe      bool             `json:"isMe"`
@@ -31,6 +33,9 @@ type ChatHistory struct {
 	Msg       ctype.Msg        `json:"msg"`
 	SystemMsg *ctype.SystemMsg `json:"systemMsg"`
 	ShowDate  bool             `json:"showDate"`
+	IsEdited  bool             `json:"isEdited"`            // 是否编辑过，客户端显示已编辑
+	EditedAt  string           `json:"editedAt,omitempty"`  // 最后一次编辑的时间
+	Reactions []ctype.Reaction `json:"reactions,omitempty"` // 表情回应的汇总
 }
 type ChatHistoryResponse struct {
 	List  []ChatHistory `json:"list"`
@@ -57,7 +62,7 @@ func (l *ChatHistoryLogic) ChatHistory(req *types.ChatHisoryRequest) (resp *Chat
 			User2: uint32(req.FriendID),
 		})
 		if err != nil {
-			return
+			return nil, err
 		}
 		if !res.IsFriend {
 			return nil, errors.New("你们还不是好友")
@@ -76,20 +81,19 @@ func (l *ChatHistoryLogic) ChatHistory(req *types.ChatHisoryRequest) (resp *Chat
 	})
 
 	// 构建用户ID列表，用于后续获取用户信息
-	var userIDList []uint32
+	var userIDList []uint
+	var chatIDList []uint
 	for _, model := range chatList {
-		userIDList = append(userIDList, uint32(model.SendUserID))
-		userIDList = append(userIDList, uint32(model.RevUserID))
+		userIDList = append(userIDList, model.SendUserID)
+		userIDList = append(userIDList, model.RevUserID)
+		chatIDList = append(chatIDList, model.ID)
 	}
-	// 去重用户ID列表
-	// 去重
-	userIDList = utils.DeduplicationList(userIDList)
 
-	// 通过用户ID列表获取用户信息
-	// 获取用户信息
-	response, err := l.svcCtx.UserRpc.UserListInfo(l.ctx, &user_rpc.UserListInfoRequest{
-		UserIdList: userIDList,
-	})
+	// 批量获取消息的表情回应
+	reactionMap := chat_models.ChatReactionMap(l.svcCtx.DB, chatIDList)
+
+	// 批量获取用户信息，优先走缓存
+	userInfoMap, err := redis_service.GetUserListInfo(l.svcCtx.Redis, l.svcCtx.UserRpc, userIDList)
 	if err != nil {
 		logx.Error(err)
 		return nil, errors.New("获取用户信息失败")
@@ -99,23 +103,23 @@ func (l *ChatHistoryLogic) ChatHistory(req *types.ChatHisoryRequest) (resp *Chat
 	var list = make([]ChatHistory, 0)
 	utils.ReverseAny(chatList)
 	for index, model := range chatList {
-		sendUser := ctype.UserInfo{
-			ID:       model.SendUserID,
-			NickName: response.UserInfo[uint32(model.SendUserID)].NickName,
-			Avatar:   response.UserInfo[uint32(model.SendUserID)].Avatar,
-		}
-		revUser := ctype.UserInfo{
-			ID:       model.RevUserID,
-			NickName: response.UserInfo[uint32(model.RevUserID)].NickName,
-			Avatar:   response.UserInfo[uint32(model.RevUserID)].Avatar,
-		}
+		sendUser := userInfoMap[model.SendUserID]
+		sendUser.ID = model.SendUserID
+		revUser := userInfoMap[model.RevUserID]
+		revUser.ID = model.RevUserID
 		info := ChatHistory{
 			ID:        model.ID,
+			Seq:       model.Seq,
 			CreatedAt: model.CreatedAt.Format("2006-01-02 15:04:05"),
 			SendUser:  sendUser,
 			RecvUser:  revUser,
 			Msg:       model.Msg,
 			SystemMsg: model.SystemMsg,
+			Reactions: reactionMap[model.ID],
+		}
+		if model.IsEdited() {
+			info.IsEdited = true
+			info.EditedAt = model.EditedAt.Format("2006-01-02 15:04:05")
 		}
 		// 根据时间间隔决定是否显示日期
 		if index == 0 {
@@ -0,0 +1,70 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/common/models/ctype"
+	"fim/common/service/redis_service"
+	"fim/fim_chat/chat_models"
+	"fim/fim_user/user_models"
+
+	"fim/fim_chat/chat_api/internal/svc"
+	"fim/fim_chat/chat_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ChatReadLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewChatReadLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ChatReadLogic {
+	return &ChatReadLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ChatRead 标记和对方的会话已读
+// 已读位置有变化的时候，同步给自己的其他设备，并且给对方发送已读回执
+// 自己关闭了已读回执，或者存在拉黑关系的时候，不给对方发送已读回执
+func (l *ChatReadLogic) ChatRead(req *types.ChatReadRequest) (resp *types.ChatReadResponse, err error) {
+	if req.FriendID == 0 {
+		return nil, errors.New("请选择会话")
+	}
+	readSeq, changed, err := chat_models.MarkChatRead(l.svcCtx.DB, req.UserID, req.FriendID, req.Seq)
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("标记已读失败")
+	}
+	resp = &types.ChatReadResponse{Seq: readSeq}
+	if !changed {
+		return resp, nil
+	}
+
+	msg := ctype.ReadReceiptMsg{
+		UserID:         req.UserID,
+		TargetID:       req.FriendID,
+		ConversationID: chat_models.ConversationID(req.UserID, req.FriendID),
+		Seq:            readSeq,
+	}
+	redis_service.PublishReadReceipt(l.svcCtx.Redis, req.UserID, msg)
+	if req.FriendID == req.UserID {
+		return resp, nil
+	}
+
+	var hideReadReceipt bool
+	l.svcCtx.DB.Model(&user_models.UserConfModel{}).Where("user_id = ?", req.UserID).Select("hide_read_receipt").Scan(&hideReadReceipt)
+	if hideReadReceipt {
+		return resp, nil
+	}
+	var block user_models.UserBlockModel
+	if block.IsBlock(l.svcCtx.DB, req.UserID, req.FriendID) {
+		return resp, nil
+	}
+	redis_service.PublishReadReceipt(l.svcCtx.Redis, req.FriendID, msg)
+	return resp, nil
+}
@@ -0,0 +1,62 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/common/models/ctype"
+	"fim/fim_chat/chat_models"
+
+	"fim/fim_chat/chat_api/internal/svc"
+	"fim/fim_chat/chat_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ChatRevisionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+type ChatRevision struct {
+	Revision   int       `json:"revision"` // 版本号，0是最初发出的版本
+	MsgPreview string    `json:"msgPreview"`
+	Msg        ctype.Msg `json:"msg"`
+	CreatedAt  string    `json:"createdAt"` // 这个版本被替换的时间
+}
+type ChatRevisionResponse struct {
+	List []ChatRevision `json:"list"` // 编辑之前的版本，按版本号从小到大，不包括当前的版本
+}
+
+func NewChatRevisionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ChatRevisionLogic {
+	return &ChatRevisionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ChatRevision 查询私聊消息的编辑历史，只有消息的双方可以查询，自己删除的消息查不到
+func (l *ChatRevisionLogic) ChatRevision(req *types.ChatRevisionRequest) (resp *ChatRevisionResponse, err error) {
+	var chat chat_models.ChatModel
+	err = l.svcCtx.DB.Take(&chat, "id = ? and (send_user_id = ? or rev_user_id = ?) and id not in (select chat_id from user_chat_delete_models where user_id = ?)",
+		req.ID, req.UserID, req.UserID, req.UserID).Error
+	if err != nil {
+		return nil, errors.New("消息不存在")
+	}
+	if chat.MsgType == ctype.WithdrawMsgType {
+		return nil, errors.New("消息已被撤回")
+	}
+
+	var revisionList []chat_models.ChatRevisionModel
+	l.svcCtx.DB.Where("chat_id = ?", chat.ID).Order("revision").Find(&revisionList)
+	resp = &ChatRevisionResponse{List: make([]ChatRevision, 0)}
+	for _, model := range revisionList {
+		resp.List = append(resp.List, ChatRevision{
+			Revision:   model.Revision,
+			MsgPreview: model.MsgPreview,
+			Msg:        model.Msg,
+			CreatedAt:  model.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return resp, nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fim/common/list_query"
 	"fim/common/models"
+	"fim/common/service/redis_service"
 	"fim/fim_chat/chat_models"
 	"fim/fim_user/user_rpc/types/user_rpc"
 	"fmt"
@@ -76,11 +77,11 @@ func (l *ChatSessionLogic) ChatSession(req *types.ChatSessionRequest) (resp *typ
 		Table: func() (string, any) {
 			return "(?) as u", l.svcCtx.DB.Model(&chat_models.ChatModel{}).
 				Select("least(send_user_id,rev_user_id)as sU",
-					"greatest(send_user-id,rev_user_id)as rU",
-					"max(date)as maxDate",
-					fmt.Sprintf("(select msg_preview from chat_models where((send_user_id=sU and rev_user_id=rUor (send_user_id=rU and rev_user_id=sU))and id not in (select chat_id from user_chat_delete_models where user_id=%d)order by created_at desc limit 1))as maxPreview", req.UserID),
+					"greatest(send_user_id,rev_user_id)as rU",
+					"max(created_at)as maxDate",
+					fmt.Sprintf("(select msg_preview from chat_models where((send_user_id=sU and rev_user_id=rU)or (send_user_id=rU and rev_user_id=sU))and id not in (select chat_id from user_chat_delete_models where user_id=%d)order by created_at desc limit 1)as maxPreview", req.UserID),
 					column).
-				Where("(send_user_id=? or rev_user_id =?)and id not in (select chat_id from user_chat_delete_models where user_id= ?)and (send_user_id=?and rev_user_id in ?)or (rev_user_id=?and send_user_id in ?)",
+				Where("(send_user_id=? or rev_user_id =?)and id not in (select chat_id from user_chat_delete_models where user_id= ?)and ((send_user_id=?and rev_user_id in ?)or (rev_user_id=?and send_user_id in ?))",
 					req.UserID, req.UserID, req.UserID, req.UserID, friendIDList, req.UserID, friendIDList).
 				Group("least(send_user_id,rev_user_id)").
 				Group("greatest(send_user_id,rev_user_id)")
@@ -89,23 +90,21 @@ func (l *ChatSessionLogic) ChatSession(req *types.ChatSessionRequest) (resp *typ
 	})
 
 	// 收集会话中涉及的用户ID，为后续获取用户信息做准备
-	var userIDList []uint32
+	var userIDList []uint
 	for _, data := range chatList {
 		if data.RU != req.UserID {
-			userIDList = append(userIDList, uint32(data.RU))
+			userIDList = append(userIDList, data.RU)
 		}
 		if data.SU != req.UserID {
-			userIDList = append(userIDList, uint32(data.SU))
+			userIDList = append(userIDList, data.SU)
 		}
 		if data.SU == req.UserID && req.UserID == data.RU {
-			userIDList = append(userIDList, uint32(req.UserID))
+			userIDList = append(userIDList, req.UserID)
 		}
 	}
 
-	// 根据用户ID列表获取用户基本信息
-	response, err := l.svcCtx.UserRpc.UserListInfo(l.ctx, &user_rpc.UserListInfoRequest{
-		UserIdList: userIDList,
-	})
+	// 根据用户ID列表批量获取用户基本信息，优先走缓存
+	userInfoMap, err := redis_service.GetUserListInfo(l.svcCtx.Redis, l.svcCtx.UserRpc, userIDList)
 	if err != nil {
 		logx.Error(err)
 		return nil, errors.New("获取用户信息失败")
@@ -126,6 +125,7 @@ func (l *ChatSessionLogic) ChatSession(req *types.ChatSessionRequest) (resp *typ
 
 	// 构建最终的会话响应列表
 	var list = make([]types.ChatSession, 0)
+	var targetIDList []uint
 	for _, data := range chatList {
 		s := types.ChatSession{
 			CreatedAt:  data.MaxDate,
@@ -134,23 +134,30 @@ func (l *ChatSessionLogic) ChatSession(req *types.ChatSessionRequest) (resp *typ
 		}
 		if data.RU != req.UserID {
 			s.UserID = data.RU
-			s.Avatar = response.UserInfo[uint32(s.UserID)].Avatar
-			s.Nickname = response.UserInfo[uint32(s.UserID)].NickName
+			s.Avatar = userInfoMap[s.UserID].Avatar
+			s.Nickname = userInfoMap[s.UserID].NickName
 		}
 		if data.SU != req.UserID {
 			s.UserID = data.SU
-			s.Avatar = response.UserInfo[uint32(s.UserID)].Avatar
-			s.Nickname = response.UserInfo[uint32(s.UserID)].NickName
+			s.Avatar = userInfoMap[s.UserID].Avatar
+			s.Nickname = userInfoMap[s.UserID].NickName
 		}
 		if data.SU == req.UserID && data.RU == req.UserID {
 			s.UserID = data.SU
-			s.Avatar = response.UserInfo[uint32(s.UserID)].Avatar
-			s.Nickname = response.UserInfo[uint32(s.UserID)].NickName
+			s.Avatar = userInfoMap[s.UserID].Avatar
+			s.Nickname = userInfoMap[s.UserID].NickName
 		}
 		s.IsOnline = onlineUserMap[s.UserID]
+		targetIDList = append(targetIDList, s.UserID)
 		list = append(list, s)
 	}
 
+	// 当前页所有会话的未读数一次查出来
+	unreadMap := chat_models.ChatUnreadCountMap(l.svcCtx.DB, req.UserID, targetIDList)
+	for i := range list {
+		list[i].Unread = unreadMap[list[i].UserID]
+	}
+
 	// 返回会话列表响应
 	return &types.ChatSessionResponse{
 		Count: count,
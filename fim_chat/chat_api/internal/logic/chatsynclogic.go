@@ -0,0 +1,309 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/common/models/ctype"
+	"fim/common/service/redis_service"
+	"fim/fim_chat/chat_models"
+	"fim/fim_group/group_models"
+
+	"fim/fim_chat/chat_api/internal/svc"
+	"fim/fim_chat/chat_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 同步的会话类型
+const (
+	syncChatType  int8 = 1 // 私聊
+	syncGroupType int8 = 2 // 群聊
+)
+
+type ChatSyncLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+type ChatSyncMsg struct {
+	ID         uint             `json:"id"`
+	Seq        int64            `json:"seq"`
+	SendUser   ctype.UserInfo   `json:"sendUser"`
+	IsMe       bool             `json:"isMe"`
+	MsgType    ctype.MsgType    `json:"msgType"`
+	MsgPreview string           `json:"msgPreview"`
+	Msg        ctype.Msg        `json:"msg"`
+	SystemMsg  *ctype.SystemMsg `json:"systemMsg"`
+	CreatedAt  string           `json:"createdAt"`
+	IsEdited   bool             `json:"isEdited"`            // 是否编辑过，客户端显示已编辑
+	EditedAt   string           `json:"editedAt,omitempty"`  // 最后一次编辑的时间
+	EditSeq    int64            `json:"editSeq,omitempty"`   // 最后一次编辑分配的序号，seq不大于客户端序号的是之前收到过的消息被编辑了，客户端按id覆盖
+	Reactions  []ctype.Reaction `json:"reactions,omitempty"` // 表情回应的汇总
+}
+type ChatSyncConversation struct {
+	Type     int8          `json:"type"`     // 会话类型 1 私聊 2 群聊
+	TargetID uint          `json:"targetID"` // 私聊是对方的用户id，群聊是群id
+	Seq      int64         `json:"seq"`      // 这次同步到的序号，客户端保存下来作为下次同步的起点
+	MaxSeq   int64         `json:"maxSeq"`   // 会话当前的最大序号
+	HasMore  bool          `json:"hasMore"`  // 还有消息没有同步完，用返回的seq继续同步
+	Reset    bool          `json:"reset"`    // 客户端的序号比服务端的还大，本地的序号已经失效，这次从头开始同步
+	List     []ChatSyncMsg `json:"list"`     // 序号或者编辑序号大于客户端序号的消息，按两者中大的从小到大，自己删除的消息不返回
+}
+type ChatSyncResponse struct {
+	List []ChatSyncConversation `json:"list"`
+}
+
+func NewChatSyncLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ChatSyncLogic {
+	return &ChatSyncLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ChatSync 离线消息同步
+// 私聊和群聊的每一条消息都有会话内连续递增的序号，客户端带上每个会话本地收到的最大序号，返回这个序号之后的消息
+// 客户端收到推送的时候，如果推送的序号不等于本地最大序号加一，说明中间漏了消息，用本地最大序号调用这个接口补齐
+// 编辑消息也会分配序号，编辑过的消息按最后一次编辑的序号再返回一次，客户端用编辑之后的内容覆盖本地的消息
+// 不传会话列表的时候，返回用户所有会话当前的最大序号，不返回消息，用于新设备登录之后建立同步的起点
+func (l *ChatSyncLogic) ChatSync(req *types.ChatSyncRequest) (resp *ChatSyncResponse, err error) {
+	if req.Limit <= 0 {
+		req.Limit = 100
+	}
+	if req.Limit > 500 {
+		req.Limit = 500
+	}
+	if len(req.List) > 200 {
+		return nil, errors.New("一次最多同步200个会话")
+	}
+
+	resp = &ChatSyncResponse{List: make([]ChatSyncConversation, 0)}
+	if len(req.List) == 0 {
+		resp.List = l.conversationList(req.UserID)
+		return resp, nil
+	}
+
+	for _, item := range req.List {
+		var conversation ChatSyncConversation
+		var ok bool
+		switch item.Type {
+		case syncChatType:
+			conversation, ok = l.syncChat(req.UserID, item, req.Limit)
+		case syncGroupType:
+			conversation, ok = l.syncGroup(req.UserID, item, req.Limit)
+		default:
+			return nil, errors.New("会话类型错误")
+		}
+		if !ok {
+			continue
+		}
+		resp.List = append(resp.List, conversation)
+	}
+
+	// 批量查询发送者的信息，优先走缓存
+	var userIDList []uint
+	for _, conversation := range resp.List {
+		for _, msg := range conversation.List {
+			userIDList = append(userIDList, msg.SendUser.ID)
+		}
+	}
+	if len(userIDList) == 0 {
+		return resp, nil
+	}
+	userInfoMap, err1 := redis_service.GetUserListInfo(l.svcCtx.Redis, l.svcCtx.UserRpc, userIDList)
+	if err1 != nil {
+		logx.Error(err1)
+		return nil, errors.New("获取用户信息失败")
+	}
+	for i := range resp.List {
+		for j := range resp.List[i].List {
+			msg := &resp.List[i].List[j]
+			userInfo := userInfoMap[msg.SendUser.ID]
+			userInfo.ID = msg.SendUser.ID
+			msg.SendUser = userInfo
+			msg.IsMe = msg.SendUser.ID == req.UserID
+		}
+	}
+	return resp, nil
+}
+
+// conversationList 用户所有会话当前的最大序号
+func (l *ChatSyncLogic) conversationList(userID uint) []ChatSyncConversation {
+	var list = make([]ChatSyncConversation, 0)
+
+	var seqList []chat_models.ChatSeqModel
+	l.svcCtx.DB.Find(&seqList, "user_id1 = ? or user_id2 = ?", userID, userID)
+	for _, model := range seqList {
+		targetID := model.UserID1
+		if targetID == userID {
+			targetID = model.UserID2
+		}
+		list = append(list, ChatSyncConversation{
+			Type:     syncChatType,
+			TargetID: targetID,
+			Seq:      model.Seq,
+			MaxSeq:   model.Seq,
+			List:     make([]ChatSyncMsg, 0),
+		})
+	}
+
+	var groupList []group_models.GroupModel
+	l.svcCtx.DB.Select("id", "msg_seq").
+		Where("id in (select group_id from group_member_models where user_id = ?)", userID).
+		Find(&groupList)
+	for _, model := range groupList {
+		list = append(list, ChatSyncConversation{
+			Type:     syncGroupType,
+			TargetID: model.ID,
+			Seq:      model.MsgSeq,
+			MaxSeq:   model.MsgSeq,
+			List:     make([]ChatSyncMsg, 0),
+		})
+	}
+	return list
+}
+
+// syncChat 同步一个私聊会话
+func (l *ChatSyncLogic) syncChat(userID uint, item types.ChatSyncItem, limit int) (conversation ChatSyncConversation, ok bool) {
+	conversationID := chat_models.ConversationID(userID, item.TargetID)
+	var maxSeq int64
+	l.svcCtx.DB.Model(&chat_models.ChatSeqModel{}).Where("conversation_id = ?", conversationID).Select("seq").Scan(&maxSeq)
+	conversation = newSyncConversation(syncChatType, item, maxSeq)
+
+	var chatList []chat_models.ChatModel
+	l.svcCtx.DB.Where("conversation_id = ? and (seq > ? or edit_seq > ?)", conversationID, conversation.Seq, conversation.Seq).
+		Order("greatest(seq, edit_seq)").Limit(limit).Find(&chatList)
+	if len(chatList) == 0 {
+		conversation.moveTo(0, false)
+		return conversation, true
+	}
+
+	var idList []uint
+	for _, model := range chatList {
+		idList = append(idList, model.ID)
+	}
+	var deleteIDList []uint
+	l.svcCtx.DB.Model(&chat_models.UserChatDeleteModel{}).
+		Where("user_id = ? and chat_id in ?", userID, idList).
+		Pluck("chat_id", &deleteIDList)
+	deleteMap := make(map[uint]bool, len(deleteIDList))
+	for _, id := range deleteIDList {
+		deleteMap[id] = true
+	}
+	reactionMap := chat_models.ChatReactionMap(l.svcCtx.DB, idList)
+
+	for _, model := range chatList {
+		if deleteMap[model.ID] {
+			continue
+		}
+		syncMsg := ChatSyncMsg{
+			ID:         model.ID,
+			Seq:        model.Seq,
+			SendUser:   ctype.UserInfo{ID: model.SendUserID},
+			MsgType:    model.MsgType,
+			MsgPreview: model.MsgPreview,
+			Msg:        model.Msg,
+			SystemMsg:  model.SystemMsg,
+			CreatedAt:  model.CreatedAt.Format("2006-01-02 15:04:05"),
+			Reactions:  reactionMap[model.ID],
+		}
+		if model.IsEdited() {
+			syncMsg.IsEdited = true
+			syncMsg.EditedAt = model.EditedAt.Format("2006-01-02 15:04:05")
+			syncMsg.EditSeq = model.EditSeq
+		}
+		conversation.List = append(conversation.List, syncMsg)
+	}
+	conversation.moveTo(chatList[len(chatList)-1].SyncSeq(), len(chatList) == limit)
+	return conversation, true
+}
+
+// syncGroup 同步一个群聊会话，不是群成员的群不返回
+func (l *ChatSyncLogic) syncGroup(userID uint, item types.ChatSyncItem, limit int) (conversation ChatSyncConversation, ok bool) {
+	var member group_models.GroupMemberModel
+	err := l.svcCtx.DB.Take(&member, "group_id = ? and user_id = ?", item.TargetID, userID).Error
+	if err != nil {
+		return conversation, false
+	}
+	var maxSeq int64
+	l.svcCtx.DB.Model(&group_models.GroupModel{}).Where("id = ?", item.TargetID).Select("msg_seq").Scan(&maxSeq)
+	conversation = newSyncConversation(syncGroupType, item, maxSeq)
+
+	var msgList []group_models.GroupMsgModel
+	l.svcCtx.DB.Where("group_id = ? and (seq > ? or edit_seq > ?)", item.TargetID, conversation.Seq, conversation.Seq).
+		Order("greatest(seq, edit_seq)").Limit(limit).Find(&msgList)
+	if len(msgList) == 0 {
+		conversation.moveTo(0, false)
+		return conversation, true
+	}
+
+	var idList []uint
+	for _, model := range msgList {
+		idList = append(idList, model.ID)
+	}
+	var deleteIDList []uint
+	l.svcCtx.DB.Model(&group_models.GroupUserMsgDeleteModel{}).
+		Where("user_id = ? and msg_id in ?", userID, idList).
+		Pluck("msg_id", &deleteIDList)
+	deleteMap := make(map[uint]bool, len(deleteIDList))
+	for _, id := range deleteIDList {
+		deleteMap[id] = true
+	}
+	reactionMap := group_models.GroupMsgReactionMap(l.svcCtx.DB, idList)
+
+	for _, model := range msgList {
+		if deleteMap[model.ID] {
+			continue
+		}
+		syncMsg := ChatSyncMsg{
+			ID:         model.ID,
+			Seq:        model.Seq,
+			SendUser:   ctype.UserInfo{ID: model.SendUserID},
+			MsgType:    model.MsgType,
+			MsgPreview: model.MsgPreview,
+			Msg:        model.Msg,
+			SystemMsg:  model.SystemMsg,
+			CreatedAt:  model.CreatedAt.Format("2006-01-02 15:04:05"),
+			Reactions:  reactionMap[model.ID],
+		}
+		if model.IsEdited() {
+			syncMsg.IsEdited = true
+			syncMsg.EditedAt = model.EditedAt.Format("2006-01-02 15:04:05")
+			syncMsg.EditSeq = model.EditSeq
+		}
+		conversation.List = append(conversation.List, syncMsg)
+	}
+	conversation.moveTo(msgList[len(msgList)-1].SyncSeq(), len(msgList) == limit)
+	return conversation, true
+}
+
+// newSyncConversation 客户端的序号比服务端的最大序号还大的时候，从头开始同步
+func newSyncConversation(syncType int8, item types.ChatSyncItem, maxSeq int64) ChatSyncConversation {
+	conversation := ChatSyncConversation{
+		Type:     syncType,
+		TargetID: item.TargetID,
+		Seq:      item.Seq,
+		MaxSeq:   maxSeq,
+		List:     make([]ChatSyncMsg, 0),
+	}
+	if conversation.Seq < 0 || conversation.Seq > maxSeq {
+		conversation.Seq = 0
+		conversation.Reset = true
+	}
+	return conversation
+}
+
+// moveTo 同步到lastSeq为止，自己删除的消息虽然不返回，同步的起点也要越过它们
+// 查询最大序号之后又有新消息入库的时候，最大序号以查到的为准
+// 没有查满的时候说明已经同步到最新，被清理掉的消息留下的空缺直接跳过
+func (c *ChatSyncConversation) moveTo(lastSeq int64, full bool) {
+	if lastSeq > c.MaxSeq {
+		c.MaxSeq = lastSeq
+	}
+	if !full {
+		c.Seq = c.MaxSeq
+		return
+	}
+	c.Seq = lastSeq
+	c.HasMore = c.Seq < c.MaxSeq
+}
@@ -1,11 +1,14 @@
 package svc
 
 import (
+	"fim/common/service/redis_service"
+	"fim/common/ws_hub"
 	"fim/common/zrpc_interceptor"
 	"fim/core"
 	"fim/fim_chat/chat_api/internal/config"
 	"fim/fim_file/file_rpc/files"
 	"fim/fim_file/file_rpc/types/file_rpc"
+	"fim/fim_user/user_models"
 	"fim/fim_user/user_rpc/types/user_rpc"
 	"fim/fim_user/user_rpc/users"
 	"github.com/go-redis/redis"
@@ -14,21 +17,25 @@ import (
 )
 
 type ServiceContext struct {
-	Config  config.Config
-	DB      *gorm.DB
-	UserRpc user_rpc.UsersClient
-	FileRpc file_rpc.FilesClient
-	Redis   *redis.Client
+	Config   config.Config
+	DB       *gorm.DB
+	UserRpc  user_rpc.UsersClient
+	FileRpc  file_rpc.FilesClient
+	Redis    *redis.Client
+	WsRouter *redis_service.WsRouter            // 用户websocket连接的路由，多个节点部署的时候用来转发推送
+	WsHub    *ws_hub.Hub[user_models.UserModel] // 这个节点上的websocket连接
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
 	mysqlDb := core.InitGorm(c.Mysql.DataSource)
 	client := core.InitRedis(c.Redis.Addr, c.Redis.Pwd, c.Redis.DB)
 	return &ServiceContext{
-		Config:  c,
-		DB:      mysqlDb,
-		UserRpc: users.NewUsers(zrpc.MustNewClient(c.UserRpc, zrpc.WithUnaryClientInterceptor(zrpc_interceptor.ClientInfoInterceptor))),
-		FileRpc: files.NewFiles(zrpc.MustNewClient(c.FileRpc, zrpc.WithUnaryClientInterceptor(zrpc_interceptor.ClientInfoInterceptor))),
-		Redis:   client,
+		Config:   c,
+		DB:       mysqlDb,
+		UserRpc:  users.NewUsers(zrpc.MustNewClient(c.UserRpc, zrpc.WithUnaryClientInterceptor(zrpc_interceptor.ClientInfoInterceptor))),
+		FileRpc:  files.NewFiles(zrpc.MustNewClient(c.FileRpc, zrpc.WithUnaryClientInterceptor(zrpc_interceptor.ClientInfoInterceptor))),
+		Redis:    client,
+		WsRouter: redis_service.NewWsRouter(client, "chat"),
+		WsHub:    ws_hub.NewHub[user_models.UserModel](c.Ws),
 	}
 }
@@ -24,6 +24,7 @@ type ChatSession struct {
 	MsgPreview string `json:"msg_preview"`
 	IsTop      bool   `json:"is_top"`
 	IsOnline   bool   `json:"is_online"`
+	Unread     int64  `json:"unread"` // 未读消息数
 }
 
 type ChatSessionRequest struct {
@@ -60,3 +61,36 @@ type UserTopRequest struct {
 	UserID   uint `header:"user_id"`
 	FriendID uint `json: "friend_id"`
 }
+
+type ChatSyncItem struct {
+	Type     int8  `json:"type"`         // 会话类型 1 私聊 2 群聊
+	TargetID uint  `json:"targetID"`     // 私聊是对方的用户id，群聊是群id
+	Seq      int64 `json:"seq,optional"` // 客户端本地这个会话已经收到的最大序号
+}
+
+type ChatSyncRequest struct {
+	UserID uint           `header:"user_id"`
+	List   []ChatSyncItem `json:"list,optional"`  // 要同步的会话，为空的时候只返回所有会话当前的最大序号
+	Limit  int            `json:"limit,optional"` // 每个会话最多返回多少条 默认100 最多500
+}
+
+type ChatSyncResponse struct {
+}
+
+type ChatReadRequest struct {
+	UserID   uint  `header:"user_id"`
+	FriendID uint  `json:"friendID"`     // 会话的对方用户id
+	Seq      int64 `json:"seq,optional"` // 已读到的消息序号，不传表示全部已读
+}
+
+type ChatReadResponse struct {
+	Seq int64 `json:"seq"` // 修改之后的已读位置
+}
+
+type ChatRevisionRequest struct {
+	UserID uint `header:"user_id"`
+	ID     uint `form:"id"` // 消息id
+}
+
+type ChatRevisionResponse struct {
+}
@@ -3,16 +3,24 @@ package chat_models
 import (
 	"fim/common/models"
 	"fim/common/models/ctype"
+	"gorm.io/gorm"
+	"time"
 )
 
 type ChatModel struct {
 	models.Model
-	SendUserID uint             `json:"sendUserID"`
-	RevUserID  uint             `json:"revUserID"`
-	MsgType    ctype.MsgType    `json:"msgType"`                   // 消息类型 1 文本类型  2 图片消息  3 视频消息 4 文件消息 5 语音消息  6 语言通话  7 视频通话  8 撤回消息 9回复消息 10 引用消息
-	MsgPreview string           `gorm:"size:64" json:"msgPreview"` // 消息预览
-	Msg        ctype.Msg        `json:"msg"`                       // 消息类容
-	SystemMsg  *ctype.SystemMsg `json:"systemMsg"`                 // 系统提示
+	SendUserID     uint             `gorm:"uniqueIndex:idx_chat_client_msg" json:"sendUserID"`
+	RevUserID      uint             `json:"revUserID"`
+	ConversationID string           `gorm:"size:32;index:idx_chat_conversation_seq" json:"conversationID"` // 会话id 两个用户id小的在前 例如 1_2
+	Seq            int64            `gorm:"index:idx_chat_conversation_seq" json:"seq"`                    // 会话内的消息序号，从1开始连续递增
+	MsgType        ctype.MsgType    `json:"msgType"`                                                       // 消息类型 1 文本类型  2 图片消息  3 视频消息 4 文件消息 5 语音消息  6 语言通话  7 视频通话  8 撤回消息 9回复消息 10 引用消息
+	MsgPreview     string           `gorm:"size:64" json:"msgPreview"`                                     // 消息预览
+	Msg            ctype.Msg        `json:"msg"`                                                           // 消息类容
+	SystemMsg      *ctype.SystemMsg `json:"systemMsg"`                                                     // 系统提示
+	ClientMsgID    *string          `gorm:"size:64;uniqueIndex:idx_chat_client_msg" json:"clientMsgID"`    // 客户端生成的消息id，同一个发送者不会重复，服务端代发的消息为空
+	EditCount      int              `json:"editCount"`                                                     // 编辑次数，大于0的时候显示已编辑
+	EditedAt       *time.Time       `json:"editedAt"`                                                      // 最后一次编辑的时间
+	EditSeq        int64            `json:"editSeq"`                                                       // 最后一次编辑分配的会话序号，没有编辑过为0
 }
 
 func (chat *ChatModel) MsgPreviewMethod() string {
@@ -31,3 +39,9 @@ func (chat *ChatModel) MsgPreviewMethod() string {
 	}
 	return chat.Msg.MsgPreview()
 }
+
+// FindClientMsg 按客户端消息id查找发送者已经入库的消息，用于重发的时候去重
+func FindClientMsg(db *gorm.DB, sendUserID uint, clientMsgID string) (chat ChatModel, ok bool) {
+	err := db.Take(&chat, "send_user_id = ? and client_msg_id = ?", sendUserID, clientMsgID).Error
+	return chat, err == nil
+}
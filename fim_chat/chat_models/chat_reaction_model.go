@@ -0,0 +1,46 @@
+package chat_models
+
+import (
+	"fim/common/models"
+	"fim/common/models/ctype"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ChatReactionModel 私聊消息的表情回应，同一个用户对同一条消息的同一个表情只有一行
+type ChatReactionModel struct {
+	models.Model
+	ChatID uint   `gorm:"uniqueIndex:idx_chat_reaction" json:"chatID"`        // 消息id
+	UserID uint   `gorm:"uniqueIndex:idx_chat_reaction" json:"userID"`        // 回应的用户id
+	Emoji  string `gorm:"size:32;uniqueIndex:idx_chat_reaction" json:"emoji"` // 表情
+}
+
+// AddChatReaction 添加表情回应，重复添加不做处理
+func AddChatReaction(db *gorm.DB, chatID, userID uint, emoji string) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&ChatReactionModel{
+		ChatID: chatID,
+		UserID: userID,
+		Emoji:  emoji,
+	}).Error
+}
+
+// RemoveChatReaction 取消表情回应
+func RemoveChatReaction(db *gorm.DB, chatID, userID uint, emoji string) error {
+	return db.Where("chat_id = ? and user_id = ? and emoji = ?", chatID, userID, emoji).
+		Delete(&ChatReactionModel{}).Error
+}
+
+// ChatReactionMap 按消息汇总表情回应
+// 返回值: 消息id和回应汇总的映射，没有回应的消息不在映射里
+func ChatReactionMap(db *gorm.DB, chatIDList []uint) map[uint][]ctype.Reaction {
+	reactionMap := map[uint][]ctype.Reaction{}
+	if len(chatIDList) == 0 {
+		return reactionMap
+	}
+	var list []ChatReactionModel
+	db.Where("chat_id in ?", chatIDList).Order("id").Find(&list)
+	for _, model := range list {
+		reactionMap[model.ChatID] = ctype.AppendReaction(reactionMap[model.ChatID], model.Emoji, model.UserID)
+	}
+	return reactionMap
+}
@@ -0,0 +1,81 @@
+package chat_models
+
+import (
+	"fim/common/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ChatReadModel 用户在私聊会话里的已读位置，每个用户每个会话一行
+type ChatReadModel struct {
+	models.Model
+	UserID         uint   `gorm:"uniqueIndex:idx_chat_read" json:"userID"`                 // 用户id
+	ConversationID string `gorm:"size:32;uniqueIndex:idx_chat_read" json:"conversationID"` // 会话id
+	ReadSeq        int64  `json:"readSeq"`                                                 // 已读到的消息序号
+}
+
+// MarkChatRead 把用户和对方的会话标记已读到seq，seq小于等于0表示全部已读
+// 已读位置只会往前移，比当前已读位置小的seq不做修改
+// 返回值:
+//
+//	readSeq: 修改之后的已读位置
+//	changed: 已读位置是否有变化，没有变化的时候不需要发送已读回执
+//	err: 修改失败时返回错误
+func MarkChatRead(db *gorm.DB, userID, targetID uint, seq int64) (readSeq int64, changed bool, err error) {
+	conversationID := ConversationID(userID, targetID)
+	var maxSeq int64
+	db.Model(&ChatSeqModel{}).Where("conversation_id = ?", conversationID).Select("seq").Scan(&maxSeq)
+	if seq <= 0 || seq > maxSeq {
+		seq = maxSeq
+	}
+
+	var old int64
+	db.Model(&ChatReadModel{}).Where("user_id = ? and conversation_id = ?", userID, conversationID).Select("read_seq").Scan(&old)
+	if seq <= old {
+		return old, false, nil
+	}
+	err = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "conversation_id"}},
+		DoUpdates: clause.Assignments(map[string]any{"read_seq": gorm.Expr("greatest(read_seq, ?)", seq)}),
+	}).Create(&ChatReadModel{
+		UserID:         userID,
+		ConversationID: conversationID,
+		ReadSeq:        seq,
+	}).Error
+	if err != nil {
+		return old, false, err
+	}
+	return seq, true, nil
+}
+
+// ChatUnreadCountMap 批量统计用户在多个会话里的未读消息数，key是对方的用户id，没有未读的不在map里
+// 已读位置按会话左连接，整个列表只查一次，统计规则和单个会话一样：对方发的、在已读位置之后的、没有被自己删除的消息
+func ChatUnreadCountMap(db *gorm.DB, userID uint, targetIDList []uint) map[uint]int64 {
+	var conversationIDList []string
+	var targetMap = map[string]uint{}
+	for _, targetID := range targetIDList {
+		conversationID := ConversationID(userID, targetID)
+		conversationIDList = append(conversationIDList, conversationID)
+		targetMap[conversationID] = targetID
+	}
+	var unreadMap = map[uint]int64{}
+	if len(conversationIDList) == 0 {
+		return unreadMap
+	}
+	type unread struct {
+		ConversationID string
+		Count          int64
+	}
+	var unreadList []unread
+	db.Model(&ChatModel{}).
+		Joins("left join chat_read_models r on r.conversation_id = chat_models.conversation_id and r.user_id = ?", userID).
+		Where("chat_models.conversation_id in ? and chat_models.seq > coalesce(r.read_seq, 0) and chat_models.send_user_id <> ?", conversationIDList, userID).
+		Where("chat_models.id not in (select chat_id from user_chat_delete_models where user_id = ?)", userID).
+		Group("chat_models.conversation_id").
+		Select("chat_models.conversation_id, count(*) as count").
+		Scan(&unreadList)
+	for _, u := range unreadList {
+		unreadMap[targetMap[u.ConversationID]] = u.Count
+	}
+	return unreadMap
+}
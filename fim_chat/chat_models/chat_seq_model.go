@@ -0,0 +1,61 @@
+package chat_models
+
+import (
+	"fim/common/models"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ChatSeqModel 私聊会话的消息序号表，每个会话一行，记录这个会话已经分配的最大序号
+type ChatSeqModel struct {
+	models.Model
+	ConversationID string `gorm:"size:32;uniqueIndex" json:"conversationID"` // 会话id
+	UserID1        uint   `gorm:"index" json:"userID1"`                      // 用户id小的一方
+	UserID2        uint   `gorm:"index" json:"userID2"`                      // 用户id大的一方
+	Seq            int64  `json:"seq"`                                       // 已经分配的最大序号
+}
+
+// ConversationID 两个用户之间的私聊会话id，用户id小的在前，例如 1_2
+func ConversationID(userID1, userID2 uint) string {
+	if userID1 > userID2 {
+		userID1, userID2 = userID2, userID1
+	}
+	return fmt.Sprintf("%d_%d", userID1, userID2)
+}
+
+// NextChatSeq 给两个用户之间的私聊会话分配下一个消息序号
+// 需要在事务里调用，会话这一行在事务提交之前一直被锁住，同一个会话的消息按序号串行入库
+func NextChatSeq(tx *gorm.DB, userID1, userID2 uint) (seq int64, err error) {
+	if userID1 > userID2 {
+		userID1, userID2 = userID2, userID1
+	}
+	conversationID := ConversationID(userID1, userID2)
+	err = tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "conversation_id"}},
+		DoUpdates: clause.Assignments(map[string]any{"seq": gorm.Expr("seq + 1")}),
+	}).Create(&ChatSeqModel{
+		ConversationID: conversationID,
+		UserID1:        userID1,
+		UserID2:        userID2,
+		Seq:            1,
+	}).Error
+	if err != nil {
+		return
+	}
+	err = tx.Model(&ChatSeqModel{}).Where("conversation_id = ?", conversationID).Select("seq").Scan(&seq).Error
+	return
+}
+
+// Create 分配会话序号并把消息入库，分配序号和入库在同一个事务里，入库失败序号也不会被占用
+func (chat *ChatModel) Create(db *gorm.DB) error {
+	chat.ConversationID = ConversationID(chat.SendUserID, chat.RevUserID)
+	return db.Transaction(func(tx *gorm.DB) error {
+		seq, err := NextChatSeq(tx, chat.SendUserID, chat.RevUserID)
+		if err != nil {
+			return err
+		}
+		chat.Seq = seq
+		return tx.Create(chat).Error
+	})
+}
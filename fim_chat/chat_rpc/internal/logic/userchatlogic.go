@@ -49,7 +49,7 @@ func (l *UserChatLogic) UserChat(in *chat_rpc.UserChatRequest) (*chat_rpc.UserCh
 		MsgType:    msg.Type,
 	}
 	chat.MsgPreview = chat.MsgPreviewMethod()
-	err = l.svcCtx.DB.Create(&chat).Error
+	err = chat.Create(l.svcCtx.DB)
 	if err != nil {
 		logx.Error(err)
 		return nil, err
@@ -3,11 +3,13 @@ package main
 import (
 	"encoding/json"
 	"fim/common/etcd"
+	"fim/utils/ips"
 	"flag"
 	"fmt"
 	"github.com/zeromicro/go-zero/core/conf"
 	"github.com/zeromicro/go-zero/core/logx"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -97,13 +99,17 @@ func auth(authAddr string, res http.ResponseWriter, req *http.Request) (ok bool)
 var configFile = flag.String("f", "settings.yaml", "the config file")
 
 type Config struct {
-	Addr string
-	Etcd string
-	Log  logx.LogConf
+	Addr           string
+	Etcd           string
+	Log            logx.LogConf
+	TrustedProxies []string `json:",optional"` // 网关前面受信任的代理，只有经过这些代理的请求才使用 X-Forwarded-For 里的客户端地址
 }
 
 var config Config
 
+// trustedProxies 解析之后的受信任代理
+var trustedProxies []*net.IPNet
+
 type Proxy struct {
 }
 
@@ -142,6 +148,8 @@ func (Proxy) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 
 	// 输出客户端地址和要代理的 URL，用于调试。
 	logx.Infof("%s %s", remoteAddr[0], proxyUr1)
+	// 把客户端的真实IP传给后面的服务，覆盖客户端自己传的值。
+	req.Header.Set("Client-IP", ips.ClientIP(req, trustedProxies))
 	// 调用认证函数，如果认证失败，返回错误响应。
 	if !auth(authUr1, res, req) {
 		return
@@ -162,6 +170,10 @@ func main() {
 	conf.MustLoad(*configFile, &config)
 	// 设置日志配置
 	logx.SetUp(config.Log)
+	// 解析受信任的代理，配置错误的时候直接退出
+	var err error
+	trustedProxies, err = ips.ParseTrustedProxies(config.TrustedProxies)
+	logx.Must(err)
 	// 输出服务启动信息
 	fmt.Printf("gateway running %s\n", config.Addr)
 
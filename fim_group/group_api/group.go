@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 
+	"fim/common/service/redis_service"
 	"fim/fim_group/group_api/internal/config"
 	"fim/fim_group/group_api/internal/handler"
 	"fim/fim_group/group_api/internal/svc"
@@ -25,6 +26,15 @@ func main() {
 
 	ctx := svc.NewServiceContext(c)
 	handler.RegisterHandlers(server, ctx)
+	// 接收其他节点转发过来的推送，并定时刷新这个节点上在线用户的路由
+	go ctx.WsRouter.Subscribe(func(userID uint, byteData []byte) {
+		handler.SendLocalMsg(ctx, userID, byteData)
+	})
+	go ctx.WsRouter.KeepAlive(ctx.WsHub.UserIDList)
+	// 订阅用户资料变更，刷新在线用户的信息并推送给相关的在线用户
+	go redis_service.SubscribeUserInfoChange(ctx.Redis, func(userID uint) {
+		handler.UserInfoChange(ctx, userID)
+	})
 
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
 	server.Start()
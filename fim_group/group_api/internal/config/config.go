@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fim/common/ws_hub"
 	"github.com/zeromicro/go-zero/rest"
 	"github.com/zeromicro/go-zero/zrpc"
 )
@@ -19,4 +20,11 @@ type Config struct {
 		Pwd  string
 		DB   int
 	}
+	Invite struct {
+		Secret      string // 邀请链接签名密钥
+		ExpireHours int    `json:",default=168"` // 邀请链接默认有效期，单位小时
+		Link        string // 邀请链接地址，带上token参数之后生成二维码
+	}
+	Ws             ws_hub.Config `json:",optional"`   // websocket连接的队列长度、心跳和读限制
+	MsgEditMinutes int           `json:",default=15"` // 消息发出之后多少分钟之内可以编辑
 }
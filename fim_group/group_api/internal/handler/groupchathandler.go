@@ -6,11 +6,14 @@ import (
 	"fim/common/models/ctype"
 	"fim/common/response"
 	"fim/common/service/redis_service"
+	"fim/common/ws_hub"
 	"fim/fim_file/file_rpc/files"
 	"fim/fim_group/group_api/internal/svc"
 	"fim/fim_group/group_api/internal/types"
 	"fim/fim_group/group_models"
+	"fim/fim_user/user_models"
 	"fim/fim_user/user_rpc/types/user_rpc"
+	"fim/utils/typings"
 	"fmt"
 	"github.com/gorilla/websocket"
 	"github.com/zeromicro/go-zero/core/logx"
@@ -22,12 +25,7 @@ import (
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
-type UserWsInfo struct {
-	UserINfo    ctype.UserInfo             //用户信息
-	WsClientMap map[string]*websocket.Conn //用户管理所有ws连接
-}
-
-var UserOnlineWsMap = map[uint]*UserWsInfo{}
+var typingTracker = typings.NewTracker(3*time.Second, 8*time.Second) //正在输入的状态，3秒之内只转发一次，8秒没有新事件自动过期
 
 type ChatRequest struct {
 	GroupID uint      `json:"group_id"` //群组id
@@ -40,6 +38,7 @@ type ChatResponse struct {
 	UserAvatar     string        `json:"userAvatar"`
 	Msg            ctype.Msg     `json:"msg"`
 	ID             uint          `json:"id"`
+	Seq            int64         `json:"seq,omitempty"` // 群内的消息序号，客户端发现和本地最大序号不连续的时候调用同步接口补齐
 	MsgType        ctype.MsgType `json:"msgType"`
 	CreatedAt      time.Time     `json:"createdAt"`
 	IsMe           bool          `json:"isMe"`
@@ -66,24 +65,13 @@ func groupChatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			response.Response(r, w, nil, err)
 			return
 		}
-		addr := conn.RemoteAddr().String()
-		logx.Infof("用户建立ws连接:%s", addr)
-		defer func() {
-			conn.Close()
-			userWsInfo, ok := UserOnlineWsMap[req.UserID]
-			if ok {
-				delete(userWsInfo.WsClientMap, addr)
-			}
-			if userWsInfo != nil && len(userWsInfo.WsClientMap) == 0 {
-				delete(UserOnlineWsMap, req.UserID)
-			}
-		}()
+		logx.Infof("用户建立ws连接:%s", conn.RemoteAddr().String())
 		baseInfoResponse, err := svcCtx.UserRpc.UserBaseInfo(context.Background(), &user_rpc.UserBaseInfoRequest{
 			UserId: uint32(req.UserID),
 		})
 		if err != nil {
 			logx.Error(err)
-			response.Response(r, w, nil, err)
+			conn.Close()
 			return
 		}
 		userInfo := ctype.UserInfo{
@@ -91,22 +79,18 @@ func groupChatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			NickName: baseInfoResponse.NickName,
 			Avatar:   baseInfoResponse.Avatar,
 		}
-		userWsInfo, ok := UserOnlineWsMap[req.UserID]
-		if !ok {
-			userWsInfo = &UserWsInfo{
-				UserINfo: userInfo,
-				WsClientMap: map[string]*websocket.Conn{
-					addr: conn,
-				},
-			}
-			UserOnlineWsMap[req.UserID] = userWsInfo
-		}
-		_, ok1 := userWsInfo.WsClientMap[addr]
-		if !ok1 {
-			UserOnlineWsMap[req.UserID].WsClientMap[addr] = conn
+		// 注册连接，之后对这个连接的写操作都通过client放进发送队列，由连接自己的写协程写出
+		client, first := svcCtx.WsHub.Register(req.UserID, userInfo, conn)
+		if first {
+			svcCtx.WsRouter.Online(req.UserID)
 		}
+		defer func() {
+			if svcCtx.WsHub.Unregister(client) {
+				svcCtx.WsRouter.Offline(req.UserID)
+			}
+		}()
 		for {
-			_, p, err1 := conn.ReadMessage()
+			p, err1 := client.ReadMessage()
 			if err1 != nil {
 				fmt.Println(err1)
 				break
@@ -115,33 +99,44 @@ func groupChatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			err = json.Unmarshal(p, &request)
 			if err != nil {
 				logx.Error(err)
-				SendTipErrMsg(conn, "参数解析失败")
+				SendTipErrMsg(client, "参数解析失败")
 				continue
 			}
 			msgValidateErr := request.Msg.Validate()
 			if msgValidateErr != nil {
-				SendTipErrMsg(conn, msgValidateErr.Error())
+				SendTipErrMsg(client, msgValidateErr.Error())
 				continue
 			}
+			// 自动回复只能由服务端发送
+			if request.Msg.TextMsg != nil {
+				request.Msg.TextMsg.AutoReply = false
+			}
 			var member group_models.GroupMemberModel
 			err = svcCtx.DB.Preload("GroupModel").Take(&member, "group_id = ? and user_id=?", request.GroupID, req.UserID).Error
 			if err != nil {
-				SendTipErrMsg(conn, "群组不存在")
+				SendTipErrMsg(client, "群组不存在")
 				continue
 			}
 			if member.GroupModel.IsProhibition && member.Role == 3 {
-				SendTipErrMsg(conn, "群被禁言")
+				SendTipErrMsg(client, "群被禁言")
 				continue
 			}
 			if member.GetProhibitionTime(svcCtx.Redis, svcCtx.DB) != nil {
-				SendTipErrMsg(conn, "您已被禁言")
+				SendTipErrMsg(client, "您已被禁言")
+				continue
+			}
+			// 正在输入的事件只转发给在线的群成员，不入库
+			if request.Msg.Type == ctype.TypingMsgType {
+				if request.Msg.TypingMsg != nil {
+					relayGroupTyping(svcCtx, member, request.Msg.TypingMsg.Typing)
+				}
 				continue
 			}
 			switch request.Msg.Type {
 			case ctype.FileMsgType:
 				nameList := strings.Split(request.Msg.FileMsg.Src, "/")
 				if len(nameList) == 0 {
-					SendTipErrMsg(conn, "请上传文件")
+					SendTipErrMsg(client, "请上传文件")
 					continue
 				}
 				fileID := nameList[len(nameList)-1]
@@ -150,7 +145,7 @@ func groupChatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 				})
 				if err3 != nil {
 					logx.Error(err3)
-					SendTipErrMsg(conn, err3.Error())
+					SendTipErrMsg(client, err3.Error())
 					continue
 				}
 				request.Msg.FileMsg.Title = fileResponse.FileName
@@ -159,31 +154,31 @@ func groupChatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			case ctype.WithdrawMsgType:
 				withdrawMsg := request.Msg.WithdrawMsg
 				if withdrawMsg == nil {
-					SendTipErrMsg(conn, "撤回消息的格式错误")
+					SendTipErrMsg(client, "撤回消息的格式错误")
 					continue
 				}
 				if withdrawMsg.MsgID == 0 {
-					SendTipErrMsg(conn, "撤回消息id错误")
+					SendTipErrMsg(client, "撤回消息id错误")
 					continue
 				}
 				var groupMsg group_models.GroupMsgModel
 				err = svcCtx.DB.Take(&groupMsg, "group_id=? and id =?", request.GroupID, withdrawMsg.MsgID).Error
 				if err != nil {
-					SendTipErrMsg(conn, "撤回消息不存在")
+					SendTipErrMsg(client, "撤回消息不存在")
 					continue
 				}
 				if groupMsg.MsgType == ctype.WithdrawMsgType {
-					SendTipErrMsg(conn, "消息已被撤回")
+					SendTipErrMsg(client, "消息已被撤回")
 					continue
 				}
 				if member.Role == 3 {
 					if req.UserID != groupMsg.SendUserID {
-						SendTipErrMsg(conn, "您没有权限撤回此消息")
+						SendTipErrMsg(client, "您没有权限撤回此消息")
 						continue
 					}
 					now := time.Now()
 					if now.Sub(groupMsg.CreatedAt) > 2*time.Minute {
-						SendTipErrMsg(conn, "消息已超过2分钟，无法撤回")
+						SendTipErrMsg(client, "消息已超过2分钟，无法撤回")
 						continue
 					}
 				}
@@ -193,7 +188,7 @@ func groupChatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 					Select("role").Scan(&msgUserRole).Error
 				if member.Role == 2 {
 					if msgUserRole == 1 || (msgUserRole == 2 && groupMsg.SendUserID != req.UserID) {
-						SendTipErrMsg(conn, "您没有权限撤回此消息")
+						SendTipErrMsg(client, "您没有权限撤回此消息")
 						continue
 					}
 				}
@@ -213,25 +208,97 @@ func groupChatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 						},
 					},
 				})
+			case ctype.EditMsgType:
+				editMsg := request.Msg.EditMsg
+				var groupMsg group_models.GroupMsgModel
+				err = svcCtx.DB.Take(&groupMsg, "group_id=? and id =?", request.GroupID, editMsg.MsgID).Error
+				if err != nil {
+					SendTipErrMsg(client, "编辑消息不存在")
+					continue
+				}
+				if groupMsg.SendUserID != req.UserID {
+					SendTipErrMsg(client, "只能编辑自己发的消息")
+					continue
+				}
+				if groupMsg.MsgType == ctype.WithdrawMsgType {
+					SendTipErrMsg(client, "消息已被撤回")
+					continue
+				}
+				if groupMsg.SystemMsg != nil {
+					SendTipErrMsg(client, "消息已被系统拦截")
+					continue
+				}
+				if groupMsg.MsgType != editMsg.Msg.Type {
+					SendTipErrMsg(client, "不能修改消息的类型")
+					continue
+				}
+				if time.Since(groupMsg.CreatedAt) >= time.Duration(svcCtx.Config.MsgEditMinutes)*time.Minute {
+					SendTipErrMsg(client, fmt.Sprintf("消息已超过%d分钟，无法编辑", svcCtx.Config.MsgEditMinutes))
+					continue
+				}
+				if editMsg.Msg.TextMsg != nil {
+					editMsg.Msg.TextMsg.AutoReply = false
+				}
+				// 内容没有变化的编辑不再记录版本，也不再推送，重发的编辑直接忽略
+				if groupMsg.Msg.Equal(*editMsg.Msg) {
+					continue
+				}
+				err = groupMsg.Edit(svcCtx.DB, *editMsg.Msg)
+				if err != nil {
+					logx.Error(err)
+					SendTipErrMsg(client, err.Error())
+					continue
+				}
+				editMsg.Revision = groupMsg.EditCount
+				editMsg.EditedAt = *groupMsg.EditedAt
+				editMsg.IsLatest = groupMsg.IsLatest(svcCtx.DB)
+				editMsg.Seq = groupMsg.EditSeq
+			case ctype.ReactionMsgType:
+				reactionMsg := request.Msg.ReactionMsg
+				var groupMsg group_models.GroupMsgModel
+				err = svcCtx.DB.Take(&groupMsg, "group_id=? and id =?", request.GroupID, reactionMsg.MsgID).Error
+				if err != nil {
+					SendTipErrMsg(client, "回应的消息不存在")
+					continue
+				}
+				if groupMsg.MsgType == ctype.WithdrawMsgType {
+					SendTipErrMsg(client, "消息已被撤回")
+					continue
+				}
+				if reactionMsg.IsRemove {
+					err = group_models.RemoveGroupMsgReaction(svcCtx.DB, groupMsg.ID, req.UserID, reactionMsg.Emoji)
+				} else {
+					err = group_models.AddGroupMsgReaction(svcCtx.DB, groupMsg.ID, req.UserID, reactionMsg.Emoji)
+				}
+				if err != nil {
+					logx.Error(err)
+					SendTipErrMsg(client, "表情回应失败")
+					continue
+				}
+				reactionMsg.UserID = req.UserID
+				reactionMsg.Reactions = group_models.GroupMsgReactionMap(svcCtx.DB, []uint{groupMsg.ID})[groupMsg.ID]
+				if reactionMsg.Reactions == nil {
+					reactionMsg.Reactions = make([]ctype.Reaction, 0)
+				}
 			case ctype.ReplyMsgType:
 				if request.Msg.ReplyMsg == nil || request.Msg.ReplyMsg.MsgID == 0 {
-					SendTipErrMsg(conn, "回复消息的id必填")
+					SendTipErrMsg(client, "回复消息的id必填")
 					continue
 				}
 				var msgModel group_models.GroupMsgModel
 				err = svcCtx.DB.Take(&msgModel, "group_id=? and id=?", request.GroupID, request.Msg.ReplyMsg.MsgID).Error
 				if err != nil {
-					SendTipErrMsg(conn, "回复消息不存在")
+					SendTipErrMsg(client, "回复消息不存在")
 					continue
 				}
 				if msgModel.MsgType == ctype.WithdrawMsgType {
-					SendTipErrMsg(conn, "消息已被撤回")
+					SendTipErrMsg(client, "消息已被撤回")
 					continue
 				}
 				userBaseInfo, err5 := redis_service.GetUserBaseInfo(svcCtx.Redis, svcCtx.UserRpc, msgModel.SendUserID)
 				if err5 != nil {
 					logx.Error(err5)
-					SendTipErrMsg(conn, err5.Error())
+					SendTipErrMsg(client, err5.Error())
 					continue
 				}
 				request.Msg.ReplyMsg.Msg = &msgModel.Msg
@@ -241,23 +308,23 @@ func groupChatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 				request.Msg.ReplyMsg.ReplyMsgPreview = msgModel.MsgPreview
 			case ctype.QuoteMsgType:
 				if request.Msg.QuoteMsg == nil || request.Msg.QuoteMsg.MsgID == 0 {
-					SendTipErrMsg(conn, "引用消息的id必填")
+					SendTipErrMsg(client, "引用消息的id必填")
 					continue
 				}
 				var msgModel group_models.GroupMsgModel
 				err = svcCtx.DB.Take(&msgModel, "group_id=? and id =? ", request.GroupID, request.Msg.QuoteMsg.MsgID).Error
 				if err != nil {
-					SendTipErrMsg(conn, "引用消息不存在")
+					SendTipErrMsg(client, "引用消息不存在")
 					continue
 				}
 				if msgModel.MsgType == ctype.WithdrawMsgType {
-					SendTipErrMsg(conn, "消息已被撤回")
+					SendTipErrMsg(client, "消息已被撤回")
 					continue
 				}
 				userBaseInfo, err5 := redis_service.GetUserBaseInfo(svcCtx.Redis, svcCtx.UserRpc, msgModel.SendUserID)
 				if err5 != nil {
 					logx.Error(err5)
-					SendTipErrMsg(conn, err5.Error())
+					SendTipErrMsg(client, err5.Error())
 					continue
 				}
 				request.Msg.QuoteMsg.Msg = &msgModel.Msg
@@ -266,22 +333,30 @@ func groupChatHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 				request.Msg.QuoteMsg.OriginMsgDate = msgModel.CreatedAt
 				request.Msg.QuoteMsg.QuoteMsgPreview = msgModel.MsgPreviewMethod()
 			}
-			msgID := insertMsg(svcCtx.DB, conn, member, request.Msg)
+			msgID, seq := insertMsg(svcCtx.DB, client, member, request.Msg)
+			typingTracker.Stop(fmt.Sprintf("%d_%d", member.GroupID, member.UserID))
 			SendGroupOnlineUserMsg(
-				svcCtx.DB,
+				svcCtx,
 				member,
 				request.Msg,
 				msgID,
+				seq,
 			)
 		}
 	}
 }
 
-func insertMsg(db *gorm.DB, conn *websocket.Conn, member group_models.GroupMemberModel, msg ctype.Msg) uint {
+func insertMsg(db *gorm.DB, client *ws_hub.Client, member group_models.GroupMemberModel, msg ctype.Msg) (uint, int64) {
 	switch msg.Type {
 	case ctype.WithdrawMsgType:
 		fmt.Println("撤回消息自己是不入库的")
-		return 0
+		return 0, 0
+	case ctype.EditMsgType:
+		// 编辑消息修改的是原消息，自己不入库，推送里的序号用编辑分配的序号
+		return 0, msg.EditMsg.Seq
+	case ctype.ReactionMsgType:
+		// 表情回应修改的是原消息，自己不入库
+		return 0, 0
 	}
 	groupMsg := group_models.GroupMsgModel{
 		GroupID:       member.GroupID,
@@ -291,55 +366,117 @@ func insertMsg(db *gorm.DB, conn *websocket.Conn, member group_models.GroupMembe
 		Msg:           msg,
 	}
 	groupMsg.MsgPreview = groupMsg.MsgPreviewMethod()
-	err := db.Create(&groupMsg).Error
+	err := groupMsg.Create(db)
 	if err != nil {
 		logx.Error(err)
-		SendTipErrMsg(conn, "消息入库失败")
-		return 0
+		SendTipErrMsg(client, "消息入库失败")
+		return 0, 0
 	}
-	return groupMsg.ID
+	return groupMsg.ID, groupMsg.Seq
 }
-func SendGroupOnlineUserMsg(db *gorm.DB, member group_models.GroupMemberModel, msg ctype.Msg, msgID uint) {
-	userOnlineIDList := getOnlineUserIDList()
-	var groupMemberOnlineIDList []uint
-	db.Model(group_models.GroupMemberModel{}).Where("group_id=?and user_id in ?", member.GroupID, userOnlineIDList).Select("user_id").Scan(&groupMemberOnlineIDList)
+func SendGroupOnlineUserMsg(svcCtx *svc.ServiceContext, member group_models.GroupMemberModel, msg ctype.Msg, msgID uint, seq int64) {
 	var chatResponse = ChatResponse{
 		GroupID:        member.GroupID,
 		UserID:         member.UserID,
 		Msg:            msg,
 		ID:             msgID,
+		Seq:            seq,
 		MsgType:        msg.Type,
 		CreatedAt:      time.Now(),
 		MemberNickname: member.MemberNickname,
 		MsgPreview:     msg.MsgPreview(),
 	}
-	wsInfo, ok := UserOnlineWsMap[member.UserID]
-	if ok {
-		chatResponse.UserNickname = wsInfo.UserINfo.NickName
-		chatResponse.UserAvatar = wsInfo.UserINfo.Avatar
-	}
-	for _, u := range groupMemberOnlineIDList {
-		wsUserInfo, ok2 := UserOnlineWsMap[u]
-		if !ok2 {
+	userInfo := getUserInfo(svcCtx, member.UserID)
+	chatResponse.UserNickname = userInfo.NickName
+	chatResponse.UserAvatar = userInfo.Avatar
+
+	chatResponse.IsMe = true
+	selfData, _ := json.Marshal(chatResponse)
+	chatResponse.IsMe = false
+	otherData, _ := json.Marshal(chatResponse)
+	sendGroupMsg(svcCtx, member, selfData, otherData)
+}
+
+// sendGroupMsg 把推送发给群里的所有成员，这个节点上的连接直接发送，其他节点上的连接通过路由转发
+// selfData 是发给成员自己的其他设备的内容，为nil的时候不发给自己
+func sendGroupMsg(svcCtx *svc.ServiceContext, member group_models.GroupMemberModel, selfData, otherData []byte) {
+	var memberIDList []uint
+	svcCtx.DB.Model(group_models.GroupMemberModel{}).Where("group_id = ?", member.GroupID).Select("user_id").Scan(&memberIDList)
+	var otherIDList []uint
+	for _, u := range memberIDList {
+		if u == member.UserID {
 			continue
 		}
-		chatResponse.IsMe = false
-		if wsUserInfo.UserINfo.ID == member.UserID {
-			chatResponse.IsMe = true
-		}
-		byteData, _ := json.Marshal(chatResponse)
-		for _, w2 := range wsUserInfo.WsClientMap {
-			w2.WriteMessage(websocket.TextMessage, byteData)
+		svcCtx.WsHub.SendToUser(u, otherData)
+		otherIDList = append(otherIDList, u)
+	}
+	svcCtx.WsRouter.Forward(otherIDList, otherData)
+	if selfData != nil {
+		svcCtx.WsHub.SendToUser(member.UserID, selfData)
+		svcCtx.WsRouter.Forward([]uint{member.UserID}, selfData)
+	}
+}
+
+// SendLocalMsg 把推送发给这个节点上该用户的所有连接，用户不在这个节点上的时候不做处理
+func SendLocalMsg(svcCtx *svc.ServiceContext, userID uint, byteData []byte) {
+	svcCtx.WsHub.SendToUser(userID, byteData)
+}
+
+// getUserInfo 获取用户的昵称和头像，用户在这个节点上在线的时候直接取连接里的信息，否则走缓存
+func getUserInfo(svcCtx *svc.ServiceContext, userID uint) ctype.UserInfo {
+	if userInfo, ok := svcCtx.WsHub.Info(userID); ok {
+		return userInfo
+	}
+	userInfo, err := redis_service.GetUserBaseInfo(svcCtx.Redis, svcCtx.UserRpc, userID)
+	if err != nil {
+		logx.Error(err)
+	}
+	userInfo.ID = userID
+	return userInfo
+}
+
+// relayGroupTyping 转发群成员正在输入的状态
+// 同一个成员限制转发的频率，没有收到停止输入的时候自动按停止输入转发
+func relayGroupTyping(svcCtx *svc.ServiceContext, member group_models.GroupMemberModel, typing bool) {
+	key := fmt.Sprintf("%d_%d", member.GroupID, member.UserID)
+	if typing {
+		if !typingTracker.Start(key, func() {
+			sendGroupTypingMsg(svcCtx, member, false)
+		}) {
+			return
 		}
+	} else if !typingTracker.Stop(key) {
+		return
 	}
+	sendGroupTypingMsg(svcCtx, member, typing)
 }
-func getOnlineUserIDList() (userOnlineIDList []uint) {
-	for u, _ := range UserOnlineWsMap {
-		userOnlineIDList = append(userOnlineIDList, u)
+
+// sendGroupTypingMsg 把正在输入的状态推送给其他群成员
+func sendGroupTypingMsg(svcCtx *svc.ServiceContext, member group_models.GroupMemberModel, typing bool) {
+	var chatResponse = ChatResponse{
+		GroupID: member.GroupID,
+		UserID:  member.UserID,
+		Msg: ctype.Msg{
+			Type: ctype.TypingMsgType,
+			TypingMsg: &ctype.TypingMsg{
+				UserID:  member.UserID,
+				GroupID: member.GroupID,
+				Typing:  typing,
+				Expire:  int(typingTracker.Expire() / time.Second),
+			},
+		},
+		MsgType:        ctype.TypingMsgType,
+		CreatedAt:      time.Now(),
+		MemberNickname: member.MemberNickname,
 	}
-	return
+	userInfo := getUserInfo(svcCtx, member.UserID)
+	chatResponse.UserNickname = userInfo.NickName
+	chatResponse.UserAvatar = userInfo.Avatar
+	byteData, _ := json.Marshal(chatResponse)
+	sendGroupMsg(svcCtx, member, nil, byteData)
 }
-func SendTipErrMsg(conn *websocket.Conn, msg string) {
+
+func SendTipErrMsg(client *ws_hub.Client, msg string) {
 	resp := ChatResponse{
 		Msg: ctype.Msg{
 			Type: ctype.TipMsgType,
@@ -350,6 +487,66 @@ func SendTipErrMsg(conn *websocket.Conn, msg string) {
 		},
 		CreatedAt: time.Now(),
 	}
-	byteData, _ := json.Marshal(resp)
-	conn.WriteMessage(websocket.TextMessage, byteData)
+	client.SendJSON(resp)
+}
+
+// UserInfoChange 处理用户资料变更
+// 刷新这个节点上该用户连接里保存的用户信息，并把新的昵称和头像推送给同群的在线成员
+func UserInfoChange(svcCtx *svc.ServiceContext, userID uint) {
+	// 账号被封禁、停用或者注销之后所有的登录都已经失效，断开这个节点上该用户的连接
+	res, err := svcCtx.UserRpc.UserInfo(context.Background(), &user_rpc.UserInfoRequest{
+		UserId: uint32(userID),
+	})
+	if err != nil {
+		logx.Error(err)
+		return
+	}
+	var userModel user_models.UserModel
+	err = json.Unmarshal(res.Data, &userModel)
+	if err != nil {
+		logx.Error(err)
+		return
+	}
+	if !userModel.IsActive() {
+		svcCtx.WsHub.CloseUser(userID)
+		return
+	}
+	userInfo, err := redis_service.GetUserBaseInfo(svcCtx.Redis, svcCtx.UserRpc, userID)
+	if err != nil {
+		logx.Error(err)
+		return
+	}
+	svcCtx.WsHub.SetInfo(userID, userInfo)
+
+	userOnlineIDList := svcCtx.WsHub.UserIDList()
+	if len(userOnlineIDList) == 0 {
+		return
+	}
+	var memberOnlineIDList []uint
+	svcCtx.DB.Model(group_models.GroupMemberModel{}).
+		Where("group_id in (?) and user_id in ?",
+			svcCtx.DB.Model(group_models.GroupMemberModel{}).Where("user_id = ?", userID).Select("group_id"),
+			userOnlineIDList).
+		Distinct("user_id").Scan(&memberOnlineIDList)
+
+	chatResponse := ChatResponse{
+		UserID:       userID,
+		UserNickname: userInfo.NickName,
+		UserAvatar:   userInfo.Avatar,
+		Msg: ctype.Msg{
+			Type: ctype.UserInfoChangeMsgType,
+			UserInfoChangeMsg: &ctype.UserInfoChangeMsg{
+				UserID:   userID,
+				NickName: userInfo.NickName,
+				Avatar:   userInfo.Avatar,
+			},
+		},
+		MsgType:   ctype.UserInfoChangeMsgType,
+		CreatedAt: time.Now(),
+	}
+	for _, u := range memberOnlineIDList {
+		chatResponse.IsMe = u == userID
+		byteData, _ := json.Marshal(chatResponse)
+		svcCtx.WsHub.SendToUser(u, byteData)
+	}
 }
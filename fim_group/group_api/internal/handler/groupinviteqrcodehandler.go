@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"fim/common/response"
+	"fim/fim_group/group_api/internal/logic"
+	"fim/fim_group/group_api/internal/svc"
+	"fim/fim_group/group_api/internal/types"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// groupInviteQrcodeHandler 加群邀请链接二维码，直接返回png图片
+func groupInviteQrcodeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GroupInviteQrcodeRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			response.Response(r, w, nil, err)
+			return
+		}
+
+		l := logic.NewGroupInviteQrcodeLogic(r.Context(), svcCtx)
+		byteData, err := l.GroupInviteQrcode(&req)
+		if err != nil {
+			response.Response(r, w, nil, err)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(byteData)
+	}
+}
@@ -47,6 +47,26 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/group/history/:id",
 				Handler: groupHistoryDeleteHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/group/invite",
+				Handler: groupInviteHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/group/invite/accept",
+				Handler: groupInviteAcceptHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/group/invite/info",
+				Handler: groupInviteInfoHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/group/invite/qrcode",
+				Handler: groupInviteQrcodeHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/api/group/member",
@@ -82,6 +102,11 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/group/prohibition",
 				Handler: groupProhibitionUpdateHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/group/revision",
+				Handler: groupRevisionHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/api/group/search",
@@ -6,8 +6,8 @@ import (
 	"fim/common/list_query"
 	"fim/common/models"
 	"fim/common/models/ctype"
+	"fim/common/service/redis_service"
 	"fim/fim_group/group_models"
-	"fim/fim_user/user_rpc/types/user_rpc"
 	"fim/utils"
 	"time"
 
@@ -32,18 +32,22 @@ func NewGroupHistoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Grou
 }
 
 type HistoryResponse struct {
-	GroupID        uint          `json:"group_id"`
-	UserID         uint          `json:"user_id"`
-	UserNickname   string        `json:"user_nickname"`
-	UserAvatar     string        `json:"user_avatar"`
-	Msg            ctype.Msg     `json:"msg"`
-	MsgPreview     string        `json:"msg_preview"`
-	ID             uint          `json:"id"`
-	MsgType        ctype.MsgType `json:"msg_type"`
-	CreatedAt      string        `json:"created_at"`
-	IsMe           bool          `json:"is_me"`
-	MemberNickname string        `json:"member_nickname"`
-	ShowDate       bool          `json:"show_date"`
+	GroupID        uint             `json:"group_id"`
+	UserID         uint             `json:"user_id"`
+	UserNickname   string           `json:"user_nickname"`
+	UserAvatar     string           `json:"user_avatar"`
+	Msg            ctype.Msg        `json:"msg"`
+	MsgPreview     string           `json:"msg_preview"`
+	ID             uint             `json:"id"`
+	Seq            int64            `json:"seq"`
+	MsgType        ctype.MsgType    `json:"msg_type"`
+	CreatedAt      string           `json:"created_at"`
+	IsMe           bool             `json:"is_me"`
+	MemberNickname string           `json:"member_nickname"`
+	ShowDate       bool             `json:"show_date"`
+	IsEdited       bool             `json:"is_edited"`           // 是否编辑过，客户端显示已编辑
+	EditedAt       string           `json:"edited_at,omitempty"` // 最后一次编辑的时间
+	Reactions      []ctype.Reaction `json:"reactions,omitempty"` // 表情回应的汇总
 }
 type HistoryListResponse struct {
 	List  []HistoryResponse `json:"list"`
@@ -90,16 +94,21 @@ func (l *GroupHistoryLogic) GroupHistory(req *types.GroupHistoryRequest) (resp *
 	}
 
 	// 提取发送用户ID列表
-	var userIDList []uint32
+	var userIDList []uint
+	var groupMsgIDList []uint
 	for _, model := range groupMsgList {
-		userIDList = append(userIDList, uint32(model.SendUserID))
+		userIDList = append(userIDList, model.SendUserID)
+		groupMsgIDList = append(groupMsgIDList, model.ID)
 	}
-	userIDList = utils.DeduplicationList(userIDList)
 
-	// 批量查询用户信息
-	userListResponse, err1 := l.svcCtx.UserRpc.UserListInfo(l.ctx, &user_rpc.UserListInfoRequest{
-		UserIdList: userIDList,
-	})
+	// 批量查询消息的表情回应
+	reactionMap := group_models.GroupMsgReactionMap(l.svcCtx.DB, groupMsgIDList)
+
+	// 批量查询用户信息，优先走缓存
+	userInfoMap, err1 := redis_service.GetUserListInfo(l.svcCtx.Redis, l.svcCtx.UserRpc, userIDList)
+	if err1 != nil {
+		logx.Error(err1)
+	}
 
 	// 反转消息列表，使时间顺序从旧到新
 	utils.ReverseAny(groupMsgList)
@@ -112,8 +121,14 @@ func (l *GroupHistoryLogic) GroupHistory(req *types.GroupHistoryRequest) (resp *
 			UserID:    model.SendUserID,
 			Msg:       model.Msg,
 			ID:        model.ID,
+			Seq:       model.Seq,
 			MsgType:   model.MsgType,
 			CreatedAt: model.CreatedAt.Format("2006-01-02 15:04:05"),
+			Reactions: reactionMap[model.ID],
+		}
+		if model.IsEdited() {
+			info.IsEdited = true
+			info.EditedAt = model.EditedAt.Format("2006-01-02 15:04:05")
 		}
 		// 标记是否显示日期，第一条消息或与前一条消息时间间隔超过1小时
 		if index == 0 {
@@ -129,10 +144,8 @@ func (l *GroupHistoryLogic) GroupHistory(req *types.GroupHistoryRequest) (resp *
 			info.MemberNickname = model.GroupMemberModel.MemberNickname
 		}
 		// 设置用户昵称和头像
-		if err1 == nil {
-			info.UserNickname = userListResponse.UserInfo[uint32(info.UserID)].NickName
-			info.UserAvatar = userListResponse.UserInfo[uint32(info.UserID)].Avatar
-		}
+		info.UserNickname = userInfoMap[info.UserID].NickName
+		info.UserAvatar = userInfoMap[info.UserID].Avatar
 		// 标记是否为本人发送
 		if req.UserID == info.UserID {
 			info.IsMe = true
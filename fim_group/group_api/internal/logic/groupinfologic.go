@@ -3,6 +3,7 @@ package logic
 import (
 	"context"
 	"errors"
+	"fim/common/service/redis_service"
 	"fim/fim_group/group_api/internal/svc"
 	"fim/fim_group/group_api/internal/types"
 	"fim/fim_group/group_models"
@@ -62,20 +63,18 @@ func (l *GroupInfoLogic) GroupInfo(req *types.GroupInfoRequest) (resp *types.Gro
 	}
 
 	// 初始化列表，用于存储群组中的用户ID
-	var userIDList []uint32
+	var userIDList []uint
 	var userAllIDList []uint32
 	// 遍历群组成员列表，筛选出管理员和普通成员的用户ID
 	for _, model := range groupModel.MemberList {
 		if model.Role == 1 || model.Role == 2 {
-			userIDList = append(userIDList, uint32(model.UserID))
+			userIDList = append(userIDList, model.UserID)
 		}
 		userAllIDList = append(userAllIDList, uint32(model.UserID))
 	}
 
-	// 调用用户RPC服务，获取用户详细信息
-	userListResponse, err := l.svcCtx.UserRpc.UserListInfo(l.ctx, &user_rpc.UserListInfoRequest{
-		UserIdList: userIDList,
-	})
+	// 批量获取群主和管理员的用户信息，优先走缓存
+	userInfoMap, err := redis_service.GetUserListInfo(l.svcCtx.Redis, l.svcCtx.UserRpc, userIDList)
 	if err != nil {
 		return
 	}
@@ -99,8 +98,8 @@ func (l *GroupInfoLogic) GroupInfo(req *types.GroupInfoRequest) (resp *types.Gro
 		}
 		userInfo := types.UserInfo{
 			UserID:   model.UserID,
-			Avatar:   userListResponse.UserInfo[uint32(model.UserID)].Avatar,
-			Nickname: userListResponse.UserInfo[uint32(model.UserID)].NickName,
+			Avatar:   userInfoMap[model.UserID].Avatar,
+			Nickname: userInfoMap[model.UserID].NickName,
 		}
 		if model.Role == 1 {
 			creator = userInfo
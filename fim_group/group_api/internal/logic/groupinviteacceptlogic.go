@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"context"
+	"fim/fim_group/group_api/internal/svc"
+	"fim/fim_group/group_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GroupInviteAcceptLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGroupInviteAcceptLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GroupInviteAcceptLogic {
+	return &GroupInviteAcceptLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GroupInviteAccept 通过邀请链接加群。
+// 依然按照群聊的验证设置走加群流程，需要验证的时候生成加群验证，等待群主或管理员处理。
+func (l *GroupInviteAcceptLogic) GroupInviteAccept(req *types.GroupInviteAcceptRequest) (resp *types.GroupInviteAcceptResponse, err error) {
+	invite, group, err := parseGroupInvite(l.svcCtx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先占用一次使用次数，加群失败再归还，避免并发的时候超过上限
+	err = invite.Use(l.svcCtx.DB)
+	if err != nil {
+		return nil, err
+	}
+	_, err = NewGroupValidAddLogic(l.ctx, l.svcCtx).GroupValidAdd(&types.GroupValidAddRequest{
+		UserID:               req.UserID,
+		GroupID:              group.ID,
+		Verify:               req.Verify,
+		VerificationQuestion: req.VerificationQuestion,
+	})
+	if err != nil {
+		invite.Release(l.svcCtx.DB)
+		return nil, err
+	}
+	return &types.GroupInviteAcceptResponse{}, nil
+}
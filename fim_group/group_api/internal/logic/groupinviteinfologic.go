@@ -0,0 +1,69 @@
+package logic
+
+import (
+	"context"
+	"fim/common/service/redis_service"
+	"fim/fim_group/group_api/internal/svc"
+	"fim/fim_group/group_api/internal/types"
+	"fim/fim_group/group_models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GroupInviteInfoLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGroupInviteInfoLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GroupInviteInfoLogic {
+	return &GroupInviteInfoLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GroupInviteInfo 解析加群邀请链接，返回群聊的预览信息和加群验证方式。
+func (l *GroupInviteInfoLogic) GroupInviteInfo(req *types.GroupInviteInfoRequest) (resp *types.GroupInviteInfoResponse, err error) {
+	invite, group, err := parseGroupInvite(l.svcCtx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	var memberCount int64
+	l.svcCtx.DB.Model(group_models.GroupMemberModel{}).Where("group_id = ?", group.ID).Count(&memberCount)
+	var isMember int64
+	l.svcCtx.DB.Model(group_models.GroupMemberModel{}).Where("group_id = ? and user_id = ?", group.ID, req.UserID).Count(&isMember)
+
+	resp = &types.GroupInviteInfoResponse{
+		GroupID:      group.ID,
+		Title:        group.Title,
+		Abstract:     group.Abstract,
+		Avatar:       group.Avatar,
+		MemberCount:  int(memberCount),
+		Verification: group.Verification,
+		IsMember:     isMember > 0,
+		ExpireAt:     invite.ExpireAt.Format("2006-01-02 15:04:05"),
+		RemainUse:    invite.RemainUse(),
+	}
+	// 邀请人信息
+	userInfo, err := redis_service.GetUserBaseInfo(l.svcCtx.Redis, l.svcCtx.UserRpc, invite.CreatorID)
+	if err != nil {
+		logx.Error(err)
+	}
+	resp.Inviter = types.UserInfo{
+		UserID:   invite.CreatorID,
+		Avatar:   userInfo.Avatar,
+		Nickname: userInfo.NickName,
+	}
+	// 只返回问题，不返回答案
+	if (group.Verification == 3 || group.Verification == 4) && group.VerificationQuestion != nil {
+		resp.VerificationQuestion = &types.VerificationQuestion{
+			Problem1: group.VerificationQuestion.Problem1,
+			Problem2: group.VerificationQuestion.Problem2,
+			Problem3: group.VerificationQuestion.Problem3,
+		}
+	}
+	return resp, nil
+}
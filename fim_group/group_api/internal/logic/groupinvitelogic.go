@@ -0,0 +1,113 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/fim_group/group_api/internal/svc"
+	"fim/fim_group/group_api/internal/types"
+	"fim/fim_group/group_models"
+	"fim/fim_user/user_models"
+	"fim/utils/invites"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GroupInviteLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGroupInviteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GroupInviteLogic {
+	return &GroupInviteLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GroupInvite 创建加群邀请链接。
+// 群成员才能创建，群聊关闭了邀请的时候只有群主和管理员可以创建。
+func (l *GroupInviteLogic) GroupInvite(req *types.GroupInviteRequest) (resp *types.GroupInviteResponse, err error) {
+	if req.Hours < 0 || req.MaxUse < 0 {
+		return nil, errors.New("参数错误")
+	}
+	hours := req.Hours
+	if hours == 0 {
+		hours = l.svcCtx.Config.Invite.ExpireHours
+	}
+
+	// 检查用户是否在群里
+	var member group_models.GroupMemberModel
+	err = l.svcCtx.DB.Preload("GroupModel").Take(&member, "group_id = ? and user_id = ?", req.GroupID, req.UserID).Error
+	if err != nil {
+		return nil, errors.New("你不是该群成员")
+	}
+	if !member.GroupModel.IsInvite && member.Role == 3 {
+		return nil, errors.New("该群不允许普通成员邀请")
+	}
+
+	invite := user_models.InviteModel{
+		CreatorID: req.UserID,
+		Type:      invites.GroupInvite,
+		TargetID:  req.GroupID,
+		ExpireAt:  time.Now().Add(time.Duration(hours) * time.Hour),
+		MaxUse:    req.MaxUse,
+	}
+	err = l.svcCtx.DB.Create(&invite).Error
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("创建邀请链接失败")
+	}
+	token, err := invites.GenerateToken(invites.InvitePayLoad{
+		InviteID: invite.ID,
+		Type:     invite.Type,
+		TargetID: invite.TargetID,
+	}, l.svcCtx.Config.Invite.Secret, invite.ExpireAt)
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("创建邀请链接失败")
+	}
+
+	return &types.GroupInviteResponse{
+		Token:    token,
+		Link:     invites.TokenLink(l.svcCtx.Config.Invite.Link, token),
+		ExpireAt: invite.ExpireAt.Format("2006-01-02 15:04:05"),
+		MaxUse:   invite.MaxUse,
+	}, nil
+}
+
+// parseGroupInvite 校验邀请token的签名，并查出对应的邀请记录。
+// 邀请人已经退群，或者群关闭了邀请而邀请人不再是管理员的时候，链接失效。
+func parseGroupInvite(svcCtx *svc.ServiceContext, token string) (invite user_models.InviteModel, group group_models.GroupModel, err error) {
+	claims, err := invites.ParseToken(token, svcCtx.Config.Invite.Secret)
+	if err != nil {
+		return
+	}
+	if claims.Type != invites.GroupInvite {
+		err = errors.New("不是加群的邀请链接")
+		return
+	}
+	err = svcCtx.DB.Take(&invite, "id = ? and type = ? and target_id = ?", claims.InviteID, claims.Type, claims.TargetID).Error
+	if err != nil {
+		err = errors.New("邀请链接无效")
+		return
+	}
+	err = invite.Check()
+	if err != nil {
+		return
+	}
+	err = svcCtx.DB.Take(&group, invite.TargetID).Error
+	if err != nil {
+		err = errors.New("群聊不存在")
+		return
+	}
+	var member group_models.GroupMemberModel
+	err = svcCtx.DB.Take(&member, "group_id = ? and user_id = ?", invite.TargetID, invite.CreatorID).Error
+	if err != nil || (!group.IsInvite && member.Role == 3) {
+		err = errors.New("邀请链接已失效")
+		return
+	}
+	return
+}
@@ -0,0 +1,42 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/fim_group/group_api/internal/svc"
+	"fim/fim_group/group_api/internal/types"
+	"fim/utils/invites"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GroupInviteQrcodeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGroupInviteQrcodeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GroupInviteQrcodeLogic {
+	return &GroupInviteQrcodeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GroupInviteQrcode 生成加群邀请链接的二维码，返回png图片数据。
+func (l *GroupInviteQrcodeLogic) GroupInviteQrcode(req *types.GroupInviteQrcodeRequest) (byteData []byte, err error) {
+	if req.Size < 0 || req.Size > 1024 {
+		return nil, errors.New("二维码尺寸错误")
+	}
+	_, _, err = parseGroupInvite(l.svcCtx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+	byteData, err = invites.QRCode(invites.TokenLink(l.svcCtx.Config.Invite.Link, req.Token), req.Size)
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("二维码生成失败")
+	}
+	return byteData, nil
+}
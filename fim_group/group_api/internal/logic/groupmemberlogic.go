@@ -5,13 +5,14 @@ import (
 	"errors"
 	"fim/common/list_query"
 	"fim/common/models"
-	"fim/common/models/ctype"
+	"fim/common/service/redis_service"
 	"fim/fim_group/group_api/internal/svc"
 	"fim/fim_group/group_api/internal/types"
-	"fim/fim_group/group_models"
 	"fim/fim_user/user_rpc/types/user_rpc"
+	"fim/utils/pinyins"
 	"fmt"
 	"github.com/zeromicro/go-zero/core/logx"
+	"strings"
 )
 
 type GroupMemberLogic struct {
@@ -36,15 +37,23 @@ type Data struct {
 	MemberNickname  string `gorm:"column:member_nickname"`
 	NewMsgDate      string `gorm:"column:new_msg_date"`
 	ProhibitionTime *int   `gorm:"column:prohibition_time"`
+	SortPinyin      string `gorm:"column:sort_pinyin"` // 显示名称的全拼，有群昵称用群昵称，否则用用户昵称
 }
 
+// likeEscape 转义like语句中的通配符
+var likeEscape = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // GroupMember 根据请求中的排序方式和群组ID获取群成员信息。
 // 参数 req: GroupMemberRequest对象，包含排序方式、页码、每页限制和群组ID等信息。
 // 返回值 resp: GroupMemberResponse对象，包含成员列表和总数等信息。
 // 返回值 err: 错误信息，如果执行过程中发生错误，会返回具体的错误信息。
 func (l *GroupMemberLogic) GroupMember(req *types.GroupMemberRequest) (resp *types.GroupMemberResponse, err error) {
 	// 根据请求的排序方式进行合法性校验
+	sort := req.Sort
 	switch req.Sort {
+	case "", "pinyin asc":
+		// 按拼音A-Z排序，非字母开头的排在最后
+		sort = "if(sort_pinyin regexp '^[a-z]', 0, 1), sort_pinyin"
 	case "new_msg_date desc", "new_msg_date asc":
 	case "role asc":
 	case "create_at desc", "create_at asc":
@@ -53,48 +62,53 @@ func (l *GroupMemberLogic) GroupMember(req *types.GroupMemberRequest) (resp *typ
 		return nil, errors.New("不支持的排序模式")
 	}
 	// 构造新消息日期的SQL子查询字符串
-	column := fmt.Sprintf(fmt.Sprintf("(select group_msg_models.created_at from group_msg_models where group_msg_models.group_id=%d and group_msg_models.send_user_id=user_id order by created_at desc limit 1)as new_msg_date", req.ID))
+	column := fmt.Sprintf(fmt.Sprintf("(select group_msg_models.created_at from group_msg_models where group_msg_models.group_id=%d and group_msg_models.send_user_id=m.user_id order by created_at desc limit 1)as new_msg_date", req.ID))
+	// 按群昵称、用户昵称过滤，支持汉字、全拼和拼音首字母
+	where := l.svcCtx.DB.Where("group_id=?", req.ID)
+	if key := strings.TrimSpace(req.Key); key != "" {
+		contains := "%" + likeEscape.Replace(key) + "%"
+		pinyinContains := "%" + likeEscape.Replace(strings.ToLower(key)) + "%"
+		prefix := likeEscape.Replace(strings.ToLower(key)) + "%"
+		where = where.Where("member_nickname like ? or nickname like ? or member_nickname_pinyin like ? or nickname_pinyin like ? or member_nickname_initials like ? or nickname_initials like ?",
+			contains, contains, pinyinContains, pinyinContains, prefix, prefix)
+	}
 	// 执行列表查询并获取成员列表和总数
 	memberList, count, _ := list_query.ListQuery(l.svcCtx.DB, Data{}, list_query.Option{
 		PageInfo: models.PagaInfo{
 			Page:  req.Page,
 			Limit: req.Limit,
-			Sort:  req.Sort,
+			Sort:  sort,
 		},
-		Where: l.svcCtx.DB.Where("group_id=?", req.ID),
+		Where: where,
 		Table: func() (string, any) {
-			return "(?)as u", l.svcCtx.DB.Model(&group_models.GroupMemberModel{GroupID: req.ID}).
-				Select("group_id",
-					"user_id",
-					"role",
-					"created_at",
-					"member_nickname",
-					"prohibition_time",
-					column)
+			// 用户昵称的拼音在修改昵称时已经同步到搜索索引
+			return "(?)as u", l.svcCtx.DB.Table("group_member_models m").
+				Joins("left join user_search_index_models idx on idx.user_id = m.user_id").
+				Select("m.group_id",
+					"m.user_id",
+					"m.role",
+					"m.created_at",
+					"m.member_nickname",
+					"m.prohibition_time",
+					column,
+					"m.member_nickname_pinyin",
+					"m.member_nickname_initials",
+					"ifnull(idx.nickname, '') as nickname",
+					"ifnull(idx.pinyin, '') as nickname_pinyin",
+					"ifnull(idx.initials, '') as nickname_initials",
+					"if(m.member_nickname <> '', m.member_nickname_pinyin, ifnull(idx.pinyin, '')) as sort_pinyin").
+				Where("m.group_id = ?", req.ID)
 		},
 	})
 	// 初始化用户ID列表
-	var userIDList []uint32
+	var userIDList []uint
 	// 从成员列表中提取用户ID
 	for _, data := range memberList {
-		userIDList = append(userIDList, uint32(data.UserID))
+		userIDList = append(userIDList, data.UserID)
 	}
-	// 初始化用户信息映射
-	var userInfoMap = map[uint]ctype.UserInfo{}
-	// 调用用户RPC服务获取用户详细信息
-	userListResponse, err := l.svcCtx.UserRpc.UserListInfo(l.ctx, &user_rpc.UserListInfoRequest{
-		UserIdList: userIDList,
-	})
-	if err == nil {
-		// 将用户详细信息填充到映射中
-		for u, info := range userListResponse.UserInfo {
-			userInfoMap[uint(u)] = ctype.UserInfo{
-				ID:       uint(u),
-				NickName: info.NickName,
-				Avatar:   info.Avatar,
-			}
-		}
-	} else {
+	// 批量获取用户详细信息，优先走缓存
+	userInfoMap, err := redis_service.GetUserListInfo(l.svcCtx.Redis, l.svcCtx.UserRpc, userIDList)
+	if err != nil {
 		logx.Error(err)
 	}
 	// 初始化好友映射
@@ -138,6 +152,7 @@ func (l *GroupMemberLogic) GroupMember(req *types.GroupMemberRequest) (resp *typ
 			NewMsgDate:      data.NewMsgDate,
 			IsFriend:        friendMap[data.UserID],
 			ProhibitionTime: data.ProhibitionTime,
+			Letter:          pinyins.Letter(data.SortPinyin),
 		})
 	}
 	// 设置响应的成员总数
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fim/fim_group/group_models"
+	"fim/utils/pinyins"
 
 	"fim/fim_group/group_api/internal/svc"
 	"fim/fim_group/group_api/internal/types"
@@ -47,7 +48,9 @@ func (l *GroupMemberNicknameUpdateLogic) GroupMemberNicknameUpdate(req *types.Gr
 	// 如果操作用户和被修改昵称的用户是同一个用户，则直接更新昵称。
 	if req.UserID == req.MemberID {
 		l.svcCtx.DB.Model(&member).Updates(map[string]any{
-			"member_nickname": req.Nickname,
+			"member_nickname":          req.Nickname,
+			"member_nickname_pinyin":   pinyins.Full(req.Nickname),
+			"member_nickname_initials": pinyins.Initials(req.Nickname),
 		})
 		return
 	}
@@ -59,7 +62,9 @@ func (l *GroupMemberNicknameUpdateLogic) GroupMemberNicknameUpdate(req *types.Gr
 
 	// 更新被修改昵称用户的昵称。
 	l.svcCtx.DB.Model(&member1).Updates(map[string]any{
-		"member_nickname": req.Nickname,
+		"member_nickname":          req.Nickname,
+		"member_nickname_pinyin":   pinyins.Full(req.Nickname),
+		"member_nickname_initials": pinyins.Initials(req.Nickname),
 	})
 
 	return
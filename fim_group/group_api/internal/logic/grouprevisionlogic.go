@@ -0,0 +1,73 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/common/models/ctype"
+	"fim/fim_group/group_models"
+
+	"fim/fim_group/group_api/internal/svc"
+	"fim/fim_group/group_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GroupRevisionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewGroupRevisionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GroupRevisionLogic {
+	return &GroupRevisionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+type RevisionResponse struct {
+	Revision   int       `json:"revision"` // 版本号，0是最初发出的版本
+	MsgPreview string    `json:"msg_preview"`
+	Msg        ctype.Msg `json:"msg"`
+	CreatedAt  string    `json:"created_at"` // 这个版本被替换的时间
+}
+type RevisionListResponse struct {
+	List []RevisionResponse `json:"list"` // 编辑之前的版本，按版本号从小到大，不包括当前的版本
+}
+
+// GroupRevision 查询群消息的编辑历史，只有群成员可以查询，自己删除的消息查不到
+func (l *GroupRevisionLogic) GroupRevision(req *types.GroupRevisionRequest) (resp *RevisionListResponse, err error) {
+	var groupMsg group_models.GroupMsgModel
+	err = l.svcCtx.DB.Take(&groupMsg, req.ID).Error
+	if err != nil {
+		return nil, errors.New("消息不存在")
+	}
+	var member group_models.GroupMemberModel
+	err = l.svcCtx.DB.Take(&member, "group_id = ? and user_id = ?", groupMsg.GroupID, req.UserID).Error
+	if err != nil {
+		return nil, errors.New("该用户不是该群成员")
+	}
+	var count int64
+	l.svcCtx.DB.Model(group_models.GroupUserMsgDeleteModel{}).
+		Where("user_id = ? and msg_id = ?", req.UserID, groupMsg.ID).Count(&count)
+	if count > 0 {
+		return nil, errors.New("消息不存在")
+	}
+	if groupMsg.MsgType == ctype.WithdrawMsgType {
+		return nil, errors.New("消息已被撤回")
+	}
+
+	var revisionList []group_models.GroupMsgRevisionModel
+	l.svcCtx.DB.Where("msg_id = ?", groupMsg.ID).Order("revision").Find(&revisionList)
+	resp = &RevisionListResponse{List: make([]RevisionResponse, 0)}
+	for _, model := range revisionList {
+		resp.List = append(resp.List, RevisionResponse{
+			Revision:   model.Revision,
+			MsgPreview: model.MsgPreview,
+			Msg:        model.Msg,
+			CreatedAt:  model.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return resp, nil
+}
@@ -8,6 +8,7 @@ import (
 	"fim/fim_group/group_models"
 	user_models "fim/fim_user/user_models"
 	"fim/fim_user/user_rpc/types/user_rpc"
+	"fmt"
 
 	"fim/fim_group/group_api/internal/svc"
 	"fim/fim_group/group_api/internal/types"
@@ -135,5 +136,26 @@ func (l *GroupValidAddLogic) GroupValidAdd(req *types.GroupValidAddRequest) (res
 	if err != nil {
 		return nil, err
 	}
+	// 需要审核的申请通知群主和管理员。
+	if verifyModel.Status == 0 {
+		content := req.Verify
+		if content == "" {
+			content = "申请加入群聊"
+		}
+		var adminIDList []uint
+		l.svcCtx.DB.Model(&group_models.GroupMemberModel{}).
+			Where("group_id = ? and role in ?", req.GroupID, []int8{1, 2}).Pluck("user_id", &adminIDList)
+		for _, adminID := range adminIDList {
+			user_models.SendNotification(l.svcCtx.DB, l.svcCtx.Redis, user_models.NotificationModel{
+				UserID:     adminID,
+				Type:       user_models.NotifyGroupVerify,
+				SourceID:   verifyModel.ID,
+				SendUserID: req.UserID,
+				GroupID:    req.GroupID,
+				Title:      "加群申请",
+				Content:    fmt.Sprintf("申请加入%s：%s", group.Title, content),
+			})
+		}
+	}
 	return resp, nil
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fim/fim_group/group_models"
+	"fim/fim_user/user_models"
+	"fmt"
 
 	"fim/fim_group/group_api/internal/svc"
 	"fim/fim_group/group_api/internal/types"
@@ -81,5 +83,29 @@ func (l *GroupValidStatusLogic) GroupValidStatus(req *types.GroupValidStatusRequ
 	}
 	// 更新验证请求的状态。
 	l.svcCtx.DB.Model(&groupValidModel).UpdateColumn("status", req.Status)
-	return
+
+	// 通过或者拒绝之后通知申请人，其他管理员收到的申请通知标记为已读。
+	if req.Status == 1 || req.Status == 2 {
+		var group group_models.GroupModel
+		l.svcCtx.DB.Take(&group, groupValidModel.GroupID)
+		notification := user_models.NotificationModel{
+			UserID:     groupValidModel.UserID,
+			Type:       user_models.NotifyGroupVerifyResult,
+			SourceID:   groupValidModel.ID,
+			SendUserID: req.UserID,
+			GroupID:    groupValidModel.GroupID,
+			Title:      "加群申请已通过",
+			Content:    fmt.Sprintf("你已加入%s", group.Title),
+		}
+		if req.Status == 2 {
+			notification.Title = "加群申请被拒绝"
+			notification.Content = fmt.Sprintf("%s拒绝了你的加群申请", group.Title)
+		}
+		user_models.SendNotification(l.svcCtx.DB, l.svcCtx.Redis, notification)
+	}
+	err = user_models.ReadNotificationBySource(l.svcCtx.DB, user_models.NotifyGroupVerify, groupValidModel.ID)
+	if err != nil {
+		logx.Error(err)
+	}
+	return nil, nil
 }
@@ -1,6 +1,9 @@
 package svc
 
 import (
+	"fim/common/models/ctype"
+	"fim/common/service/redis_service"
+	"fim/common/ws_hub"
 	"fim/common/zrpc_interceptor"
 	"fim/core"
 	"fim/fim_file/file_rpc/files"
@@ -18,8 +21,10 @@ type ServiceContext struct {
 	DB      *gorm.DB
 	UserRpc user_rpc.UsersClient
 	//GroupRpc        group_rpc.GroupsClient
-	FileRpc file_rpc.FilesClient
-	Redis   *redis.Client
+	FileRpc  file_rpc.FilesClient
+	Redis    *redis.Client
+	WsRouter *redis_service.WsRouter     // 用户websocket连接的路由，多个节点部署的时候用来转发推送
+	WsHub    *ws_hub.Hub[ctype.UserInfo] // 这个节点上的websocket连接
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
@@ -30,7 +35,9 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		DB:      mysqlDb,
 		UserRpc: users.NewUsers(zrpc.MustNewClient(c.UserRpc, zrpc.WithUnaryClientInterceptor(zrpc_interceptor.ClientInfoInterceptor))),
 		//GroupRpc:        groups.NewGroups(zrpc.MustNewClient(c.GroupRpc, zrpc.WithUnaryClientInterceptor(zrpc_interceptor.ClientInfoInterceptor))),
-		FileRpc: files.NewFiles(zrpc.MustNewClient(c.FileRpc, zrpc.WithUnaryClientInterceptor(zrpc_interceptor.ClientInfoInterceptor))),
-		Redis:   client,
+		FileRpc:  files.NewFiles(zrpc.MustNewClient(c.FileRpc, zrpc.WithUnaryClientInterceptor(zrpc_interceptor.ClientInfoInterceptor))),
+		Redis:    client,
+		WsRouter: redis_service.NewWsRouter(client, "group"),
+		WsHub:    ws_hub.NewHub[ctype.UserInfo](c.Ws),
 	}
 }
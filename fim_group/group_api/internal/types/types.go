@@ -12,6 +12,7 @@ type GroupMemberInfo struct {
 	NewMsgDate      string `json:"new_msg_date"`
 	IsFriend        bool   `json:"is_friend"`
 	ProhibitionTime *int   `json:"prohibition_time"`
+	Letter          string `json:"letter"` // 索引字母 A-Z，其他字符为 #
 }
 
 type GroupMyResponse struct {
@@ -176,7 +177,8 @@ type GroupMemberRequest struct {
 	ID     uint   `form:"id"`
 	Page   int    `form:"page,optional"`
 	Limit  int    `form:"limit,optional"`
-	Sort   string `form:"sort,optional"`
+	Sort   string `form:"sort,optional"` // 排序方式，默认按群昵称或用户昵称的拼音A-Z排序
+	Key    string `form:"key,optional"`  // 按群昵称、用户昵称过滤，支持汉字、全拼和拼音首字母
 }
 
 type GroupMemberResponse struct {
@@ -322,3 +324,59 @@ type GroupfriendsListResponse struct {
 	List  []GroupfriendsResponse `json:"list"`  //好友列表
 	Count int                    `json:"count"` //好友数量
 }
+
+type GroupInviteRequest struct {
+	UserID  uint `header:"user_id"`
+	GroupID uint `json:"group_id"`         //群组ID
+	Hours   int  `json:"hours,optional"`   //有效期，单位小时，不传使用默认值
+	MaxUse  int  `json:"max_use,optional"` //最大使用次数，0表示不限制
+}
+
+type GroupInviteResponse struct {
+	Token    string `json:"token"`
+	Link     string `json:"link"` //邀请链接
+	ExpireAt string `json:"expire_at"`
+	MaxUse   int    `json:"max_use"`
+}
+
+type GroupInviteInfoRequest struct {
+	UserID uint   `header:"user_id"`
+	Token  string `form:"token"`
+}
+
+type GroupInviteInfoResponse struct {
+	GroupID              uint                  `json:"group_id"`              //群组ID
+	Title                string                `json:"title"`                 //群名称
+	Abstract             string                `json:"abstract"`              //群简介
+	Avatar               string                `json:"avatar"`                //群头像
+	MemberCount          int                   `json:"member_count"`          //群成员数量
+	Inviter              UserInfo              `json:"inviter"`               //邀请人
+	Verification         int8                  `json:"verification"`          //验证方式
+	VerificationQuestion *VerificationQuestion `json:"verification_question"` //验证问题，不包含答案
+	IsMember             bool                  `json:"is_member"`             //是否已经在群里
+	ExpireAt             string                `json:"expire_at"`
+	RemainUse            int                   `json:"remain_use"` //剩余使用次数，-1表示不限制
+}
+
+type GroupInviteAcceptRequest struct {
+	UserID               uint                  `header:"user_id"`
+	Token                string                `json:"token"`
+	Verify               string                `json:"verify,optional"`                //验证消息
+	VerificationQuestion *VerificationQuestion `json:"verification_question,optional"` //验证问题
+}
+
+type GroupInviteAcceptResponse struct {
+}
+
+type GroupInviteQrcodeRequest struct {
+	Token string `form:"token"`
+	Size  int    `form:"size,optional"` //二维码尺寸，默认256
+}
+
+type GroupRevisionRequest struct {
+	UserID uint `header:"user_id"` //用户ID
+	ID     uint `form:"id"`        //群消息ID
+}
+
+type GroupRevisionResponse struct {
+}
@@ -18,6 +18,7 @@ type GroupModel struct {
 	IsTemporarySession   bool                        `json:"isTemporarySession"`          // 是否开启临时会话
 	IsProhibition        bool                        `json:"isProhibition"`               // 是否开启全员禁言
 	Size                 int                         `json:"size"`                        // 群规模  20  100 200 1000 2000
+	MsgSeq               int64                       `json:"msgSeq"`                      // 群消息已经分配的最大序号
 	MemberList           []GroupMemberModel          `gorm:"foreignKey:GroupID" json:"-"` // 群成员列表
 	GroupMsgList         []GroupMsgModel             `gorm:"foreignKey:GroupID" json:"-"` // 群消息列表
 }
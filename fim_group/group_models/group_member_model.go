@@ -11,13 +11,15 @@ import (
 // GroupMemberModel 群成员表
 type GroupMemberModel struct {
 	models.Model
-	GroupID         uint            `json:"groupID"`                           // 群id
-	GroupModel      GroupModel      `gorm:"foreignKey:GroupID" json:"-"`       // 群
-	UserID          uint            `json:"userID"`                            // 用户id
-	MemberNickname  string          `gorm:"size:32" json:"memberNickname"`     // 群成员昵称
-	Role            int8            `json:"role"`                              // 1 群主 2 管理员  3 普通成员
-	ProhibitionTime *int            `json:"prohibitionTime"`                   // 禁言时间 单位分钟
-	MsgList         []GroupMsgModel `json:"-" gorm:"foreignKey:GroupMemberID"` // 这个用户发的消息
+	GroupID                uint            `json:"groupID"`                           // 群id
+	GroupModel             GroupModel      `gorm:"foreignKey:GroupID" json:"-"`       // 群
+	UserID                 uint            `json:"userID"`                            // 用户id
+	MemberNickname         string          `gorm:"size:32" json:"memberNickname"`     // 群成员昵称
+	MemberNicknamePinyin   string          `gorm:"size:256" json:"-"`                 // 群成员昵称的全拼，修改昵称时计算
+	MemberNicknameInitials string          `gorm:"size:32" json:"-"`                  // 群成员昵称的拼音首字母
+	Role                   int8            `json:"role"`                              // 1 群主 2 管理员  3 普通成员
+	ProhibitionTime        *int            `json:"prohibitionTime"`                   // 禁言时间 单位分钟
+	MsgList                []GroupMsgModel `json:"-" gorm:"foreignKey:GroupMemberID"` // 这个用户发的消息
 }
 
 // GetProhibitionTime 获取禁言时间
@@ -3,20 +3,26 @@ package group_models
 import (
 	"fim/common/models"
 	"fim/common/models/ctype"
+	"gorm.io/gorm"
+	"time"
 )
 
 // GroupMsgModel 群消息表
 type GroupMsgModel struct {
 	models.Model
-	GroupID          uint              `json:"groupID"`                           // 群id
-	GroupModel       GroupModel        `gorm:"foreignKey:GroupID" json:"-"`       // 群
-	SendUserID       uint              `json:"sendUserID"`                        // 发送者id
-	GroupMemberID    uint              `json:"groupMemberID"`                     // 群成员id
-	GroupMemberModel *GroupMemberModel `gorm:"foreignKey:GroupMemberID" json:"-"` // 对应的群成员
-	MsgType          ctype.MsgType     `json:"msgType"`                           // 消息类型 1 文本类型  2 图片消息  3 视频消息 4 文件消息 5 语音消息  6 语言通话  7 视频通话  8 撤回消息 9回复消息 10 引用消息 11 at消息
-	MsgPreview       string            `gorm:"size:64" json:"msgPreview"`         // 消息预览
-	Msg              ctype.Msg         `json:"msg"`                               // 消息内容
-	SystemMsg        *ctype.SystemMsg  `json:"systemMsg"`                         // 系统提示
+	GroupID          uint              `gorm:"index:idx_group_msg_seq" json:"groupID"` // 群id
+	Seq              int64             `gorm:"index:idx_group_msg_seq" json:"seq"`     // 群内的消息序号，从1开始连续递增
+	GroupModel       GroupModel        `gorm:"foreignKey:GroupID" json:"-"`            // 群
+	SendUserID       uint              `json:"sendUserID"`                             // 发送者id
+	GroupMemberID    uint              `json:"groupMemberID"`                          // 群成员id
+	GroupMemberModel *GroupMemberModel `gorm:"foreignKey:GroupMemberID" json:"-"`      // 对应的群成员
+	MsgType          ctype.MsgType     `json:"msgType"`                                // 消息类型 1 文本类型  2 图片消息  3 视频消息 4 文件消息 5 语音消息  6 语言通话  7 视频通话  8 撤回消息 9回复消息 10 引用消息 11 at消息
+	MsgPreview       string            `gorm:"size:64" json:"msgPreview"`              // 消息预览
+	Msg              ctype.Msg         `json:"msg"`                                    // 消息内容
+	SystemMsg        *ctype.SystemMsg  `json:"systemMsg"`                              // 系统提示
+	EditCount        int               `json:"editCount"`                              // 编辑次数，大于0的时候显示已编辑
+	EditedAt         *time.Time        `json:"editedAt"`                               // 最后一次编辑的时间
+	EditSeq          int64             `json:"editSeq"`                                // 最后一次编辑分配的群消息序号，没有编辑过为0
 }
 
 func (chat GroupMsgModel) MsgPreviewMethod() string {
@@ -35,3 +41,28 @@ func (chat GroupMsgModel) MsgPreviewMethod() string {
 	}
 	return chat.Msg.MsgPreview()
 }
+
+// Create 分配群内的消息序号并把消息入库
+// 分配序号的时候锁住群这一行，同一个群的消息按序号串行入库，入库失败序号也不会被占用
+func (chat *GroupMsgModel) Create(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		seq, err := NextGroupSeq(tx, chat.GroupID)
+		if err != nil {
+			return err
+		}
+		chat.Seq = seq
+		return tx.Create(chat).Error
+	})
+}
+
+// NextGroupSeq 给群分配下一个消息序号
+// 需要在事务里调用，群这一行在事务提交之前一直被锁住
+func NextGroupSeq(tx *gorm.DB, groupID uint) (seq int64, err error) {
+	err = tx.Model(&GroupModel{}).Where("id = ?", groupID).
+		UpdateColumn("msg_seq", gorm.Expr("msg_seq + 1")).Error
+	if err != nil {
+		return
+	}
+	err = tx.Model(&GroupModel{}).Where("id = ?", groupID).Select("msg_seq").Scan(&seq).Error
+	return
+}
@@ -0,0 +1,46 @@
+package group_models
+
+import (
+	"fim/common/models"
+	"fim/common/models/ctype"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GroupMsgReactionModel 群消息的表情回应，同一个成员对同一条消息的同一个表情只有一行
+type GroupMsgReactionModel struct {
+	models.Model
+	MsgID  uint   `gorm:"uniqueIndex:idx_group_msg_reaction" json:"msgID"`         // 群消息id
+	UserID uint   `gorm:"uniqueIndex:idx_group_msg_reaction" json:"userID"`        // 回应的用户id
+	Emoji  string `gorm:"size:32;uniqueIndex:idx_group_msg_reaction" json:"emoji"` // 表情
+}
+
+// AddGroupMsgReaction 添加表情回应，重复添加不做处理
+func AddGroupMsgReaction(db *gorm.DB, msgID, userID uint, emoji string) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&GroupMsgReactionModel{
+		MsgID:  msgID,
+		UserID: userID,
+		Emoji:  emoji,
+	}).Error
+}
+
+// RemoveGroupMsgReaction 取消表情回应
+func RemoveGroupMsgReaction(db *gorm.DB, msgID, userID uint, emoji string) error {
+	return db.Where("msg_id = ? and user_id = ? and emoji = ?", msgID, userID, emoji).
+		Delete(&GroupMsgReactionModel{}).Error
+}
+
+// GroupMsgReactionMap 按群消息汇总表情回应
+// 返回值: 群消息id和回应汇总的映射，没有回应的消息不在映射里
+func GroupMsgReactionMap(db *gorm.DB, msgIDList []uint) map[uint][]ctype.Reaction {
+	reactionMap := map[uint][]ctype.Reaction{}
+	if len(msgIDList) == 0 {
+		return reactionMap
+	}
+	var list []GroupMsgReactionModel
+	db.Where("msg_id in ?", msgIDList).Order("id").Find(&list)
+	for _, model := range list {
+		reactionMap[model.MsgID] = ctype.AppendReaction(reactionMap[model.MsgID], model.Emoji, model.UserID)
+	}
+	return reactionMap
+}
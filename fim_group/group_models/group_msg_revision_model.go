@@ -0,0 +1,81 @@
+package group_models
+
+import (
+	"errors"
+	"fim/common/models"
+	"fim/common/models/ctype"
+	"gorm.io/gorm"
+	"time"
+)
+
+// GroupMsgRevisionModel 群消息编辑之前的版本，每编辑一次记录一行
+type GroupMsgRevisionModel struct {
+	models.Model
+	MsgID      uint      `gorm:"uniqueIndex:idx_group_msg_revision" json:"msgID"`    // 群消息id
+	Revision   int       `gorm:"uniqueIndex:idx_group_msg_revision" json:"revision"` // 版本号，0是最初发出的版本
+	MsgPreview string    `gorm:"size:64" json:"msgPreview"`                          // 这个版本的消息预览
+	Msg        ctype.Msg `json:"msg"`                                                // 这个版本的消息内容
+}
+
+// Edit 编辑群消息，把当前的内容记录为一个历史版本，再把新的内容写回消息
+// 编辑也分配一个群消息序号记在消息上，客户端按序号离线同步的时候能拿到编辑之后的内容
+// 同一条消息同时编辑的时候只有一个能成功，另一个返回错误
+func (chat *GroupMsgModel) Edit(db *gorm.DB, msg ctype.Msg) error {
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&GroupMsgRevisionModel{
+			MsgID:      chat.ID,
+			Revision:   chat.EditCount,
+			MsgPreview: chat.MsgPreview,
+			Msg:        chat.Msg,
+		}).Error
+		if err != nil {
+			return errors.New("消息已被修改，请刷新之后重试")
+		}
+		editSeq, err := NextGroupSeq(tx, chat.GroupID)
+		if err != nil {
+			return err
+		}
+		preview := GroupMsgModel{Msg: msg}.MsgPreviewMethod()
+		result := tx.Model(&GroupMsgModel{}).Where("id = ? and edit_count = ?", chat.ID, chat.EditCount).Updates(map[string]any{
+			"msg":         msg,
+			"msg_preview": preview,
+			"edit_count":  chat.EditCount + 1,
+			"edited_at":   now,
+			"edit_seq":    editSeq,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("消息已被修改，请刷新之后重试")
+		}
+		chat.Msg = msg
+		chat.MsgPreview = preview
+		chat.EditCount++
+		chat.EditedAt = &now
+		chat.EditSeq = editSeq
+		return nil
+	})
+}
+
+// IsEdited 消息是否编辑过，编辑之后又撤回的消息不算
+func (chat *GroupMsgModel) IsEdited() bool {
+	return chat.EditCount > 0 && chat.EditedAt != nil && chat.MsgType != ctype.WithdrawMsgType
+}
+
+// IsLatest 消息是否是群里的最后一条消息
+// 编辑也会占用群消息序号，所以按消息表里的最大序号判断
+func (chat *GroupMsgModel) IsLatest(db *gorm.DB) bool {
+	var maxSeq int64
+	db.Model(&GroupMsgModel{}).Where("group_id = ?", chat.GroupID).Select("max(seq)").Scan(&maxSeq)
+	return chat.Seq > 0 && chat.Seq == maxSeq
+}
+
+// SyncSeq 离线同步时这条消息对应的序号，编辑过的消息按最后一次编辑的序号
+func (chat *GroupMsgModel) SyncSeq() int64 {
+	if chat.EditSeq > chat.Seq {
+		return chat.EditSeq
+	}
+	return chat.Seq
+}
@@ -19,4 +19,20 @@ type Config struct {
 		Password string
 		DB       int
 	}
+	FriendVerify struct {
+		ExpireHours         int `json:",default=168"` // 好友请求有效期，单位小时
+		DailyLimit          int `json:",default=20"`  // 每个用户每天最多发起的好友请求数
+		RejectCooldownHours int `json:",default=24"`  // 被拒绝之后再次申请的间隔，单位小时
+	}
+	Account struct {
+		DestroyGraceDays int `json:",default=15"` // 注销宽限期，单位天
+	}
+	Handle struct {
+		ChangeDays int `json:",default=30"` // 用户号修改间隔，单位天
+	}
+	Invite struct {
+		Secret      string // 邀请链接签名密钥
+		ExpireHours int    `json:",default=168"` // 邀请链接默认有效期，单位小时
+		Link        string // 邀请链接地址，带上token或用户号参数之后生成二维码
+	}
 }
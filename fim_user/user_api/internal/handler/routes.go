@@ -12,6 +12,71 @@ import (
 func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 	server.AddRoutes(
 		[]rest.Route{
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/user/admin/ban",
+				Handler: adminBanHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/api/user/admin/ban",
+				Handler: adminBanRemoveHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/user/admin/curtail",
+				Handler: adminCurtailHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/api/user/admin/curtail",
+				Handler: adminCurtailRemoveHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/admin/curtail_log",
+				Handler: adminCurtailLogHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/admin/user_stats",
+				Handler: adminUserStatsHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/admin/users",
+				Handler: adminUserListHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/user/block",
+				Handler: userBlockHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/api/user/block",
+				Handler: userBlockRemoveHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/block",
+				Handler: userBlockListHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/user/deactivate",
+				Handler: userDeactivateHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/user/destroy",
+				Handler: userDestroyHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/export",
+				Handler: userExportHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/api/user/friend_info",
@@ -37,6 +102,81 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/user/friends",
 				Handler: deleteFriendHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/friends/sync",
+				Handler: friendSyncHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/api/user/handle",
+				Handler: userHandleUpdateHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/handle/check",
+				Handler: userHandleCheckHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/user/invite",
+				Handler: userInviteHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/user/invite/accept",
+				Handler: userInviteAcceptHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/invite/info",
+				Handler: userInviteInfoHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/invite/qrcode",
+				Handler: userInviteQrcodeHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/login_log",
+				Handler: loginLogHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/notification",
+				Handler: notificationListHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPut,
+				Path:    "/api/user/notification/read",
+				Handler: notificationReadHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/notification/unread",
+				Handler: notificationUnreadHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/preference",
+				Handler: preferenceListHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPatch,
+				Path:    "/api/user/preference",
+				Handler: preferenceUpdateHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/user/recommend",
+				Handler: friendRecommendHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodDelete,
+				Path:    "/api/user/recommend",
+				Handler: friendRecommendDismissHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodGet,
 				Path:    "/api/user/search",
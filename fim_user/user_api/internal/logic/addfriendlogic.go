@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fim/common/models/ctype"
 	"fim/fim_user/user_models"
+	"fmt"
+	"time"
 
 	"fim/fim_user/user_api/internal/svc"
 	"fim/fim_user/user_api/internal/types"
@@ -48,21 +50,62 @@ func (l *AddFriendLogic) AddFriend(req *types.AddFriendRequest) (resp *types.Add
 		return nil, errors.New("你们已经是好友了")
 	}
 
-	// 检查被请求的用户是否存在
+	// 检查双方是否存在拉黑关系
+	var block user_models.UserBlockModel
+	if block.IsBlock(l.svcCtx.DB, req.UserID, req.FriendID) {
+		if block.UserID == req.UserID {
+			return nil, errors.New("你已将对方拉黑，请先移出黑名单")
+		}
+		return nil, errors.New("对方拒绝添加你为好友")
+	}
+
+	// 检查被请求的用户是否存在，停用和注销中的用户不能被添加
 	var userConf user_models.UserConfModel
-	err = l.svcCtx.DB.Take(&userConf, "user_id = ?", req.FriendID).Error
-	if err != nil {
+	err = l.svcCtx.DB.Preload("UserModel").Take(&userConf, "user_id = ?", req.FriendID).Error
+	if err != nil || !userConf.UserModel.IsActive() {
 		return nil, errors.New("用户不存在")
 	}
 
+	// 被对方拒绝之后，冷却期内不能再次申请
+	var rejectVerify user_models.FriendVerifyModel
+	err = l.svcCtx.DB.Order("updated_at desc").Take(&rejectVerify, "send_user_id = ? and rev_user_id = ? and rev_status = 2", req.UserID, req.FriendID).Error
+	if err == nil {
+		cooldown := time.Duration(l.svcCtx.Config.FriendVerify.RejectCooldownHours) * time.Hour
+		if time.Since(rejectVerify.UpdatedAt) < cooldown {
+			return nil, errors.New("对方已拒绝你的好友请求，请稍后再试")
+		}
+	}
+
+	// 每个用户每天发起的好友请求数有上限
+	countKey := fmt.Sprintf("friend_verify_count:%d:%s", req.UserID, time.Now().Format("20060102"))
+	// 先自增占用一次次数再判断，并发的请求也不会超过上限，请求没有发出去的时候退回这次次数
+	sendCount, err := l.svcCtx.Redis.Incr(countKey).Result()
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("添加好友失败")
+	}
+	if sendCount == 1 {
+		l.svcCtx.Redis.Expire(countKey, 24*time.Hour)
+	}
+	defer func() {
+		if err != nil {
+			l.svcCtx.Redis.Decr(countKey)
+		}
+	}()
+	if sendCount > int64(l.svcCtx.Config.FriendVerify.DailyLimit) {
+		return nil, errors.New("今日好友请求次数已达上限")
+	}
+
 	// 初始化添加好友的响应
 	resp = new(types.AddFriendResponse)
 
 	// 根据用户的验证设置，处理添加好友的请求
+	expireAt := time.Now().Add(time.Duration(l.svcCtx.Config.FriendVerify.ExpireHours) * time.Hour)
 	var verifyModel = user_models.FriendVerifyModel{
 		SendUserID:         req.UserID,
 		RevUserID:          req.FriendID,
 		AdditionalMessages: req.Verify,
+		ExpireAt:           &expireAt,
 	}
 	switch userConf.Verification {
 	case 0:
@@ -77,6 +120,7 @@ func (l *AddFriendLogic) AddFriend(req *types.AddFriendRequest) (resp *types.Add
 			RevUserID:  req.FriendID,
 		}
 		l.svcCtx.DB.Create(&userFriend)
+		l.bumpContactVersion(req.UserID, req.FriendID)
 	case 2:
 		// 处理不需要验证的其他情况
 	case 3:
@@ -120,17 +164,53 @@ func (l *AddFriendLogic) AddFriend(req *types.AddFriendRequest) (resp *types.Add
 				RevUserID:  req.FriendID,
 			}
 			l.svcCtx.DB.Create(&userFriend)
+			l.bumpContactVersion(req.UserID, req.FriendID)
 		}
 	default:
 		return nil, errors.New("不支持的验证参数")
 	}
 
-	// 将验证信息记录到数据库
-	err = l.svcCtx.DB.Create(&verifyModel).Error
+	// 对同一个用户重复申请的时候，更新之前未处理的请求，不再新建记录
+	var pendingVerify user_models.FriendVerifyModel
+	err = l.svcCtx.DB.Take(&pendingVerify, "send_user_id = ? and rev_user_id = ? and rev_status = 0", req.UserID, req.FriendID).Error
+	if err == nil {
+		verifyModel.ID = pendingVerify.ID
+		verifyModel.CreatedAt = pendingVerify.CreatedAt
+		err = l.svcCtx.DB.Save(&verifyModel).Error
+	} else {
+		// 将验证信息记录到数据库
+		err = l.svcCtx.DB.Create(&verifyModel).Error
+	}
 	if err != nil {
 		logx.Error(err)
 		return nil, errors.New("添加好友失败")
 	}
 
+	// 通知对方，直接成为好友的时候也要让对方知道
+	notification := user_models.NotificationModel{
+		UserID:     req.FriendID,
+		Type:       user_models.NotifyFriendVerify,
+		SourceID:   verifyModel.ID,
+		SendUserID: req.UserID,
+		Title:      "好友请求",
+		Content:    req.Verify,
+	}
+	if notification.Content == "" {
+		notification.Content = "请求添加你为好友"
+	}
+	if verifyModel.RevStatus == 1 {
+		notification.Title = "新的好友"
+		notification.Content = "已添加你为好友"
+	}
+	user_models.SendNotification(l.svcCtx.DB, l.svcCtx.Redis, notification)
+
 	return
 }
+
+// bumpContactVersion 直接成为好友的时候，更新双方的联系人版本
+func (l *AddFriendLogic) bumpContactVersion(userID, friendID uint) {
+	err := user_models.BumpFriendContactVersion(l.svcCtx.DB, userID, friendID, user_models.ContactAdd)
+	if err != nil {
+		logx.Error(err)
+	}
+}
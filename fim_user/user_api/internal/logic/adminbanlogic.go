@@ -0,0 +1,38 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type AdminBanLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminBanLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminBanLogic {
+	return &AdminBanLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AdminBan 管理员封禁账号，封禁后账号的登录全部失效并且不能再登录
+func (l *AdminBanLogic) AdminBan(req *types.AdminBanRequest) (resp *types.AdminBanResponse, err error) {
+	if req.Role != 1 {
+		return nil, errors.New("权限不足")
+	}
+	err = addCurtail(l.svcCtx, req.UserID, req.TargetUserID, 5, req.Reason, req.Minute)
+	if err != nil {
+		return nil, err
+	}
+	offlineUser(l.svcCtx, req.TargetUserID)
+	return
+}
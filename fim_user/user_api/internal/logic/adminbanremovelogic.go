@@ -0,0 +1,37 @@
+package logic
+
+import (
+	"context"
+	"errors"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type AdminBanRemoveLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminBanRemoveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminBanRemoveLogic {
+	return &AdminBanRemoveLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AdminBanRemove 管理员解除封禁
+func (l *AdminBanRemoveLogic) AdminBanRemove(req *types.AdminBanRemoveRequest) (resp *types.AdminBanRemoveResponse, err error) {
+	if req.Role != 1 {
+		return nil, errors.New("权限不足")
+	}
+	err = removeCurtail(l.svcCtx, req.UserID, req.TargetUserID, 5, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return
+}
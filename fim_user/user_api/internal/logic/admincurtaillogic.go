@@ -0,0 +1,148 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/common/service/redis_service"
+	"fim/fim_user/user_models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type AdminCurtailLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminCurtailLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminCurtailLogic {
+	return &AdminCurtailLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AdminCurtail 管理员限制用户聊天、添加好友、建群或者群聊
+func (l *AdminCurtailLogic) AdminCurtail(req *types.AdminCurtailRequest) (resp *types.AdminCurtailResponse, err error) {
+	if req.Role != 1 {
+		return nil, errors.New("权限不足")
+	}
+	if req.Type == 5 || !user_models.IsValidCurtailType(req.Type) {
+		return nil, errors.New("限制类型错误")
+	}
+	err = addCurtail(l.svcCtx, req.UserID, req.TargetUserID, req.Type, req.Reason, req.Minute)
+	return
+}
+
+// addCurtail 添加限制并写入操作日志，已经存在同类型的限制时更新原因和到期时间
+func addCurtail(svcCtx *svc.ServiceContext, adminID, userID uint, curtailType int8, reason string, minute int) error {
+	if reason == "" {
+		return errors.New("请填写原因")
+	}
+	if minute < 0 {
+		return errors.New("时长错误")
+	}
+	var user user_models.UserModel
+	err := svcCtx.DB.Take(&user, userID).Error
+	if err != nil || user.Status == 3 {
+		return errors.New("用户不存在")
+	}
+	if user.Role == 1 {
+		return errors.New("不能限制管理员")
+	}
+	var expireAt *time.Time
+	if minute > 0 {
+		t := time.Now().Add(time.Duration(minute) * time.Minute)
+		expireAt = &t
+	}
+	curtail := user_models.UserCurtailModel{
+		UserID:   userID,
+		AdminID:  adminID,
+		Type:     curtailType,
+		Reason:   reason,
+		ExpireAt: expireAt,
+	}
+	err = svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		var old user_models.UserCurtailModel
+		err1 := tx.Take(&old, "user_id = ? and type = ?", userID, curtailType).Error
+		if err1 == nil {
+			curtail.ID = old.ID
+			curtail.CreatedAt = old.CreatedAt
+			curtail.PrevStatus = old.PrevStatus
+			curtail.PrevDeleteAt = old.PrevDeleteAt
+			err1 = tx.Save(&curtail).Error
+		} else {
+			err1 = tx.Create(&curtail).Error
+		}
+		if err1 != nil {
+			return err1
+		}
+		err1 = curtail.Apply(tx, true)
+		if err1 != nil {
+			return err1
+		}
+		return tx.Create(&user_models.UserCurtailLogModel{
+			UserID:   userID,
+			AdminID:  adminID,
+			Type:     curtailType,
+			Action:   1,
+			Reason:   reason,
+			ExpireAt: expireAt,
+		}).Error
+	})
+	if errors.Is(err, user_models.ErrCurtailBanStatus) {
+		return err
+	}
+	if err != nil {
+		logx.Error(err)
+		return errors.New("操作失败")
+	}
+	// 通知各个节点刷新内存中的用户信息，让限制对已经建立的连接立即生效
+	// 封禁账号由调用方在写入下线标记之后再通知
+	if curtailType != 5 {
+		redis_service.PublishUserInfoChange(svcCtx.Redis, userID)
+	}
+	content := fmt.Sprintf("你的账号已被%s，原因：%s，", curtail.Name(), reason)
+	if expireAt != nil {
+		content += "到期时间：" + expireAt.Format("2006-01-02 15:04:05")
+	} else {
+		content += "永久有效"
+	}
+	user_models.SendNotification(svcCtx.DB, svcCtx.Redis, user_models.NotificationModel{
+		UserID:  userID,
+		Type:    user_models.NotifySystem,
+		Title:   "账号限制",
+		Content: content,
+	})
+	return nil
+}
+
+// removeCurtail 管理员解除限制
+func removeCurtail(svcCtx *svc.ServiceContext, adminID, userID uint, curtailType int8, reason string) error {
+	var curtail user_models.UserCurtailModel
+	err := svcCtx.DB.Take(&curtail, "user_id = ? and type = ?", userID, curtailType).Error
+	if err != nil {
+		return errors.New("该用户没有这个限制")
+	}
+	err = curtail.Remove(svcCtx.DB, adminID, 2, reason)
+	if err != nil {
+		logx.Error(err)
+		return errors.New("操作失败")
+	}
+	redis_service.PublishUserInfoChange(svcCtx.Redis, userID)
+	user_models.SendNotification(svcCtx.DB, svcCtx.Redis, user_models.NotificationModel{
+		UserID:  userID,
+		Type:    user_models.NotifySystem,
+		Title:   "限制解除",
+		Content: fmt.Sprintf("你的账号已解除%s", curtail.Name()),
+	})
+	return nil
+}
@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/common/list_query"
+	"fim/common/models"
+	"fim/fim_user/user_models"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type AdminCurtailLogLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminCurtailLogLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminCurtailLogLogic {
+	return &AdminCurtailLogLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AdminCurtailLog 管理员查看限制和封禁的操作日志
+func (l *AdminCurtailLogLogic) AdminCurtailLog(req *types.AdminCurtailLogRequest) (resp *types.AdminCurtailLogResponse, err error) {
+	if req.Role != 1 {
+		return nil, errors.New("权限不足")
+	}
+	option := list_query.Option{
+		PageInfo: models.PagaInfo{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Sort:  "created_at desc",
+		},
+	}
+	if req.TargetUserID != 0 {
+		option.Where = l.svcCtx.DB.Where("user_id = ?", req.TargetUserID)
+	}
+	logs, count, err := list_query.ListQuery(l.svcCtx.DB, user_models.UserCurtailLogModel{}, option)
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("查询失败")
+	}
+	resp = &types.AdminCurtailLogResponse{Count: count, List: make([]types.AdminCurtailLogInfo, 0)}
+	for _, log := range logs {
+		info := types.AdminCurtailLogInfo{
+			ID:        log.ID,
+			UserID:    log.UserID,
+			AdminID:   log.AdminID,
+			Type:      log.Type,
+			Action:    log.Action,
+			Reason:    log.Reason,
+			CreatedAt: log.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if log.ExpireAt != nil {
+			info.ExpireAt = log.ExpireAt.Format("2006-01-02 15:04:05")
+		}
+		resp.List = append(resp.List, info)
+	}
+	return resp, nil
+}
@@ -0,0 +1,38 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/fim_user/user_models"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type AdminCurtailRemoveLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminCurtailRemoveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminCurtailRemoveLogic {
+	return &AdminCurtailRemoveLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AdminCurtailRemove 管理员解除用户的限制
+func (l *AdminCurtailRemoveLogic) AdminCurtailRemove(req *types.AdminCurtailRemoveRequest) (resp *types.AdminCurtailRemoveResponse, err error) {
+	if req.Role != 1 {
+		return nil, errors.New("权限不足")
+	}
+	if req.Type == 5 || !user_models.IsValidCurtailType(req.Type) {
+		return nil, errors.New("限制类型错误")
+	}
+	err = removeCurtail(l.svcCtx, req.UserID, req.TargetUserID, req.Type, req.Reason)
+	return
+}
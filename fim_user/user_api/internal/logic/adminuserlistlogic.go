@@ -0,0 +1,79 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/common/list_query"
+	"fim/common/models"
+	"fim/fim_user/user_models"
+	"strconv"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type AdminUserListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminUserListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminUserListLogic {
+	return &AdminUserListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AdminUserList 管理员查看用户列表，可以按用户id或昵称搜索
+func (l *AdminUserListLogic) AdminUserList(req *types.AdminUserListRequest) (resp *types.AdminUserListResponse, err error) {
+	if req.Role != 1 {
+		return nil, errors.New("权限不足")
+	}
+	option := list_query.Option{
+		PageInfo: models.PagaInfo{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Sort:  "created_at desc",
+		},
+		Preload: []string{"UserConfModel"},
+	}
+	// key是数字的时候按用户id查询，否则按昵称模糊搜索
+	_, err1 := strconv.Atoi(req.Key)
+	if req.Key != "" && err1 == nil {
+		option.Where = l.svcCtx.DB.Where("id = ?", req.Key)
+	} else {
+		option.PageInfo.Key = req.Key
+		option.Likes = []string{"nickname"}
+	}
+	users, count, err := list_query.ListQuery(l.svcCtx.DB, user_models.UserModel{}, option)
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("查询失败")
+	}
+	resp = &types.AdminUserListResponse{Count: count, List: make([]types.AdminUserInfo, 0)}
+	for _, user := range users {
+		info := types.AdminUserInfo{
+			UserID:         user.ID,
+			Nickname:       user.Nickname,
+			Avatar:         user.Avatar,
+			Role:           user.Role,
+			Status:         user.Status,
+			IP:             user.IP,
+			Addr:           user.Addr,
+			RegisterSource: user.RegisterSource,
+			CreatedAt:      user.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if user.UserConfModel != nil {
+			info.CurtailChat = user.UserConfModel.CurtailChat
+			info.CurtailAddUser = user.UserConfModel.CurtailAddUser
+			info.CurtailCreateGroup = user.UserConfModel.CurtailCreateGroup
+			info.CurtailInGroupChat = user.UserConfModel.CurtailInGroupChat
+		}
+		resp.List = append(resp.List, info)
+	}
+	return resp, nil
+}
@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/fim_chat/chat_models"
+	"fim/fim_group/group_models"
+	"fim/fim_user/user_models"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type AdminUserStatsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewAdminUserStatsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AdminUserStatsLogic {
+	return &AdminUserStatsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// AdminUserStats 管理员查看用户的统计数据和生效中的限制
+func (l *AdminUserStatsLogic) AdminUserStats(req *types.AdminUserStatsRequest) (resp *types.AdminUserStatsResponse, err error) {
+	if req.Role != 1 {
+		return nil, errors.New("权限不足")
+	}
+	var user user_models.UserModel
+	err = l.svcCtx.DB.Take(&user, req.TargetUserID).Error
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	resp = &types.AdminUserStatsResponse{UserID: user.ID, CurtailList: make([]types.AdminCurtailInfo, 0)}
+	l.svcCtx.DB.Model(&user_models.FriendModel{}).
+		Where("send_user_id = ? or rev_user_id = ?", user.ID, user.ID).Count(&resp.FriendCount)
+	l.svcCtx.DB.Model(&group_models.GroupMemberModel{}).
+		Where("user_id = ?", user.ID).Count(&resp.GroupCount)
+	l.svcCtx.DB.Model(&group_models.GroupModel{}).
+		Where("creator = ?", user.ID).Count(&resp.GroupOwnerCount)
+	l.svcCtx.DB.Model(&chat_models.ChatModel{}).
+		Where("send_user_id = ?", user.ID).Count(&resp.ChatCount)
+	l.svcCtx.DB.Model(&group_models.GroupMsgModel{}).
+		Where("send_user_id = ?", user.ID).Count(&resp.GroupMsgCount)
+
+	var curtailList []user_models.UserCurtailModel
+	l.svcCtx.DB.Order("created_at desc").Find(&curtailList, "user_id = ?", user.ID)
+	for _, curtail := range curtailList {
+		info := types.AdminCurtailInfo{
+			Type:      curtail.Type,
+			Reason:    curtail.Reason,
+			AdminID:   curtail.AdminID,
+			CreatedAt: curtail.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if curtail.ExpireAt != nil {
+			info.ExpireAt = curtail.ExpireAt.Format("2006-01-02 15:04:05")
+		}
+		resp.CurtailList = append(resp.CurtailList, info)
+	}
+	return resp, nil
+}
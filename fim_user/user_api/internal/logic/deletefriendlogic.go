@@ -31,5 +31,9 @@ func (l *DeleteFriendLogic) DeleteFriend(req *types.DeleteFriendRequest) (resp *
 		return nil, errors.New("你们不是好友")
 	}
 	l.svcCtx.DB.Delete(&friend)
-	return
+	err = user_models.BumpFriendContactVersion(l.svcCtx.DB, req.UserID, req.FriendID, user_models.ContactRemove)
+	if err != nil {
+		logx.Error(err)
+	}
+	return resp, nil
 }
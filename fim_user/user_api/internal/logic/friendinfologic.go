@@ -40,7 +40,7 @@ func NewFriendInfoLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Friend
 func (l *FriendInfoLogic) FriendInfo(req *types.FriendInfoRequest) (resp *types.FriendInfoResponse, err error) {
 	// 检查请求者和好友是否为好友关系
 	var friend user_models.FriendModel
-	if friend.IsFriend(l.svcCtx.DB, req.UserID, req.FriendID) {
+	if !friend.IsFriend(l.svcCtx.DB, req.UserID, req.FriendID) {
 		// 如果不是好友，则返回错误
 		return nil, errors.New("他人不是你的好友")
 	}
@@ -58,13 +58,32 @@ func (l *FriendInfoLogic) FriendInfo(req *types.FriendInfoRequest) (resp *types.
 	var friendUser user_models.UserModel
 	json.Unmarshal(res.Data, &friendUser)
 
+	// 停用和注销的用户不展示资料
+	if !friendUser.IsActive() {
+		friendUser = user_models.UserModel{Model: friendUser.Model, Nickname: "已注销用户"}
+	}
+	// 扩展资料按对方设置的可见范围展示
+	friendUser.FilterProfile(true)
+
 	// 构建并返回好友信息的响应
 	response := types.FriendInfoResponse{
-		UserID:   friendUser.ID,
-		Nickname: friendUser.Nickname,
-		Avatar:   friendUser.Avatar,
-		Abstract: friendUser.Abstract,
-		Notice:   friend.GetUserNotice(req.FriendID),
+		UserID:     friendUser.ID,
+		Nickname:   friendUser.Nickname,
+		Avatar:     friendUser.Avatar,
+		Abstract:   friendUser.Abstract,
+		Notice:     friend.GetUserNotice(req.FriendID),
+		Gender:     friendUser.Gender,
+		Birthday:   friendUser.Birthday,
+		Region:     friendUser.Region,
+		Signature:  friendUser.Signature,
+		CoverImage: friendUser.CoverImage,
+		Addr:       friendUser.Addr,
+	}
+	if friendUser.Handle != nil {
+		response.Handle = *friendUser.Handle
+	}
+	if friendUser.UserConfModel != nil {
+		response.Presence = friendUser.UserConfModel.Presence
 	}
 	return &response, nil
 }
@@ -2,9 +2,9 @@ package logic
 
 import (
 	"context"
-	"fim/common/list_query"
-	"fim/common/models"
 	"fim/fim_user/user_models"
+	"fim/utils/pinyins"
+	"sort"
 	"strconv"
 
 	"fim/fim_user/user_api/internal/svc"
@@ -28,63 +28,169 @@ func NewFriendListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Friend
 }
 
 // FriendList 获取用户的朋友列表
+// 按显示名称（有备注用备注，否则用昵称）的拼音 A-Z 排序，key 支持汉字、全拼和拼音首字母过滤
 // @param req 包含页码和每页数量的请求参数
 // @return resp 包含朋友信息的响应对象
 // @return err 可能发生的错误
 func (l *FriendListLogic) FriendList(req *types.FriendListRequest) (resp *types.FriendListResponse, err error) {
-	// 使用ListQuery查询朋友信息，包括分页和预加载用户信息
-	friends, count, _ := list_query.ListQuery(l.svcCtx.DB, user_models.FriendModel{}, list_query.Option{
-		PageInfo: models.PagaInfo{
-			Page:  req.Page,
-			Limit: req.Limit,
-		},
-		Where:   l.svcCtx.DB.Where("send_user_id=? or recv_user_id=?", req.UserID, req.UserID),
-		Preload: []string{"SendUserModel", "RecvUserModel"},
-	})
+	// 先取联系人版本再查列表，查询期间发生的变化会在下一次增量同步里补上
+	version := contactVersion(l.svcCtx, req.UserID)
 
-	// 从Redis获取在线用户列表
-	// 查询用户在线
-	onlineMap := l.svcCtx.Redis.HGetAll("online").Val()
-	var onlineUserMap = map[uint]bool{}
-	for key, _ := range onlineMap {
-		val, err1 := strconv.Atoi(key)
-		if err1 != nil {
-			logx.Error(err1)
-			continue
-		}
-		onlineUserMap[uint(val)] = true
+	// 排序需要全部好友，好友数量有限，直接在内存里排序分页
+	var friends []user_models.FriendModel
+	l.svcCtx.DB.Preload("SendUserModel").Preload("RevUserModel").
+		Find(&friends, "send_user_id = ? or rev_user_id = ?", req.UserID, req.UserID)
+
+	var friendIDList []uint
+	for _, friend := range friends {
+		friendIDList = append(friendIDList, friendID(friend, req.UserID))
 	}
+	pinyinMap := nicknamePinyinMap(l.svcCtx, friendIDList)
 
-	// 处理朋友信息，构建FriendInfoResponse列表
-	var list []types.FriendInfoResponse
+	type sortItem struct {
+		info types.FriendInfoResponse
+		full string
+	}
+	userOnlineMap := onlineUserMap(l.svcCtx)
+	var items []sortItem
 	for _, friend := range friends {
-		info := types.FriendInfoResponse{}
-		if friend.SendUserID == req.UserID {
-			info = types.FriendInfoResponse{
-				UserID:   friend.RevUserID,
-				Nickname: friend.RevUserModel.Nickname,
-				Abstract: friend.RevUserModel.Abstract,
-				Avatar:   friend.RevUserModel.Avatar,
-				Notice:   friend.SenUserNotice,
-				IsOnline: onlineUserMap[friend.RevUserID],
-			}
+		info := friendInfo(friend, req.UserID, userOnlineMap)
+		nickname := pinyinMap[info.UserID]
+		noticeFull, noticeInitials := friendNoticePinyin(friend, req.UserID)
+		if !pinyins.Match(req.Key, info.Nickname, nickname.Pinyin, nickname.Initials) &&
+			!(info.Notice != "" && pinyins.Match(req.Key, info.Notice, noticeFull, noticeInitials)) {
+			continue
 		}
-		if friend.RevUserID == req.UserID {
-			info = types.FriendInfoResponse{
-				UserID:   friend.SendUserID,
-				Nickname: friend.SendUserModel.Nickname,
-				Abstract: friend.SendUserModel.Abstract,
-				Avatar:   friend.SendUserModel.Avatar,
-				Notice:   friend.RevUserNotice,
-				IsOnline: onlineUserMap[friend.SendUserID],
-			}
+		full := friendDisplayPinyin(friend, req.UserID, pinyinMap)
+		info.Letter = pinyins.Letter(full)
+		items = append(items, sortItem{info: info, full: full})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return pinyins.Less(items[i].full, items[j].full)
+	})
+
+	// 分页规则和ListQuery保持一致，limit为-1的时候返回全部
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 || req.Limit < -1 {
+		req.Limit = 10
+	}
+	var list = make([]types.FriendInfoResponse, 0)
+	for index, item := range items {
+		if req.Limit != -1 && (index < (req.Page-1)*req.Limit || index >= req.Page*req.Limit) {
+			continue
 		}
-		list = append(list, info)
+		list = append(list, item.info)
 	}
 
 	// 构建并返回朋友列表响应
 	return &types.FriendListResponse{
-		Count: int(count),
-		List:  list,
+		Count:   len(items),
+		List:    list,
+		Version: version,
 	}, nil
 }
+
+// friendID 好友关系中对方的用户id
+func friendID(friend user_models.FriendModel, userID uint) uint {
+	if friend.SendUserID == userID {
+		return friend.RevUserID
+	}
+	return friend.SendUserID
+}
+
+// friendNoticePinyin 从userID的视角获取给对方的备注的拼音
+func friendNoticePinyin(friend user_models.FriendModel, userID uint) (full string, initials string) {
+	notice := friend.GetUserNotice(userID)
+	full, initials = friend.RevUserNoticePinyin, friend.RevUserNoticeInitials
+	if friend.SendUserID == userID {
+		full, initials = friend.SenUserNoticePinyin, friend.SenUserNoticeInitials
+	}
+	// 备注拼音还没有补全的旧数据，现场计算
+	if notice != "" && full == "" {
+		full, initials = pinyins.Full(notice), pinyins.Initials(notice)
+	}
+	return
+}
+
+// friendDisplayPinyin 好友显示名称的全拼，有备注用备注，否则用昵称
+func friendDisplayPinyin(friend user_models.FriendModel, userID uint, pinyinMap map[uint]user_models.UserSearchIndexModel) string {
+	noticeFull, _ := friendNoticePinyin(friend, userID)
+	if noticeFull != "" {
+		return noticeFull
+	}
+	return pinyinMap[friendID(friend, userID)].Pinyin
+}
+
+// nicknamePinyinMap 批量获取用户昵称的拼音，昵称修改时已经同步到搜索索引
+func nicknamePinyinMap(svcCtx *svc.ServiceContext, userIDList []uint) map[uint]user_models.UserSearchIndexModel {
+	var pinyinMap = map[uint]user_models.UserSearchIndexModel{}
+	if len(userIDList) == 0 {
+		return pinyinMap
+	}
+	var indexList []user_models.UserSearchIndexModel
+	svcCtx.DB.Select("user_id", "nickname", "pinyin", "initials").Find(&indexList, "user_id in ?", userIDList)
+	for _, index := range indexList {
+		pinyinMap[index.UserID] = index
+	}
+	// 还没有建立搜索索引的用户，现场计算
+	for _, userID := range userIDList {
+		if _, ok := pinyinMap[userID]; ok {
+			continue
+		}
+		var user user_models.UserModel
+		if svcCtx.DB.Select("id", "nickname").Take(&user, userID).Error == nil {
+			pinyinMap[userID] = user_models.UserSearchIndexModel{
+				UserID:   userID,
+				Nickname: user.Nickname,
+				Pinyin:   pinyins.Full(user.Nickname),
+				Initials: pinyins.Initials(user.Nickname),
+			}
+		}
+	}
+	return pinyinMap
+}
+
+// onlineUserMap 从Redis获取在线用户列表
+func onlineUserMap(svcCtx *svc.ServiceContext) map[uint]bool {
+	onlineMap := svcCtx.Redis.HGetAll("online").Val()
+	var userMap = map[uint]bool{}
+	for key := range onlineMap {
+		val, err := strconv.Atoi(key)
+		if err != nil {
+			logx.Error(err)
+			continue
+		}
+		userMap[uint(val)] = true
+	}
+	return userMap
+}
+
+// friendInfo 从userID的视角组装好友信息，需要预加载双方的用户信息
+func friendInfo(friend user_models.FriendModel, userID uint, onlineUserMap map[uint]bool) types.FriendInfoResponse {
+	if friend.SendUserID == userID {
+		return types.FriendInfoResponse{
+			UserID:   friend.RevUserID,
+			Nickname: friend.RevUserModel.Nickname,
+			Abstract: friend.RevUserModel.Abstract,
+			Avatar:   friend.RevUserModel.Avatar,
+			Notice:   friend.SenUserNotice,
+			IsOnline: onlineUserMap[friend.RevUserID],
+		}
+	}
+	return types.FriendInfoResponse{
+		UserID:   friend.SendUserID,
+		Nickname: friend.SendUserModel.Nickname,
+		Abstract: friend.SendUserModel.Abstract,
+		Avatar:   friend.SendUserModel.Avatar,
+		Notice:   friend.RevUserNotice,
+		IsOnline: onlineUserMap[friend.SendUserID],
+	}
+}
+
+// contactVersion 获取用户当前的联系人版本
+func contactVersion(svcCtx *svc.ServiceContext, userID uint) (version int64) {
+	svcCtx.DB.Model(&user_models.UserConfModel{}).Where("user_id = ?", userID).Select("contact_version").Scan(&version)
+	return
+}
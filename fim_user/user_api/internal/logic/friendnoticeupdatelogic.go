@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fim/fim_user/user_models"
+	"fim/utils/pinyins"
+	"unicode/utf8"
 
 	"fim/fim_user/user_api/internal/svc"
 	"fim/fim_user/user_api/internal/types"
@@ -36,6 +38,9 @@ func NewFriendNoticeUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 //	FriendNoticeUpdateResponse: 更新后的响应数据，如果未更新则可能为空。
 //	error: 如果操作失败，返回错误信息。
 func (l *FriendNoticeUpdateLogic) FriendNoticeUpdate(req *types.FriendNoticeUpdateRequest) (resp *types.FriendNoticeUpdateResponse, err error) {
+	if utf8.RuneCountInString(req.Notice) > 128 {
+		return nil, errors.New("备注不能超过128个字")
+	}
 	// 加载好友信息以验证用户关系并进行后续更新。
 	var friend user_models.FriendModel
 	// 验证请求中的用户是否为好友关系。
@@ -50,7 +55,15 @@ func (l *FriendNoticeUpdateLogic) FriendNoticeUpdate(req *types.FriendNoticeUpda
 			return
 		}
 		// 更新发送方的通知设置。
-		l.svcCtx.DB.Model(&friend).Update("sen_user_notice", req.Notice)
+		err = l.svcCtx.DB.Model(&friend).Updates(map[string]any{
+			"sen_user_notice":          req.Notice,
+			"sen_user_notice_pinyin":   pinyins.Full(req.Notice),
+			"sen_user_notice_initials": pinyins.Initials(req.Notice),
+		}).Error
+		if err != nil {
+			logx.Error(err)
+			return nil, errors.New("备注修改失败")
+		}
 	}
 	// 如果是接收方更新通知设置。
 	if friend.RevUserID == req.UserID {
@@ -59,8 +72,21 @@ func (l *FriendNoticeUpdateLogic) FriendNoticeUpdate(req *types.FriendNoticeUpda
 			return
 		}
 		// 更新接收方的通知设置。
-		l.svcCtx.DB.Model(&friend).Update("rev_user_notice", req.Notice)
+		err = l.svcCtx.DB.Model(&friend).Updates(map[string]any{
+			"rev_user_notice":          req.Notice,
+			"rev_user_notice_pinyin":   pinyins.Full(req.Notice),
+			"rev_user_notice_initials": pinyins.Initials(req.Notice),
+		}).Error
+		if err != nil {
+			logx.Error(err)
+			return nil, errors.New("备注修改失败")
+		}
+	}
+	// 备注只影响自己的联系人列表。
+	err = user_models.BumpContactVersion(l.svcCtx.DB, req.UserID, req.FriendID, user_models.ContactUpdate)
+	if err != nil {
+		logx.Error(err)
 	}
 	// 返回更新后的响应，如果未更新则响应可能为空。
-	return
+	return resp, nil
 }
@@ -0,0 +1,48 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/fim_user/user_models"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type FriendRecommendDismissLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendRecommendDismissLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendRecommendDismissLogic {
+	return &FriendRecommendDismissLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// FriendRecommendDismiss 忽略推荐的用户，之后不会再推荐这个人
+func (l *FriendRecommendDismissLogic) FriendRecommendDismiss(req *types.FriendRecommendDismissRequest) (resp *types.FriendRecommendDismissResponse, err error) {
+	if req.DismissUserID == 0 || req.DismissUserID == req.UserID {
+		return nil, errors.New("用户错误")
+	}
+	var dismiss user_models.FriendRecommendDismissModel
+	err = l.svcCtx.DB.Take(&dismiss, "user_id = ? and dismiss_user_id = ?", req.UserID, req.DismissUserID).Error
+	if err == nil {
+		// 已经忽略过了
+		return
+	}
+	err = l.svcCtx.DB.Create(&user_models.FriendRecommendDismissModel{
+		UserID:        req.UserID,
+		DismissUserID: req.DismissUserID,
+	}).Error
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("操作失败")
+	}
+	return
+}
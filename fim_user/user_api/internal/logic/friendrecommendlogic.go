@@ -0,0 +1,161 @@
+package logic
+
+import (
+	"context"
+	"fim/fim_group/group_models"
+	"fim/fim_user/user_models"
+	"sort"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type FriendRecommendLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendRecommendLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendRecommendLogic {
+	return &FriendRecommendLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// FriendRecommend 可能认识的人
+// 从好友的好友和共同群聊的群成员中找出还不是好友的用户，按共同好友数、共同群聊数排序。
+// 不允许被搜索的用户、存在拉黑关系的用户以及被忽略过的用户不会被推荐。
+func (l *FriendRecommendLogic) FriendRecommend(req *types.FriendRecommendRequest) (resp *types.FriendRecommendResponse, err error) {
+	// 自己的好友，推荐时需要排除
+	var friend user_models.FriendModel
+	var friendMap = map[uint]bool{}
+	var friendIDList []uint
+	for _, model := range friend.Friends(l.svcCtx.DB, req.UserID) {
+		friendID := model.SendUserID
+		if friendID == req.UserID {
+			friendID = model.RevUserID
+		}
+		friendMap[friendID] = true
+		friendIDList = append(friendIDList, friendID)
+	}
+
+	// 存在拉黑关系的用户和忽略过的用户
+	var block user_models.UserBlockModel
+	var excludeMap = map[uint]bool{req.UserID: true}
+	for _, u := range block.BlockUserIDList(l.svcCtx.DB, req.UserID) {
+		excludeMap[u] = true
+	}
+	var dismissIDList []uint
+	l.svcCtx.DB.Model(&user_models.FriendRecommendDismissModel{}).
+		Where("user_id = ?", req.UserID).
+		Select("dismiss_user_id").Scan(&dismissIDList)
+	for _, u := range dismissIDList {
+		excludeMap[u] = true
+	}
+
+	var mutualMap = map[uint]int{}
+	// 好友的好友，每条好友关系记录代表一个共同好友
+	if len(friendIDList) > 0 {
+		var friendOfFriendList []user_models.FriendModel
+		l.svcCtx.DB.Find(&friendOfFriendList, "send_user_id in ? or rev_user_id in ?", friendIDList, friendIDList)
+		for _, model := range friendOfFriendList {
+			if friendMap[model.SendUserID] {
+				mutualMap[model.RevUserID]++
+			}
+			if friendMap[model.RevUserID] {
+				mutualMap[model.SendUserID]++
+			}
+		}
+	}
+
+	// 共同群聊的群成员
+	type SharedGroup struct {
+		UserID uint `gorm:"column:user_id"`
+		Count  int  `gorm:"column:count"`
+	}
+	var sharedGroupList []SharedGroup
+	l.svcCtx.DB.Model(&group_models.GroupMemberModel{}).
+		Joins("join group_member_models me on me.group_id = group_member_models.group_id").
+		Where("me.user_id = ? and group_member_models.user_id <> ?", req.UserID, req.UserID).
+		Group("group_member_models.user_id").
+		Select("group_member_models.user_id as user_id", "count(*) as count").
+		Scan(&sharedGroupList)
+	var sharedMap = map[uint]int{}
+	for _, group := range sharedGroupList {
+		sharedMap[group.UserID] = group.Count
+	}
+
+	// 汇总候选人
+	var candidateIDList []uint
+	for u := range mutualMap {
+		if friendMap[u] || excludeMap[u] {
+			continue
+		}
+		candidateIDList = append(candidateIDList, u)
+	}
+	for u := range sharedMap {
+		if friendMap[u] || excludeMap[u] {
+			continue
+		}
+		if _, ok := mutualMap[u]; ok {
+			continue
+		}
+		candidateIDList = append(candidateIDList, u)
+	}
+
+	list := make([]types.FriendRecommendInfo, 0)
+	if len(candidateIDList) > 0 {
+		// 不允许别人查找的用户不推荐
+		var userList []user_models.UserModel
+		l.svcCtx.DB.Joins("join user_conf_models uc on uc.user_id = user_models.id").
+			Where("user_models.id in ? and uc.search_user <> 0 and user_models.status = 0", candidateIDList).
+			Find(&userList)
+		for _, user := range userList {
+			list = append(list, types.FriendRecommendInfo{
+				UserID:            user.ID,
+				Nickname:          user.Nickname,
+				Abstract:          user.Abstract,
+				Avatar:            user.Avatar,
+				MutualFriendCount: mutualMap[user.ID],
+				SharedGroupCount:  sharedMap[user.ID],
+			})
+		}
+	}
+
+	// 共同好友多的排前面，其次是共同群聊多的
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].MutualFriendCount != list[j].MutualFriendCount {
+			return list[i].MutualFriendCount > list[j].MutualFriendCount
+		}
+		if list[i].SharedGroupCount != list[j].SharedGroupCount {
+			return list[i].SharedGroupCount > list[j].SharedGroupCount
+		}
+		return list[i].UserID < list[j].UserID
+	})
+
+	// 分页
+	count := len(list)
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	offset := (req.Page - 1) * req.Limit
+	if offset > count {
+		offset = count
+	}
+	end := offset + req.Limit
+	if end > count {
+		end = count
+	}
+
+	return &types.FriendRecommendResponse{
+		List:  list[offset:end],
+		Count: count,
+	}, nil
+}
@@ -0,0 +1,102 @@
+package logic
+
+import (
+	"context"
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+	"fim/fim_user/user_models"
+	"fim/utils/pinyins"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type FriendSyncLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewFriendSyncLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FriendSyncLogic {
+	return &FriendSyncLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// FriendSync 增量同步好友列表
+// 客户端带上本地的联系人版本，返回这个版本之后新增、修改备注和删除的好友
+// 版本为0或者比服务端还新的时候（比如服务端数据迁移过），返回全量的好友列表
+func (l *FriendSyncLogic) FriendSync(req *types.FriendSyncRequest) (resp *types.FriendSyncResponse, err error) {
+	version := contactVersion(l.svcCtx, req.UserID)
+	resp = &types.FriendSyncResponse{
+		Version: version,
+		Add:     make([]types.FriendInfoResponse, 0),
+		Update:  make([]types.FriendInfoResponse, 0),
+		Remove:  make([]uint, 0),
+	}
+	if req.Version == version {
+		return resp, nil
+	}
+
+	userOnlineMap := onlineUserMap(l.svcCtx)
+	if req.Version <= 0 || req.Version > version {
+		var friends []user_models.FriendModel
+		l.svcCtx.DB.Preload("SendUserModel").Preload("RevUserModel").
+			Find(&friends, "send_user_id = ? or rev_user_id = ?", req.UserID, req.UserID)
+		pinyinMap := l.nicknamePinyinMap(friends, req.UserID)
+		for _, model := range friends {
+			info := friendInfo(model, req.UserID, userOnlineMap)
+			info.Letter = pinyins.Letter(friendDisplayPinyin(model, req.UserID, pinyinMap))
+			resp.Add = append(resp.Add, info)
+		}
+		resp.Full = true
+		return resp, nil
+	}
+
+	// 客户端版本之后的变更记录
+	var changeList []user_models.ContactChangeModel
+	err = l.svcCtx.DB.Find(&changeList, "user_id = ? and version > ? and version <= ?", req.UserID, req.Version, version).Error
+	if err != nil {
+		logx.Error(err)
+		return nil, err
+	}
+	var friendIDList []uint
+	var addMap = map[uint]bool{}
+	for _, change := range changeList {
+		if change.IsRemoved {
+			resp.Remove = append(resp.Remove, change.FriendID)
+			continue
+		}
+		friendIDList = append(friendIDList, change.FriendID)
+		addMap[change.FriendID] = change.AddVersion > req.Version
+	}
+	if len(friendIDList) == 0 {
+		return resp, nil
+	}
+
+	var friends []user_models.FriendModel
+	l.svcCtx.DB.Preload("SendUserModel").Preload("RevUserModel").
+		Find(&friends, "(send_user_id = ? and rev_user_id in ?) or (rev_user_id = ? and send_user_id in ?)",
+			req.UserID, friendIDList, req.UserID, friendIDList)
+	pinyinMap := l.nicknamePinyinMap(friends, req.UserID)
+	for _, model := range friends {
+		info := friendInfo(model, req.UserID, userOnlineMap)
+		info.Letter = pinyins.Letter(friendDisplayPinyin(model, req.UserID, pinyinMap))
+		if addMap[info.UserID] {
+			resp.Add = append(resp.Add, info)
+			continue
+		}
+		resp.Update = append(resp.Update, info)
+	}
+	return resp, nil
+}
+
+// nicknamePinyinMap 批量获取好友昵称的拼音，用于计算索引字母
+func (l *FriendSyncLogic) nicknamePinyinMap(friends []user_models.FriendModel, userID uint) map[uint]user_models.UserSearchIndexModel {
+	var friendIDList []uint
+	for _, model := range friends {
+		friendIDList = append(friendIDList, friendID(model, userID))
+	}
+	return nicknamePinyinMap(l.svcCtx, friendIDList)
+}
@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"context"
+	"fim/common/list_query"
+	"fim/common/models"
+	"fim/fim_user/user_models"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type LoginLogLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewLoginLogLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LoginLogLogic {
+	return &LoginLogLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// LoginLog 自己的登录记录，按登录时间倒序
+func (l *LoginLogLogic) LoginLog(req *types.LoginLogRequest) (resp *types.LoginLogResponse, err error) {
+	logs, count, _ := list_query.ListQuery(l.svcCtx.DB, user_models.LoginLogModel{}, list_query.Option{
+		PageInfo: models.PagaInfo{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Sort:  "created_at desc",
+		},
+		Where: l.svcCtx.DB.Where("user_id = ?", req.UserID),
+	})
+
+	var list = make([]types.LoginLogInfo, 0)
+	for _, log := range logs {
+		list = append(list, types.LoginLogInfo{
+			IP:        log.IP,
+			Addr:      log.Addr,
+			Source:    log.Source,
+			UserAgent: log.UserAgent,
+			CreatedAt: log.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return &types.LoginLogResponse{
+		List:  list,
+		Count: count,
+	}, nil
+}
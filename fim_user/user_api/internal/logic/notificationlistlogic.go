@@ -0,0 +1,84 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/common/list_query"
+	"fim/common/models"
+	"fim/common/service/redis_service"
+	"fim/fim_user/user_models"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type NotificationListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewNotificationListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationListLogic {
+	return &NotificationListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// NotificationList 通知列表，按时间倒序，可以按类型和未读筛选
+func (l *NotificationListLogic) NotificationList(req *types.NotificationListRequest) (resp *types.NotificationListResponse, err error) {
+	if req.Type != 0 && !user_models.IsValidNotifyType(req.Type) {
+		return nil, errors.New("通知类型错误")
+	}
+	query := l.svcCtx.DB.Where("user_id = ?", req.UserID)
+	if req.Type != 0 {
+		query.Where("type = ?", req.Type)
+	}
+	if req.Unread {
+		query.Where("is_read = ?", false)
+	}
+	notifications, count, _ := list_query.ListQuery(l.svcCtx.DB, user_models.NotificationModel{}, list_query.Option{
+		PageInfo: models.PagaInfo{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Sort:  "created_at desc",
+		},
+		Where: query,
+	})
+
+	var userIDList []uint
+	for _, notification := range notifications {
+		if notification.SendUserID != 0 {
+			userIDList = append(userIDList, notification.SendUserID)
+		}
+	}
+	userInfoMap, err := redis_service.GetUserListInfo(l.svcCtx.Redis, l.svcCtx.UserRpc, userIDList)
+	if err != nil {
+		logx.Error(err)
+	}
+
+	var list = make([]types.NotificationInfo, 0)
+	for _, notification := range notifications {
+		userInfo := userInfoMap[notification.SendUserID]
+		list = append(list, types.NotificationInfo{
+			ID:         notification.ID,
+			Type:       notification.Type,
+			SourceID:   notification.SourceID,
+			SendUserID: notification.SendUserID,
+			Nickname:   userInfo.NickName,
+			Avatar:     userInfo.Avatar,
+			GroupID:    notification.GroupID,
+			Title:      notification.Title,
+			Content:    notification.Content,
+			IsRead:     notification.IsRead,
+			CreatedAt:  notification.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return &types.NotificationListResponse{
+		List:  list,
+		Count: count,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/fim_user/user_models"
+	"time"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type NotificationReadLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewNotificationReadLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationReadLogic {
+	return &NotificationReadLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// NotificationRead 把通知标记为已读，可以按id、按类型或者全部标记
+func (l *NotificationReadLogic) NotificationRead(req *types.NotificationReadRequest) (resp *types.NotificationReadResponse, err error) {
+	if req.Type != 0 && !user_models.IsValidNotifyType(req.Type) {
+		return nil, errors.New("通知类型错误")
+	}
+	query := l.svcCtx.DB.Model(&user_models.NotificationModel{}).
+		Where("user_id = ? and is_read = ?", req.UserID, false)
+	if len(req.IDList) > 0 {
+		query.Where("id in ?", req.IDList)
+	}
+	if req.Type != 0 {
+		query.Where("type = ?", req.Type)
+	}
+	err = query.Updates(map[string]any{
+		"is_read": true,
+		"read_at": time.Now(),
+	}).Error
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("操作失败")
+	}
+	return
+}
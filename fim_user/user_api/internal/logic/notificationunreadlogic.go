@@ -0,0 +1,51 @@
+package logic
+
+import (
+	"context"
+	"fim/fim_user/user_models"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type NotificationUnreadLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewNotificationUnreadLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationUnreadLogic {
+	return &NotificationUnreadLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// NotificationUnread 未读通知数，按好友、群和系统三类分别统计
+func (l *NotificationUnreadLogic) NotificationUnread(req *types.NotificationUnreadRequest) (resp *types.NotificationUnreadResponse, err error) {
+	type typeCount struct {
+		Type  int8
+		Count int64
+	}
+	var countList []typeCount
+	l.svcCtx.DB.Model(&user_models.NotificationModel{}).
+		Where("user_id = ? and is_read = ?", req.UserID, false).
+		Group("type").Select("type, count(id) as count").Scan(&countList)
+
+	resp = new(types.NotificationUnreadResponse)
+	for _, item := range countList {
+		resp.Total += item.Count
+		switch item.Type {
+		case user_models.NotifyFriendVerify, user_models.NotifyFriendVerifyResult:
+			resp.Friend += item.Count
+		case user_models.NotifyGroupVerify, user_models.NotifyGroupVerifyResult:
+			resp.Group += item.Count
+		case user_models.NotifySystem:
+			resp.System += item.Count
+		}
+	}
+	return
+}
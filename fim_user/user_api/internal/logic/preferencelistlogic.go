@@ -0,0 +1,68 @@
+package logic
+
+import (
+	"context"
+	"fim/fim_user/user_models"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type PreferenceListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPreferenceListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PreferenceListLogic {
+	return &PreferenceListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PreferenceList 获取偏好设置，客户端带上本地的版本时只返回之后变化的配置项
+// 客户端没有版本或者版本比服务端还新（比如数据被清理过）的时候返回全量数据
+func (l *PreferenceListLogic) PreferenceList(req *types.PreferenceListRequest) (resp *types.PreferenceListResponse, err error) {
+	var conf user_models.UserConfModel
+	l.svcCtx.DB.Take(&conf, "user_id = ?", req.UserID)
+
+	resp = &types.PreferenceListResponse{
+		Version: conf.PreferenceVersion,
+		Full:    req.Version <= 0 || req.Version > conf.PreferenceVersion,
+		List:    make([]types.PreferenceItem, 0),
+	}
+	if !resp.Full && req.Version == conf.PreferenceVersion {
+		return
+	}
+
+	query := l.svcCtx.DB.Where("user_id = ?", req.UserID)
+	if req.Namespace != "" {
+		query.Where("namespace = ?", req.Namespace)
+	}
+	if resp.Full {
+		query.Where("is_removed = ?", false)
+	} else {
+		query.Where("version > ?", req.Version)
+	}
+	var preferenceList []user_models.UserPreferenceModel
+	query.Order("version asc").Find(&preferenceList)
+	for _, preference := range preferenceList {
+		resp.List = append(resp.List, preferenceItem(preference))
+	}
+	return
+}
+
+// preferenceItem 转换成接口返回的配置项
+func preferenceItem(preference user_models.UserPreferenceModel) types.PreferenceItem {
+	return types.PreferenceItem{
+		Namespace: preference.Namespace,
+		Key:       preference.Key,
+		Value:     preference.Value,
+		Version:   preference.Version,
+		IsRemoved: preference.IsRemoved,
+	}
+}
@@ -0,0 +1,97 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/common/models/ctype"
+	"fim/common/service/redis_service"
+	"fim/fim_user/user_models"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type PreferenceUpdateLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPreferenceUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PreferenceUpdateLogic {
+	return &PreferenceUpdateLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PreferenceUpdate 修改一个命名空间下的偏好设置，修改成功后推送给用户其他在线的设备
+// 客户端带上本地版本的时候做冲突检测，冲突时不做修改，返回冲突配置项的当前值由客户端合并后重新提交
+func (l *PreferenceUpdateLogic) PreferenceUpdate(req *types.PreferenceUpdateRequest) (resp *types.PreferenceUpdateResponse, err error) {
+	err = user_models.ValidPreferenceNamespace(req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Set) == 0 && len(req.Remove) == 0 {
+		return nil, errors.New("没有要修改的配置")
+	}
+	if len(req.Set)+len(req.Remove) > user_models.PreferenceMaxKeys {
+		return nil, user_models.ErrPreferenceTooMany
+	}
+	for key, value := range req.Set {
+		err = user_models.ValidPreferenceKey(key, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range req.Remove {
+		err = user_models.ValidPreferenceKey(key, "")
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := req.Set[key]; ok {
+			return nil, errors.New("同一个配置项不能同时修改和删除")
+		}
+	}
+
+	version, conflictList, err := user_models.PatchPreference(l.svcCtx.DB, req.UserID, req.Namespace, req.Version, req.Set, req.Remove)
+	if errors.Is(err, user_models.ErrPreferenceConflict) {
+		var conf user_models.UserConfModel
+		l.svcCtx.DB.Take(&conf, "user_id = ?", req.UserID)
+		resp = &types.PreferenceUpdateResponse{
+			Version:      conf.PreferenceVersion,
+			Conflict:     true,
+			ConflictList: make([]types.PreferenceItem, 0),
+		}
+		for _, preference := range conflictList {
+			resp.ConflictList = append(resp.ConflictList, preferenceItem(preference))
+		}
+		return resp, nil
+	}
+	if errors.Is(err, user_models.ErrPreferenceTooMany) {
+		return nil, err
+	}
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("修改偏好设置失败")
+	}
+
+	msg := ctype.PreferenceChangeMsg{
+		Namespace: req.Namespace,
+		Version:   version,
+	}
+	for key, value := range req.Set {
+		msg.Items = append(msg.Items, ctype.PreferenceItem{Key: key, Value: value})
+	}
+	for _, key := range req.Remove {
+		msg.Items = append(msg.Items, ctype.PreferenceItem{Key: key, IsRemoved: true})
+	}
+	redis_service.PublishPreferenceChange(l.svcCtx.Redis, req.UserID, msg)
+
+	return &types.PreferenceUpdateResponse{
+		Version:      version,
+		ConflictList: make([]types.PreferenceItem, 0),
+	}, nil
+}
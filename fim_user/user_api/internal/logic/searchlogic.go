@@ -2,15 +2,19 @@ package logic
 
 import (
 	"context"
-	"fim/common/list_query"
-	"fim/common/models"
+	"fim/common/models/ctype"
 	"fim/fim_user/user_models"
+	"fim/utils/handles"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"fim/fim_user/user_api/internal/svc"
 	"fim/fim_user/user_api/internal/types"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type SearchLogic struct {
@@ -27,19 +31,107 @@ func NewSearchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SearchLogi
 	}
 }
 
+// searchUser 搜索结果
+type searchUser struct {
+	UserID            uint
+	Nickname          string
+	Handle            *string
+	Abstract          string
+	Avatar            string
+	Gender            int8
+	Birthday          string
+	Region            string
+	Signature         string
+	CoverImage        string
+	Addr              string
+	ProfileVisibility *ctype.ProfileVisibility
+}
+
+// likeEscape 转义like语句中的通配符
+var likeEscape = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Search 搜索用户
+// 排序规则：用户id、用户号精确匹配 > 昵称精确匹配 > 昵称、拼音、首字母前缀匹配 > 昵称、简介全文匹配
+// 用户配置 search_user：0 不能被搜索到 1 只能通过用户id和用户号搜索到 2 可以通过用户id、用户号和昵称搜索到
 func (l *SearchLogic) Search(req *types.SearchRequest) (resp *types.SearchResponse, err error) {
-	//查询用户信息，数量，是否在线
-	users, count, err := list_query.ListQuery(l.svcCtx.DB, user_models.UserConfModel{
-		Online: req.Online,
-	}, list_query.Option{
-		PageInfo: models.PagaInfo{
-			Page:  req.Page,
-			Limit: req.Limit,
-		},
-		Preload: []string{"UserModel"},
-		Joins:   "left join user_models um on um.id=user_conf.user_id",
-		Where:   l.svcCtx.DB.Where("(user_conf_model.search_user <> 0 or user_conf_model.search_user is null) and (user_conf_model.search_user=1 and um.id=?)or (user_conf_model.search_user=2 and(um.id=? or um.nickname like ? ))", req.Key, req.Key, fmt.Sprintf("%%%s%%")),
-	})
+	resp = &types.SearchResponse{List: make([]types.SearchInfo, 0)}
+	key := strings.TrimSpace(req.Key)
+	if key == "" {
+		return resp, nil
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+
+	// key是数字的时候才按用户id匹配，0不对应任何用户
+	var keyUserID uint
+	id, err1 := strconv.ParseUint(key, 10, 64)
+	if err1 == nil {
+		keyUserID = uint(id)
+	}
+	// 用户号不区分大小写
+	keyHandle := handles.Normalize(key)
+	nicknamePrefix := likeEscape.Replace(key) + "%"
+	prefix := likeEscape.Replace(strings.ToLower(key)) + "%"
+	// 全文检索使用短语匹配，去掉布尔模式下的双引号
+	against := fmt.Sprintf(`"%s"`, strings.ReplaceAll(key, `"`, ""))
+
+	query := l.svcCtx.DB.Table("user_search_index_models idx").
+		Joins("join user_conf_models uc on uc.user_id = idx.user_id").
+		Joins("join user_models u on u.id = idx.user_id and u.status = 0").
+		Where("(uc.search_user in (1, 2) and (idx.user_id = ? or u.handle = ?)) or (uc.search_user = 2 and (idx.nickname = ? or idx.nickname like ? or idx.pinyin like ? or idx.initials like ? or match(idx.keywords) against (? in boolean mode)))",
+			keyUserID, keyHandle, key, nicknamePrefix, prefix, prefix, against)
+
+	// 和自己存在拉黑关系的用户不出现在搜索结果中
+	var block user_models.UserBlockModel
+	blockUserIDList := block.BlockUserIDList(l.svcCtx.DB, req.UserID)
+	if len(blockUserIDList) > 0 {
+		query = query.Where("idx.user_id not in ?", blockUserIDList)
+	}
+
+	// 只搜索在线的用户
+	if req.Online {
+		onlineMap := l.svcCtx.Redis.HGetAll("online").Val()
+		var onlineUserIDList []uint
+		for k := range onlineMap {
+			val, err1 := strconv.Atoi(k)
+			if err1 != nil {
+				logx.Error(err1)
+				continue
+			}
+			onlineUserIDList = append(onlineUserIDList, uint(val))
+		}
+		if len(onlineUserIDList) == 0 {
+			return resp, nil
+		}
+		query = query.Where("idx.user_id in ?", onlineUserIDList)
+	}
+	query = query.Session(&gorm.Session{})
+
+	err = query.Count(&resp.Count).Error
+	if err != nil {
+		logx.Error(err)
+		return nil, err
+	}
+
+	var users []searchUser
+	err = query.Select("idx.user_id, u.nickname, u.handle, u.abstract, u.avatar, u.gender, u.birthday, u.region, u.signature, u.cover_image, u.addr, uc.profile_visibility").
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "case when idx.user_id = ? or u.handle = ? then 0 when idx.nickname = ? then 1 when idx.nickname like ? or idx.pinyin like ? or idx.initials like ? then 2 else 3 end, match(idx.keywords) against (? in boolean mode) desc, idx.user_id",
+			Vars:               []any{keyUserID, keyHandle, key, nicknamePrefix, prefix, prefix, against},
+			WithoutParentheses: true,
+		}}).
+		Offset((req.Page - 1) * req.Limit).
+		Limit(req.Limit).
+		Scan(&users).Error
+	if err != nil {
+		logx.Error(err)
+		return nil, err
+	}
+
 	var friend user_models.FriendModel
 	//查询好友关系
 	friends := friend.Friends(l.svcCtx.DB, req.UserID)
@@ -51,16 +143,40 @@ func (l *SearchLogic) Search(req *types.SearchRequest) (resp *types.SearchRespon
 			userMap[model.SendUserID] = true
 		}
 	}
-	list := make([]types.SearchInfo, 0)
 	//组装返回数据
-	for _, uc := range users {
-		list = append(list, types.SearchInfo{
-			UserID:   uc.UserID,
-			Nickname: uc.UserModel.Nickname,
-			Abstract: uc.UserModel.Abstract,
-			Avatar:   uc.UserModel.Avatar,
-			IsFriend: userMap[uc.UserID],
+	for _, row := range users {
+		// 扩展资料按对方设置的可见范围展示
+		user := user_models.UserModel{
+			Nickname:      row.Nickname,
+			Abstract:      row.Abstract,
+			Avatar:        row.Avatar,
+			Gender:        row.Gender,
+			Birthday:      row.Birthday,
+			Region:        row.Region,
+			Signature:     row.Signature,
+			CoverImage:    row.CoverImage,
+			Addr:          row.Addr,
+			UserConfModel: &user_models.UserConfModel{ProfileVisibility: row.ProfileVisibility},
+		}
+		user.FilterProfile(userMap[row.UserID])
+		var handle string
+		if row.Handle != nil {
+			handle = *row.Handle
+		}
+		resp.List = append(resp.List, types.SearchInfo{
+			UserID:     row.UserID,
+			Nickname:   user.Nickname,
+			Handle:     handle,
+			Abstract:   user.Abstract,
+			Avatar:     user.Avatar,
+			IsFriend:   userMap[row.UserID],
+			Gender:     user.Gender,
+			Birthday:   user.Birthday,
+			Region:     user.Region,
+			Signature:  user.Signature,
+			CoverImage: user.CoverImage,
+			Addr:       user.Addr,
 		})
 	}
-	return &types.SearchResponse{List: list, Count: count}, nil
+	return resp, nil
 }
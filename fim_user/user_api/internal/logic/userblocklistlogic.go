@@ -0,0 +1,54 @@
+package logic
+
+import (
+	"context"
+	"fim/common/list_query"
+	"fim/common/models"
+	"fim/fim_user/user_models"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UserBlockListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserBlockListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserBlockListLogic {
+	return &UserBlockListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserBlockList 获取自己的黑名单列表，按拉黑时间倒序
+func (l *UserBlockListLogic) UserBlockList(req *types.UserBlockListRequest) (resp *types.UserBlockListResponse, err error) {
+	blocks, count, _ := list_query.ListQuery(l.svcCtx.DB, user_models.UserBlockModel{}, list_query.Option{
+		PageInfo: models.PagaInfo{
+			Page:  req.Page,
+			Limit: req.Limit,
+			Sort:  "created_at desc",
+		},
+		Where:   l.svcCtx.DB.Where("user_id = ?", req.UserID),
+		Preload: []string{"BlockUserModel"},
+	})
+
+	var list = make([]types.UserBlockInfo, 0)
+	for _, block := range blocks {
+		list = append(list, types.UserBlockInfo{
+			UserID:    block.BlockUserID,
+			Nickname:  block.BlockUserModel.Nickname,
+			Avatar:    block.BlockUserModel.Avatar,
+			CreatedAt: block.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+	return &types.UserBlockListResponse{
+		List:  list,
+		Count: count,
+	}, nil
+}
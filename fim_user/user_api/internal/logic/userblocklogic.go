@@ -0,0 +1,63 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/fim_user/user_models"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UserBlockLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserBlockLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserBlockLogic {
+	return &UserBlockLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserBlock 将用户加入黑名单。
+// 拉黑之后双方不能再互相发送消息和好友请求，也不会再收到对方的上线提醒，搜索时互相不可见。
+// 拉黑不会删除好友关系，移出黑名单之后可以继续聊天。
+func (l *UserBlockLogic) UserBlock(req *types.UserBlockRequest) (resp *types.UserBlockResponse, err error) {
+	if req.UserID == req.BlockUserID {
+		return nil, errors.New("不能拉黑自己")
+	}
+	// 检查被拉黑的用户是否存在
+	var user user_models.UserModel
+	err = l.svcCtx.DB.Take(&user, req.BlockUserID).Error
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	// 已经拉黑过的不重复添加
+	var block user_models.UserBlockModel
+	if block.IsBlockBy(l.svcCtx.DB, req.UserID, req.BlockUserID) {
+		return nil, errors.New("该用户已在黑名单中")
+	}
+	err = l.svcCtx.DB.Create(&user_models.UserBlockModel{
+		UserID:      req.UserID,
+		BlockUserID: req.BlockUserID,
+	}).Error
+	if err != nil {
+		// 并发拉黑同一个人时唯一索引冲突，按已拉黑处理
+		if block.IsBlockBy(l.svcCtx.DB, req.UserID, req.BlockUserID) {
+			return nil, errors.New("该用户已在黑名单中")
+		}
+		logx.Error(err)
+		return nil, errors.New("拉黑失败")
+	}
+	// 对方发来的未处理的好友验证直接置为拒绝
+	l.svcCtx.DB.Model(&user_models.FriendVerifyModel{}).
+		Where("send_user_id = ? and rev_user_id = ? and rev_status = 0", req.BlockUserID, req.UserID).
+		Update("rev_status", 2)
+	return
+}
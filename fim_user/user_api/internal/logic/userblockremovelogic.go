@@ -0,0 +1,36 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/fim_user/user_models"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UserBlockRemoveLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserBlockRemoveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserBlockRemoveLogic {
+	return &UserBlockRemoveLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserBlockRemove 将用户移出黑名单，只能移除自己拉黑的用户
+func (l *UserBlockRemoveLogic) UserBlockRemove(req *types.UserBlockRemoveRequest) (resp *types.UserBlockRemoveResponse, err error) {
+	var block user_models.UserBlockModel
+	if !block.IsBlockBy(l.svcCtx.DB, req.UserID, req.BlockUserID) {
+		return nil, errors.New("该用户不在黑名单中")
+	}
+	l.svcCtx.DB.Delete(&block)
+	return
+}
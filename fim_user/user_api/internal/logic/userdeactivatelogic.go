@@ -0,0 +1,55 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/common/service/redis_service"
+	"fim/fim_user/user_models"
+	"fmt"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UserDeactivateLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserDeactivateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserDeactivateLogic {
+	return &UserDeactivateLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserDeactivate 停用账号
+// 停用后资料对其他人隐藏，不能登录，可以通过恢复账号接口重新启用
+func (l *UserDeactivateLogic) UserDeactivate(req *types.UserDeactivateRequest) (resp *types.UserDeactivateResponse, err error) {
+	var user user_models.UserModel
+	err = l.svcCtx.DB.Take(&user, req.UserID).Error
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if user.Status != 0 {
+		return nil, errors.New("账号已停用或正在注销")
+	}
+	err = l.svcCtx.DB.Model(&user).Update("status", 1).Error
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("账号停用失败")
+	}
+	offlineUser(l.svcCtx, req.UserID)
+	return
+}
+
+// offlineUser 让账号的所有登录失效，并清除在线状态和用户信息缓存
+func offlineUser(svcCtx *svc.ServiceContext, userID uint) {
+	redis_service.SetLogout(svcCtx.Redis, userID)
+	svcCtx.Redis.HDel("online", fmt.Sprintf("%d", userID))
+	redis_service.PublishUserInfoChange(svcCtx.Redis, userID)
+}
@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/fim_user/user_models"
+	"time"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UserDestroyLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserDestroyLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserDestroyLogic {
+	return &UserDestroyLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserDestroy 申请注销账号
+// 账号进入注销中状态，宽限期内可以通过恢复账号撤销，宽限期结束后由定时任务清理数据
+func (l *UserDestroyLogic) UserDestroy(req *types.UserDestroyRequest) (resp *types.UserDestroyResponse, err error) {
+	var user user_models.UserModel
+	err = l.svcCtx.DB.Take(&user, req.UserID).Error
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if user.Status == 2 {
+		return nil, errors.New("账号已在注销中")
+	}
+	deleteAt := time.Now().AddDate(0, 0, l.svcCtx.Config.Account.DestroyGraceDays)
+	err = l.svcCtx.DB.Model(&user).Updates(map[string]any{
+		"status":    2,
+		"delete_at": deleteAt,
+	}).Error
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("账号注销失败")
+	}
+	offlineUser(l.svcCtx, req.UserID)
+	return &types.UserDestroyResponse{
+		DeleteAt: deleteAt.Format("2006-01-02 15:04:05"),
+	}, nil
+}
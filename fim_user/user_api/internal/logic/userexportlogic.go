@@ -0,0 +1,127 @@
+package logic
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fim/fim_chat/chat_models"
+	"fim/fim_group/group_models"
+	"fim/fim_user/user_models"
+
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UserExportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserExportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserExportLogic {
+	return &UserExportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// exportContact 导出的联系人
+type exportContact struct {
+	UserID   uint   `json:"userID"`
+	Nickname string `json:"nickname"`
+	Notice   string `json:"notice"` // 备注
+}
+
+// exportGroup 导出的群聊
+type exportGroup struct {
+	GroupID        uint   `json:"groupID"`
+	Title          string `json:"title"`
+	MemberNickname string `json:"memberNickname"` // 群昵称
+	Role           int8   `json:"role"`           // 1 群主 2 管理员  3 普通成员
+}
+
+// UserExport 导出个人数据，包括资料、联系人、群聊和聊天记录，打包成zip返回
+func (l *UserExportLogic) UserExport(req *types.UserExportRequest) (data []byte, err error) {
+	var user user_models.UserModel
+	err = l.svcCtx.DB.Preload("UserConfModel").Take(&user, req.UserID).Error
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	// 联系人
+	var friends []user_models.FriendModel
+	l.svcCtx.DB.Preload("SendUserModel").Preload("RevUserModel").
+		Find(&friends, "send_user_id = ? or rev_user_id = ?", req.UserID, req.UserID)
+	contacts := make([]exportContact, 0)
+	for _, friend := range friends {
+		contact := exportContact{
+			UserID:   friend.SendUserID,
+			Nickname: friend.SendUserModel.Nickname,
+			Notice:   friend.GetUserNotice(friend.SendUserID),
+		}
+		if friend.SendUserID == req.UserID {
+			contact = exportContact{
+				UserID:   friend.RevUserID,
+				Nickname: friend.RevUserModel.Nickname,
+				Notice:   friend.GetUserNotice(friend.RevUserID),
+			}
+		}
+		contacts = append(contacts, contact)
+	}
+	var blockList []user_models.UserBlockModel
+	l.svcCtx.DB.Find(&blockList, "user_id = ?", req.UserID)
+
+	// 群聊
+	var memberList []group_models.GroupMemberModel
+	l.svcCtx.DB.Preload("GroupModel").Find(&memberList, "user_id = ?", req.UserID)
+	groups := make([]exportGroup, 0)
+	for _, member := range memberList {
+		groups = append(groups, exportGroup{
+			GroupID:        member.GroupID,
+			Title:          member.GroupModel.Title,
+			MemberNickname: member.MemberNickname,
+			Role:           member.Role,
+		})
+	}
+
+	// 聊天记录，私聊包括收发的消息，群聊只导出自己发的消息
+	var chatList []chat_models.ChatModel
+	l.svcCtx.DB.Order("created_at").Find(&chatList, "send_user_id = ? or rev_user_id = ?", req.UserID, req.UserID)
+	var groupMsgList []group_models.GroupMsgModel
+	l.svcCtx.DB.Order("created_at").Find(&groupMsgList, "send_user_id = ?", req.UserID)
+
+	buf := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buf)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", user},
+		{"contacts.json", map[string]any{"friends": contacts, "blocks": blockList, "groups": groups}},
+		{"messages.json", map[string]any{"chats": chatList, "groupMsgs": groupMsgList}},
+	}
+	for _, file := range files {
+		byteData, err1 := json.MarshalIndent(file.data, "", "  ")
+		if err1 != nil {
+			logx.Error(err1)
+			return nil, errors.New("数据导出失败")
+		}
+		writer, err1 := zipWriter.Create(file.name)
+		if err1 != nil {
+			logx.Error(err1)
+			return nil, errors.New("数据导出失败")
+		}
+		writer.Write(byteData)
+	}
+	err = zipWriter.Close()
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("数据导出失败")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+package logic
+
+import (
+	"context"
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+	"fim/fim_user/user_models"
+	"fim/utils/handles"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UserHandleCheckLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserHandleCheckLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserHandleCheckLogic {
+	return &UserHandleCheckLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserHandleCheck 检查用户号的格式以及是否已被占用，设置之前给用户提示
+func (l *UserHandleCheckLogic) UserHandleCheck(req *types.UserHandleCheckRequest) (resp *types.UserHandleCheckResponse, err error) {
+	resp = new(types.UserHandleCheckResponse)
+	handle := handles.Normalize(req.Handle)
+	err = handles.Validate(handle)
+	if err != nil {
+		resp.Reason = err.Error()
+		return resp, nil
+	}
+	var user user_models.UserModel
+	err = l.svcCtx.DB.Take(&user, "handle = ?", handle).Error
+	if err == nil && user.ID != req.UserID {
+		resp.Reason = "该用户号已被占用"
+		return resp, nil
+	}
+	resp.Available = true
+	return resp, nil
+}
@@ -0,0 +1,79 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/common/service/redis_service"
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+	"fim/fim_user/user_models"
+	"fim/utils/handles"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UserHandleUpdateLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserHandleUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserHandleUpdateLogic {
+	return &UserHandleUpdateLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserHandleUpdate 设置或修改用户号
+// 用户号不区分大小写，全局唯一，修改之后一段时间内不能再次修改
+func (l *UserHandleUpdateLogic) UserHandleUpdate(req *types.UserHandleUpdateRequest) (resp *types.UserHandleUpdateResponse, err error) {
+	handle := handles.Normalize(req.Handle)
+	err = handles.Validate(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	var user user_models.UserModel
+	err = l.svcCtx.DB.Take(&user, req.UserID).Error
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if user.Handle != nil && *user.Handle == handle {
+		return &types.UserHandleUpdateResponse{}, nil
+	}
+	if nextTime, ok := handleNextUpdate(l.svcCtx, user); !ok {
+		return nil, fmt.Errorf("用户号修改过于频繁，请在%s之后再试", nextTime.Format("2006-01-02 15:04:05"))
+	}
+
+	var other user_models.UserModel
+	err = l.svcCtx.DB.Take(&other, "handle = ?", handle).Error
+	if err == nil {
+		return nil, errors.New("该用户号已被占用")
+	}
+
+	now := time.Now()
+	// 并发设置同一个用户号的时候由唯一索引兜底
+	err = l.svcCtx.DB.Model(&user).Updates(map[string]any{
+		"handle":           handle,
+		"handle_update_at": &now,
+	}).Error
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("该用户号已被占用")
+	}
+	redis_service.PublishUserInfoChange(l.svcCtx.Redis, req.UserID)
+	return &types.UserHandleUpdateResponse{}, nil
+}
+
+// handleNextUpdate 下次可以修改用户号的时间，第一次设置不受限制
+func handleNextUpdate(svcCtx *svc.ServiceContext, user user_models.UserModel) (nextTime time.Time, ok bool) {
+	if user.Handle == nil || user.HandleUpdateAt == nil {
+		return time.Time{}, true
+	}
+	nextTime = user.HandleUpdateAt.Add(time.Duration(svcCtx.Config.Handle.ChangeDays) * 24 * time.Hour)
+	return nextTime, time.Now().After(nextTime)
+}
@@ -3,6 +3,7 @@ package logic
 import (
 	"context"
 	"errors"
+	"fim/common/models/ctype"
 	"fim/fim_user/user_models"
 	"fim/fim_user/user_rpc/types/user_rpc"
 
@@ -52,16 +53,57 @@ func (l *UserInfoLogic) UserInfo(req *types.UserInfoRequest) (resp *types.UserIn
 
 	// 填充响应结构体
 	resp = &types.UserInfoResponse{
-		UserID:        user.ID,
-		Nickname:      user.Nickname,
-		Abstract:      user.Abstract,
-		Avatar:        user.Avatar,
-		RecallMessage: user.UserConfModel.RecallMessage,
-		FriendOnline:  user.UserConfModel.FriendOnline,
-		Sound:         user.UserConfModel.Sound,
-		SearchUser:    user.UserConfModel.SearchUser,
-		SavePwd:       user.UserConfModel.SavePwd,
-		Verification:  user.UserConfModel.Verification,
+		UserID:          user.ID,
+		Nickname:        user.Nickname,
+		Abstract:        user.Abstract,
+		Avatar:          user.Avatar,
+		RecallMessage:   user.UserConfModel.RecallMessage,
+		FriendOnline:    user.UserConfModel.FriendOnline,
+		Sound:           user.UserConfModel.Sound,
+		SearchUser:      user.UserConfModel.SearchUser,
+		SavePwd:         user.UserConfModel.SavePwd,
+		Verification:    user.UserConfModel.Verification,
+		Gender:          user.Gender,
+		Birthday:        user.Birthday,
+		Region:          user.Region,
+		Signature:       user.Signature,
+		CoverImage:      user.CoverImage,
+		Addr:            user.Addr,
+		Presence:        user.UserConfModel.Presence,
+		HideReadReceipt: user.UserConfModel.HideReadReceipt,
+	}
+
+	if user.Handle != nil {
+		resp.Handle = *user.Handle
+	}
+	if nextTime, ok := handleNextUpdate(l.svcCtx, user); !ok {
+		resp.HandleUpdateAt = nextTime.Format("2006-01-02 15:04:05")
+	}
+
+	// 扩展资料的可见范围，没有设置过的时候默认所有人可见
+	visibility := ctype.ProfileVisibility{}
+	if user.UserConfModel.ProfileVisibility != nil {
+		visibility = *user.UserConfModel.ProfileVisibility
+	}
+	resp.ProfileVisibility = &types.ProfileVisibility{
+		Gender:     &visibility.Gender,
+		Birthday:   &visibility.Birthday,
+		Region:     &visibility.Region,
+		Signature:  &visibility.Signature,
+		CoverImage: &visibility.CoverImage,
+		Addr:       &visibility.Addr,
+	}
+
+	// 自动回复设置，没有设置过的时候为关闭
+	autoReply := ctype.AutoReply{}
+	if user.UserConfModel.AutoReply != nil {
+		autoReply = *user.UserConfModel.AutoReply
+	}
+	resp.AutoReply = &types.AutoReply{
+		Mode:      &autoReply.Mode,
+		Content:   &autoReply.Content,
+		StartTime: &autoReply.StartTime,
+		EndTime:   &autoReply.EndTime,
 	}
 
 	// 如果存在验证问题，创建并填充 VerificationQuestion 字段
@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"fim/common/models/ctype"
+	"fim/common/service/redis_service"
 	"fim/fim_user/user_models"
 	"fim/utils/maps"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"fim/fim_user/user_api/internal/svc"
 	"fim/fim_user/user_api/internal/types"
@@ -31,6 +35,26 @@ func NewUserInfoUpadteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Us
 // req 包含需要更新的用户信息和用户配置信息。
 // 返回值 resp 是更新后的响应信息，err 是更新过程中可能出现的错误。
 func (l *UserInfoUpadteLogic) UserInfoUpadte(req *types.UserInfoUpdateRequest) (resp *types.UserInfoUpdateResponse, err error) {
+	// 校验扩展资料
+	if req.Gender != nil && (*req.Gender < 0 || *req.Gender > 2) {
+		return nil, errors.New("性别错误")
+	}
+	if req.Birthday != nil && *req.Birthday != "" {
+		birthday, err1 := time.Parse("2006-01-02", *req.Birthday)
+		if err1 != nil || birthday.After(time.Now()) {
+			return nil, errors.New("生日格式错误")
+		}
+	}
+	if req.Region != nil && utf8.RuneCountInString(*req.Region) > 64 {
+		return nil, errors.New("地区过长")
+	}
+	if req.Signature != nil && utf8.RuneCountInString(*req.Signature) > 128 {
+		return nil, errors.New("个性签名过长")
+	}
+	if req.Presence != nil && (*req.Presence < 0 || *req.Presence > 2) {
+		return nil, errors.New("状态错误")
+	}
+
 	// 将请求中的用户信息转换为 map，用于后续更新数据库中的用户信息。
 	userMaps := maps.RefToMap(*req, "user")
 	// 如果用户信息不为空，则尝试更新用户信息。
@@ -48,6 +72,17 @@ func (l *UserInfoUpadteLogic) UserInfoUpadte(req *types.UserInfoUpdateRequest) (
 			logx.Error(userMaps)
 			return nil, errors.New("更新失败")
 		}
+		// 通知所有节点刷新用户信息缓存，并推送给在线的好友和群成员
+		redis_service.PublishUserInfoChange(l.svcCtx.Redis, req.UserID)
+		// 昵称和简介参与用户搜索，需要同步搜索索引
+		_, nicknameOk := userMaps["nickname"]
+		_, abstractOk := userMaps["abstract"]
+		if nicknameOk || abstractOk {
+			err = user_models.SyncUserSearchIndex(l.svcCtx.DB, req.UserID)
+			if err != nil {
+				logx.Error(err)
+			}
+		}
 	}
 
 	// 将请求中的用户配置信息转换为 map，用于后续更新数据库中的用户配置信息。
@@ -71,6 +106,35 @@ func (l *UserInfoUpadteLogic) UserInfoUpadte(req *types.UserInfoUpdateRequest) (
 				VerificationQuestion: &data,
 			})
 		}
+		// 扩展资料的可见范围只更新传了的字段
+		_, ok = userConfMaps["profile_visibility"]
+		if ok {
+			delete(userConfMaps, "profile_visibility")
+			data, err1 := mergeProfileVisibility(userConf.ProfileVisibility, req.ProfileVisibility)
+			if err1 != nil {
+				return nil, err1
+			}
+			l.svcCtx.DB.Model(&userConf).Updates(&user_models.UserConfModel{
+				ProfileVisibility: data,
+			})
+		}
+		// 自动回复只更新传了的字段
+		_, ok = userConfMaps["auto_reply"]
+		if ok {
+			delete(userConfMaps, "auto_reply")
+			data, err1 := mergeAutoReply(userConf.AutoReply, req.AutoReply)
+			if err1 != nil {
+				return nil, err1
+			}
+			l.svcCtx.DB.Model(&userConf).Updates(&user_models.UserConfModel{
+				AutoReply: data,
+			})
+		}
+		// 状态变化的时候记录设置的时间，离开和忙碌时的自动回复按每一次设置状态计算
+		presenceChange := req.Presence != nil && *req.Presence != userConf.Presence
+		if presenceChange {
+			userConfMaps["presence_update_at"] = time.Now()
+		}
 		// 更新剩余的用户配置信息。
 		err = l.svcCtx.DB.Model(&userConf).Updates(userConfMaps).Error
 		if err != nil {
@@ -78,8 +142,82 @@ func (l *UserInfoUpadteLogic) UserInfoUpadte(req *types.UserInfoUpdateRequest) (
 			logx.Error(userConfMaps)
 			return nil, errors.New("用户信息更新失败")
 		}
+		// 上一次离开或者忙碌的时间段结束了
+		if presenceChange {
+			redis_service.ClearAwayAutoReply(l.svcCtx.Redis, req.UserID)
+		}
 	}
 
 	// 返回更新后的响应信息。
 	return
 }
+
+// mergeProfileVisibility 把请求中的可见范围合并到原有设置上
+func mergeProfileVisibility(old *ctype.ProfileVisibility, req *types.ProfileVisibility) (*ctype.ProfileVisibility, error) {
+	data := ctype.ProfileVisibility{}
+	if old != nil {
+		data = *old
+	}
+	fields := []struct {
+		dst *int8
+		src *int8
+	}{
+		{&data.Gender, req.Gender},
+		{&data.Birthday, req.Birthday},
+		{&data.Region, req.Region},
+		{&data.Signature, req.Signature},
+		{&data.CoverImage, req.CoverImage},
+		{&data.Addr, req.Addr},
+	}
+	for _, field := range fields {
+		if field.src == nil {
+			continue
+		}
+		if *field.src < 0 || *field.src > 2 {
+			return nil, errors.New("可见范围错误")
+		}
+		*field.dst = *field.src
+	}
+	return &data, nil
+}
+
+// mergeAutoReply 把请求中的自动回复设置合并到原有设置上，并校验合并之后的设置
+func mergeAutoReply(old *ctype.AutoReply, req *types.AutoReply) (*ctype.AutoReply, error) {
+	data := ctype.AutoReply{}
+	if old != nil {
+		data = *old
+	}
+	if req.Mode != nil {
+		data.Mode = *req.Mode
+	}
+	if req.Content != nil {
+		data.Content = strings.TrimSpace(*req.Content)
+	}
+	if req.StartTime != nil {
+		data.StartTime = *req.StartTime
+	}
+	if req.EndTime != nil {
+		data.EndTime = *req.EndTime
+	}
+
+	if data.Mode < ctype.AutoReplyClose || data.Mode > ctype.AutoReplyAway {
+		return nil, errors.New("自动回复类型错误")
+	}
+	if utf8.RuneCountInString(data.Content) > 128 {
+		return nil, errors.New("自动回复内容过长")
+	}
+	if data.Mode == ctype.AutoReplyClose {
+		return &data, nil
+	}
+	if data.Content == "" {
+		return nil, errors.New("请填写自动回复内容")
+	}
+	if data.Mode == ctype.AutoReplyOffWork {
+		_, err1 := time.Parse("15:04", data.StartTime)
+		_, err2 := time.Parse("15:04", data.EndTime)
+		if err1 != nil || err2 != nil || data.StartTime == data.EndTime {
+			return nil, errors.New("工作时间格式错误")
+		}
+	}
+	return &data, nil
+}
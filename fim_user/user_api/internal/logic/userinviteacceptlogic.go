@@ -0,0 +1,57 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UserInviteAcceptLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserInviteAcceptLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserInviteAcceptLogic {
+	return &UserInviteAcceptLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserInviteAccept 通过邀请链接添加好友
+// 邀请链接只是省去了搜索的步骤，依然按照邀请人的好友验证设置走加好友流程
+func (l *UserInviteAcceptLogic) UserInviteAccept(req *types.UserInviteAcceptRequest) (resp *types.UserInviteAcceptResponse, err error) {
+	invite, targetID, err := resolveUserInvite(l.svcCtx, req.Token, req.Handle)
+	if err != nil {
+		return nil, err
+	}
+	if targetID == req.UserID {
+		return nil, errors.New("不能添加自己为好友")
+	}
+
+	// 先占用一次使用次数，加好友失败再归还，避免并发的时候超过上限
+	if invite != nil {
+		err = invite.Use(l.svcCtx.DB)
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err = NewAddFriendLogic(l.ctx, l.svcCtx).AddFriend(&types.AddFriendRequest{
+		UserID:               req.UserID,
+		FriendID:             targetID,
+		Verify:               req.Verify,
+		VerificationQuestion: req.VerificationQuestion,
+	})
+	if err != nil {
+		if invite != nil {
+			invite.Release(l.svcCtx.DB)
+		}
+		return nil, err
+	}
+	return &types.UserInviteAcceptResponse{}, nil
+}
@@ -0,0 +1,66 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+	"fim/fim_user/user_models"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UserInviteInfoLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserInviteInfoLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserInviteInfoLogic {
+	return &UserInviteInfoLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserInviteInfo 解析邀请链接，返回邀请人的预览信息和好友验证方式
+func (l *UserInviteInfoLogic) UserInviteInfo(req *types.UserInviteInfoRequest) (resp *types.UserInviteInfoResponse, err error) {
+	invite, targetID, err := resolveUserInvite(l.svcCtx, req.Token, req.Handle)
+	if err != nil {
+		return nil, err
+	}
+
+	var userConf user_models.UserConfModel
+	err = l.svcCtx.DB.Preload("UserModel").Take(&userConf, "user_id = ?", targetID).Error
+	if err != nil || !userConf.UserModel.IsActive() {
+		return nil, errors.New("用户不存在")
+	}
+
+	var friend user_models.FriendModel
+	resp = &types.UserInviteInfoResponse{
+		UserID:       targetID,
+		Nickname:     userConf.UserModel.Nickname,
+		Avatar:       userConf.UserModel.Avatar,
+		Abstract:     userConf.UserModel.Abstract,
+		Verification: userConf.Verification,
+		IsFriend:     friend.IsFriend(l.svcCtx.DB, req.UserID, targetID),
+		RemainUse:    -1,
+	}
+	if userConf.UserModel.Handle != nil {
+		resp.Handle = *userConf.UserModel.Handle
+	}
+	if invite != nil {
+		resp.ExpireAt = invite.ExpireAt.Format("2006-01-02 15:04:05")
+		resp.RemainUse = invite.RemainUse()
+	}
+	// 只返回问题，不返回答案
+	if (userConf.Verification == 3 || userConf.Verification == 4) && userConf.VerificationQuestion != nil {
+		resp.VerificationQuestion = &types.VerificationQuestion{
+			Problem1: userConf.VerificationQuestion.Problem1,
+			Problem2: userConf.VerificationQuestion.Problem2,
+			Problem3: userConf.VerificationQuestion.Problem3,
+		}
+	}
+	return resp, nil
+}
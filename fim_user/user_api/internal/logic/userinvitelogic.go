@@ -0,0 +1,108 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+	"fim/fim_user/user_models"
+	"fim/utils/handles"
+	"fim/utils/invites"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UserInviteLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserInviteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserInviteLogic {
+	return &UserInviteLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserInvite 创建加好友的邀请链接，其他用户通过链接或者二维码添加自己为好友
+func (l *UserInviteLogic) UserInvite(req *types.UserInviteRequest) (resp *types.UserInviteResponse, err error) {
+	if req.Hours < 0 || req.MaxUse < 0 {
+		return nil, errors.New("参数错误")
+	}
+	hours := req.Hours
+	if hours == 0 {
+		hours = l.svcCtx.Config.Invite.ExpireHours
+	}
+
+	var user user_models.UserModel
+	err = l.svcCtx.DB.Take(&user, req.UserID).Error
+	if err != nil || !user.IsActive() {
+		return nil, errors.New("用户不存在")
+	}
+
+	invite := user_models.InviteModel{
+		CreatorID: req.UserID,
+		Type:      invites.UserInvite,
+		TargetID:  req.UserID,
+		ExpireAt:  time.Now().Add(time.Duration(hours) * time.Hour),
+		MaxUse:    req.MaxUse,
+	}
+	err = l.svcCtx.DB.Create(&invite).Error
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("创建邀请链接失败")
+	}
+	token, err := invites.GenerateToken(invites.InvitePayLoad{
+		InviteID: invite.ID,
+		Type:     invite.Type,
+		TargetID: invite.TargetID,
+	}, l.svcCtx.Config.Invite.Secret, invite.ExpireAt)
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("创建邀请链接失败")
+	}
+
+	return &types.UserInviteResponse{
+		Token:    token,
+		Link:     invites.TokenLink(l.svcCtx.Config.Invite.Link, token),
+		ExpireAt: invite.ExpireAt.Format("2006-01-02 15:04:05"),
+		MaxUse:   invite.MaxUse,
+	}, nil
+}
+
+// resolveUserInvite 解析加好友邀请，token和用户号二选一
+// 通过token打开的时候校验签名、有效期和使用次数；通过用户号打开的时候不限次数，但需要对方允许通过用户号查找
+func resolveUserInvite(svcCtx *svc.ServiceContext, token string, handle string) (invite *user_models.InviteModel, targetID uint, err error) {
+	if token == "" {
+		if handle == "" {
+			return nil, 0, errors.New("邀请链接无效")
+		}
+		var user user_models.UserModel
+		err = svcCtx.DB.Preload("UserConfModel").Take(&user, "handle = ?", handles.Normalize(handle)).Error
+		if err != nil || !user.IsActive() || user.UserConfModel == nil || user.UserConfModel.SearchUser == 0 {
+			return nil, 0, errors.New("用户不存在")
+		}
+		return nil, user.ID, nil
+	}
+
+	claims, err := invites.ParseToken(token, svcCtx.Config.Invite.Secret)
+	if err != nil {
+		return
+	}
+	if claims.Type != invites.UserInvite {
+		return nil, 0, errors.New("不是加好友的邀请链接")
+	}
+	invite = new(user_models.InviteModel)
+	err = svcCtx.DB.Take(invite, "id = ? and type = ? and target_id = ?", claims.InviteID, claims.Type, claims.TargetID).Error
+	if err != nil {
+		return nil, 0, errors.New("邀请链接无效")
+	}
+	err = invite.Check()
+	if err != nil {
+		return nil, 0, err
+	}
+	return invite, invite.TargetID, nil
+}
@@ -0,0 +1,47 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/types"
+	"fim/utils/handles"
+	"fim/utils/invites"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UserInviteQrcodeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewUserInviteQrcodeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UserInviteQrcodeLogic {
+	return &UserInviteQrcodeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UserInviteQrcode 生成邀请链接或者用户号名片的二维码，返回png图片数据
+func (l *UserInviteQrcodeLogic) UserInviteQrcode(req *types.UserInviteQrcodeRequest) (byteData []byte, err error) {
+	if req.Size < 0 || req.Size > 1024 {
+		return nil, errors.New("二维码尺寸错误")
+	}
+	invite, _, err := resolveUserInvite(l.svcCtx, req.Token, req.Handle)
+	if err != nil {
+		return nil, err
+	}
+	link := invites.TokenLink(l.svcCtx.Config.Invite.Link, req.Token)
+	if invite == nil {
+		link = invites.HandleLink(l.svcCtx.Config.Invite.Link, handles.Normalize(req.Handle))
+	}
+	byteData, err = invites.QRCode(link, req.Size)
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("二维码生成失败")
+	}
+	return byteData, nil
+}
@@ -45,6 +45,7 @@ func (l *UserValidListLogic) UserValidList(req *types.FriendValidRequest) (resp
 			CreatedAt:          fv.CreatedAt.String(),
 			SendStatus:         fv.SendStatus,
 			RevStatus:          fv.RevStatus,
+			IsExpired:          fv.IsExpired(),
 		}
 		if fv.SendUserID == req.UserID {
 			info.UserID = fv.RevUserID
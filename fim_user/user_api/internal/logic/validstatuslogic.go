@@ -69,6 +69,10 @@ func (l *ValidStatusLogic) ValidStatus(req *types.FriendValidStatusRequest) (res
 			return nil, errors.New("接收方状态错误")
 		}
 	}
+	// 过期的请求不能再同意、拒绝或者忽略
+	if req.Status != 4 && friendVerify.IsExpired() {
+		return nil, errors.New("好友请求已过期")
+	}
 	switch req.Status {
 	case 1: //同意
 		friendVerify.RevStatus = 1
@@ -76,6 +80,10 @@ func (l *ValidStatusLogic) ValidStatus(req *types.FriendValidStatusRequest) (res
 			SendUserID: friendVerify.SendUserID,
 			RevUserID:  friendVerify.RevUserID,
 		})
+		err = user_models.BumpFriendContactVersion(l.svcCtx.DB, friendVerify.SendUserID, friendVerify.RevUserID, user_models.ContactAdd)
+		if err != nil {
+			logx.Error(err)
+		}
 		msg := ctype.Msg{
 			Type: ctype.TextMsgType,
 			TextMsg: &ctype.TextMsg{
@@ -106,6 +114,29 @@ func (l *ValidStatusLogic) ValidStatus(req *types.FriendValidStatusRequest) (res
 
 	}
 	l.svcCtx.DB.Save(&friendVerify)
+
+	// 同意或者拒绝之后通知发起方，收到的请求通知标记为已读
+	if req.Status == 1 || req.Status == 2 {
+		notification := user_models.NotificationModel{
+			UserID:     friendVerify.SendUserID,
+			Type:       user_models.NotifyFriendVerifyResult,
+			SourceID:   friendVerify.ID,
+			SendUserID: friendVerify.RevUserID,
+			Title:      "好友请求已通过",
+			Content:    "对方同意了你的好友请求",
+		}
+		if req.Status == 2 {
+			notification.Title = "好友请求被拒绝"
+			notification.Content = "对方拒绝了你的好友请求"
+		}
+		user_models.SendNotification(l.svcCtx.DB, l.svcCtx.Redis, notification)
+	}
+	if req.Status != 4 {
+		err1 := user_models.ReadNotificationBySource(l.svcCtx.DB, user_models.NotifyFriendVerify, friendVerify.ID)
+		if err1 != nil {
+			logx.Error(err1)
+		}
+	}
 	return
 
 }
@@ -14,23 +14,21 @@ import (
 )
 
 type ServiceContext struct {
-	Config   config.Config
-	DB       *gorm.DB
-	UserRpc  user_rpc.UsersClient
-	ChatRpc  chat_rpc.ChatClient
-	GroupRpc group_rpc.UsersClient
-	Redis    *redis.Client
+	Config  config.Config
+	DB      *gorm.DB
+	UserRpc user_rpc.UsersClient
+	ChatRpc chat_rpc.ChatClient
+	Redis   *redis.Client
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
 	mysqlDb := core.InitGorm(c.Mysql.DataSource)
 	client := core.InitRedis(c.Redis.Addr, c.Redis.Password, c.Redis.DB)
 	return &ServiceContext{
-		Config:   c,
-		DB:       mysqlDb,
-		Redis:    client,
-		UserRpc:  users.NewUsers(zrpc.MustNewClient(c.UserRpc, zrpc.WithUnaryClientInterceptor(zrpc_interceptor.ClientInfoInterceptor))),
-		ChatRpc:  chat.NewChat(zrpc.MustNewClient(c.ChatRpc, zrpc.WithUnaryClientInterceptor(zrpc_interceptor.ClientInfoInterceptor))),
-		GroupRpc: users.NewUsers(zrpc.MustNewClient(c.GroupRpc, zrpc.WithUnaryClientInterceptor(zrpc_interceptor.ClientInfoInterceptor))),
+		Config:  c,
+		DB:      mysqlDb,
+		Redis:   client,
+		UserRpc: users.NewUsers(zrpc.MustNewClient(c.UserRpc, zrpc.WithUnaryClientInterceptor(zrpc_interceptor.ClientInfoInterceptor))),
+		ChatRpc: chat.NewChat(zrpc.MustNewClient(c.ChatRpc, zrpc.WithUnaryClientInterceptor(zrpc_interceptor.ClientInfoInterceptor))),
 	}
 }
@@ -0,0 +1,20 @@
+package task
+
+import (
+	"fim/fim_user/user_api/internal/svc"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// tryLock 多个实例上的定时任务抢同一把锁，每一轮只有抢到锁的实例执行
+// 锁不主动释放，时长比任务的间隔稍短，到期后下一轮重新抢，抢到锁的实例挂掉也不会一直占着
+func tryLock(svcCtx *svc.ServiceContext, name string, interval time.Duration) bool {
+	ok, err := svcCtx.Redis.SetNX(fmt.Sprintf("task_lock:%s", name), 1, interval*9/10).Result()
+	if err != nil {
+		logx.Error(err)
+		return false
+	}
+	return ok
+}
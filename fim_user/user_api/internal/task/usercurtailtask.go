@@ -0,0 +1,40 @@
+package task
+
+import (
+	"fim/common/service/redis_service"
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_models"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// UserCurtailTask 定时解除已经到期的限制和封禁
+func UserCurtailTask(svcCtx *svc.ServiceContext) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		if !tryLock(svcCtx, "user_curtail", time.Minute) {
+			<-ticker.C
+			continue
+		}
+		var curtailList []user_models.UserCurtailModel
+		svcCtx.DB.Find(&curtailList, "expire_at is not null and expire_at <= ?", time.Now())
+		for _, curtail := range curtailList {
+			err := curtail.Remove(svcCtx.DB, 0, 3, "到期自动解除")
+			if err != nil {
+				logx.Error(err)
+				continue
+			}
+			redis_service.PublishUserInfoChange(svcCtx.Redis, curtail.UserID)
+			user_models.SendNotification(svcCtx.DB, svcCtx.Redis, user_models.NotificationModel{
+				UserID:  curtail.UserID,
+				Type:    user_models.NotifySystem,
+				Title:   "限制解除",
+				Content: fmt.Sprintf("你的账号%s已到期解除", curtail.Name()),
+			})
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,259 @@
+package task
+
+import (
+	"fim/common/models/ctype"
+	"fim/common/service/redis_service"
+	"fim/fim_chat/chat_models"
+	"fim/fim_group/group_models"
+	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_models"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// UserDestroyTask 定时清理注销宽限期已过的账号
+func UserDestroyTask(svcCtx *svc.ServiceContext) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if !tryLock(svcCtx, "user_destroy", time.Hour) {
+			<-ticker.C
+			continue
+		}
+		var userIDList []uint
+		svcCtx.DB.Model(&user_models.UserModel{}).
+			Where("status = 2 and delete_at <= ?", time.Now()).
+			Pluck("id", &userIDList)
+		for _, userID := range userIDList {
+			err := svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+				return destroyUser(tx, userID)
+			})
+			if err != nil {
+				logx.Errorf("用户 %d 注销失败 %s", userID, err)
+				continue
+			}
+			redis_service.PublishUserInfoChange(svcCtx.Redis, userID)
+			logx.Infof("用户 %d 已注销", userID)
+		}
+		<-ticker.C
+	}
+}
+
+// destroyUser 清理用户的关系数据，匿名化用户发过的消息
+// 用户记录本身保留为匿名的占位记录，保证历史消息里的发送者id仍然有效
+func destroyUser(tx *gorm.DB, userID uint) error {
+	// 好友、好友验证、黑名单、好友推荐
+	var friend user_models.FriendModel
+	for _, model := range friend.Friends(tx, userID) {
+		friendID := model.SendUserID
+		if friendID == userID {
+			friendID = model.RevUserID
+		}
+		// 对方的联系人列表里移除这个用户
+		err := user_models.BumpContactVersion(tx, friendID, userID, user_models.ContactRemove)
+		if err != nil {
+			return err
+		}
+	}
+	err := tx.Where("send_user_id = ? or rev_user_id = ?", userID, userID).Delete(&user_models.FriendModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("send_user_id = ? or rev_user_id = ?", userID, userID).Delete(&user_models.FriendVerifyModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("user_id = ? or block_user_id = ?", userID, userID).Delete(&user_models.UserBlockModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("user_id = ? or dismiss_user_id = ?", userID, userID).Delete(&user_models.FriendRecommendDismissModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("user_id = ?", userID).Delete(&user_models.UserConfModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("user_id = ?", userID).Delete(&user_models.UserSearchIndexModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("user_id = ?", userID).Delete(&user_models.NotificationModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("user_id = ?", userID).Delete(&user_models.UserPreferenceModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("user_id = ?", userID).Delete(&user_models.LoginLogModel{}).Error
+	if err != nil {
+		return err
+	}
+
+	// 私聊的置顶和删除标记
+	err = tx.Where("user_id = ? or top_user_id = ?", userID, userID).Delete(&chat_models.TopUserModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("user_id = ?", userID).Delete(&chat_models.UserChatDeleteModel{}).Error
+	if err != nil {
+		return err
+	}
+
+	// 私聊和群聊的消息
+	err = destroyUserMsgs(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	// 群聊
+	err = destroyUserGroups(tx, userID)
+	if err != nil {
+		return err
+	}
+
+	// 用户记录匿名化
+	return tx.Model(&user_models.UserModel{}).Where("id = ?", userID).Updates(map[string]any{
+		"pwd":              "",
+		"nickname":         "已注销用户",
+		"handle":           nil,
+		"handle_update_at": nil,
+		"abstract":         "",
+		"avatar":           "",
+		"gender":           0,
+		"birthday":         "",
+		"region":           "",
+		"signature":        "",
+		"cover_image":      "",
+		"ip":               "",
+		"addr":             "",
+		"open_id":          "",
+		"register_source":  "",
+		"status":           3,
+	}).Error
+}
+
+// destroyedMsg 已注销用户发过的消息替换成的内容
+var destroyedMsg = ctype.Msg{
+	Type: ctype.TextMsgType,
+	TextMsg: &ctype.TextMsg{
+		Content: "该消息的发送者已注销",
+	},
+}
+
+// destroyUserMsgs 匿名化用户发过的私聊和群聊消息
+// 消息记录保留，保证会话的消息序号连续，内容和预览替换成提示语
+// 编辑前的版本里有原来的内容，直接删除，用户的表情回应和已读位置也一起删除
+func destroyUserMsgs(tx *gorm.DB, userID uint) error {
+	chatIDQuery := tx.Model(&chat_models.ChatModel{}).Where("send_user_id = ?", userID).Select("id")
+	err := tx.Where("chat_id in (?)", chatIDQuery).Delete(&chat_models.ChatRevisionModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Model(&chat_models.ChatModel{}).Where("send_user_id = ?", userID).Updates(map[string]any{
+		"msg_type":    destroyedMsg.Type,
+		"msg_preview": destroyedMsg.TextMsg.Content,
+		"msg":         destroyedMsg,
+	}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("user_id = ?", userID).Delete(&chat_models.ChatReactionModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("user_id = ?", userID).Delete(&chat_models.ChatReadModel{}).Error
+	if err != nil {
+		return err
+	}
+
+	groupMsgIDQuery := tx.Model(&group_models.GroupMsgModel{}).Where("send_user_id = ?", userID).Select("id")
+	err = tx.Where("msg_id in (?)", groupMsgIDQuery).Delete(&group_models.GroupMsgRevisionModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Model(&group_models.GroupMsgModel{}).Where("send_user_id = ?", userID).Updates(map[string]any{
+		"msg_type":    destroyedMsg.Type,
+		"msg_preview": destroyedMsg.TextMsg.Content,
+		"msg":         destroyedMsg,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", userID).Delete(&group_models.GroupMsgReactionModel{}).Error
+}
+
+// destroyUserGroups 退出用户加入的所有群，自己是群主的群转让给管理员或者最早入群的成员，没有其他成员的群直接解散
+func destroyUserGroups(tx *gorm.DB, userID uint) error {
+	var memberList []group_models.GroupMemberModel
+	tx.Find(&memberList, "user_id = ?", userID)
+	for _, member := range memberList {
+		if member.Role == 1 {
+			var newOwner group_models.GroupMemberModel
+			err := tx.Where("group_id = ? and user_id <> ?", member.GroupID, userID).
+				Order("role").Order("created_at").Take(&newOwner).Error
+			if err != nil {
+				err = removeGroup(tx, member.GroupID)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			err = tx.Model(&newOwner).Update("role", 1).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Model(&group_models.GroupModel{}).Where("id = ?", member.GroupID).Update("creator", newOwner.UserID).Error
+			if err != nil {
+				return err
+			}
+		}
+		// 群消息不再关联群成员，群昵称随成员一起删除
+		err := tx.Model(&group_models.GroupMsgModel{}).Where("group_member_id = ?", member.ID).Update("group_member_id", 0).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(&member).Error
+		if err != nil {
+			return err
+		}
+	}
+	err := tx.Where("user_id = ?", userID).Delete(&group_models.GroupVerifyModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("user_id = ?", userID).Delete(&group_models.GroupUserTopModel{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Where("user_id = ?", userID).Delete(&group_models.GroupUserMsgDeleteModel{}).Error
+}
+
+// removeGroup 解散群，和群聊的解散接口一样清理群相关的数据
+func removeGroup(tx *gorm.DB, groupID uint) error {
+	err := tx.Where("group_id = ?", groupID).Delete(&group_models.GroupMsgModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("group_id = ?", groupID).Delete(&group_models.GroupVerifyModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("group_id = ?", groupID).Delete(&group_models.GroupUserTopModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("group_id = ?", groupID).Delete(&group_models.GroupUserMsgDeleteModel{}).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("group_id = ?", groupID).Delete(&group_models.GroupMemberModel{}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Delete(&group_models.GroupModel{}, groupID).Error
+}
@@ -11,6 +11,134 @@ type AddFriendRequest struct {
 type AddFriendResponse struct {
 }
 
+type AdminBanRemoveRequest struct {
+	UserID       uint   `header:"user_id"`
+	Role         int8   `header:"Role"`
+	TargetUserID uint   `json:"target_user_id"`
+	Reason       string `json:"reason,optional"`
+}
+
+type AdminBanRemoveResponse struct {
+}
+
+type AdminBanRequest struct {
+	UserID       uint   `header:"user_id"`
+	Role         int8   `header:"Role"`
+	TargetUserID uint   `json:"target_user_id"`
+	Reason       string `json:"reason"`
+	Minute       int    `json:"minute,optional"` // 封禁时长 单位分钟 0 表示永久
+}
+
+type AdminBanResponse struct {
+}
+
+type AdminCurtailInfo struct {
+	Type      int8   `json:"type"` // 限制类型 1 限制聊天 2 限制添加好友 3 限制建群 4 限制群聊 5 封禁账号
+	Reason    string `json:"reason"`
+	AdminID   uint   `json:"adminID"`
+	ExpireAt  string `json:"expireAt"` // 为空表示永久
+	CreatedAt string `json:"created_at"`
+}
+
+type AdminCurtailLogInfo struct {
+	ID        uint   `json:"id"`
+	UserID    uint   `json:"user_id"`
+	AdminID   uint   `json:"adminID"` // 为0表示到期自动解除
+	Type      int8   `json:"type"`
+	Action    int8   `json:"action"` // 操作 1 添加限制 2 解除限制 3 到期自动解除
+	Reason    string `json:"reason"`
+	ExpireAt  string `json:"expireAt"`
+	CreatedAt string `json:"created_at"`
+}
+
+type AdminCurtailLogRequest struct {
+	UserID       uint `header:"user_id"`
+	Role         int8 `header:"Role"`
+	TargetUserID uint `form:"target_user_id,optional"` // 按被操作的用户筛选
+	Page         int  `form:"page,optional"`
+	Limit        int  `form:"limit,optional"`
+}
+
+type AdminCurtailLogResponse struct {
+	List  []AdminCurtailLogInfo `json:"list"`
+	Count int64                 `json:"count"`
+}
+
+type AdminCurtailRemoveRequest struct {
+	UserID       uint   `header:"user_id"`
+	Role         int8   `header:"Role"`
+	TargetUserID uint   `json:"target_user_id"`
+	Type         int8   `json:"type"`
+	Reason       string `json:"reason,optional"`
+}
+
+type AdminCurtailRemoveResponse struct {
+}
+
+type AdminCurtailRequest struct {
+	UserID       uint   `header:"user_id"`
+	Role         int8   `header:"Role"`
+	TargetUserID uint   `json:"target_user_id"`
+	Type         int8   `json:"type"` // 限制类型 1 限制聊天 2 限制添加好友 3 限制建群 4 限制群聊
+	Reason       string `json:"reason"`
+	Minute       int    `json:"minute,optional"` // 限制时长 单位分钟 0 表示永久
+}
+
+type AdminCurtailResponse struct {
+}
+
+type AdminUserInfo struct {
+	UserID             uint   `json:"user_id"`
+	Nickname           string `json:"nickname"`
+	Avatar             string `json:"avatar"`
+	Role               int8   `json:"role"`
+	Status             int8   `json:"status"` // 账号状态 0 正常 1 已停用 2 注销中 3 已注销 4 已封禁
+	IP                 string `json:"ip"`
+	Addr               string `json:"addr"`
+	RegisterSource     string `json:"registerSource"`
+	CreatedAt          string `json:"created_at"`
+	CurtailChat        bool   `json:"curtailChat"`
+	CurtailAddUser     bool   `json:"curtailAddUser"`
+	CurtailCreateGroup bool   `json:"curtailCreateGroup"`
+	CurtailInGroupChat bool   `json:"curtailInGroupChat"`
+}
+
+type AdminUserListRequest struct {
+	UserID uint   `header:"user_id"`
+	Role   int8   `header:"Role"`
+	Key    string `form:"key,optional"` // 用户id或昵称
+	Page   int    `form:"page,optional"`
+	Limit  int    `form:"limit,optional"`
+}
+
+type AdminUserListResponse struct {
+	List  []AdminUserInfo `json:"list"`
+	Count int64           `json:"count"`
+}
+
+type AdminUserStatsRequest struct {
+	UserID       uint `header:"user_id"`
+	Role         int8 `header:"Role"`
+	TargetUserID uint `form:"target_user_id"`
+}
+
+type AdminUserStatsResponse struct {
+	UserID          uint               `json:"user_id"`
+	FriendCount     int64              `json:"friendCount"`     // 好友数
+	GroupCount      int64              `json:"groupCount"`      // 加入的群数
+	GroupOwnerCount int64              `json:"groupOwnerCount"` // 创建的群数
+	ChatCount       int64              `json:"chatCount"`       // 发送的私聊消息数
+	GroupMsgCount   int64              `json:"groupMsgCount"`   // 发送的群消息数
+	CurtailList     []AdminCurtailInfo `json:"curtailList"`     // 生效中的限制
+}
+
+type AutoReply struct {
+	Mode      *int8   `json:"mode,optional" user_conf:"mode"`            // 0 关闭 1 始终回复 2 工作时间以外回复 3 离开或者忙碌的时候回复
+	Content   *string `json:"content,optional" user_conf:"content"`      // 回复内容
+	StartTime *string `json:"startTime,optional" user_conf:"start_time"` // 工作时间开始 例如 09:00
+	EndTime   *string `json:"endTime,optional" user_conf:"end_time"`     // 工作时间结束 例如 18:00
+}
+
 type DeleteFriendRequest struct {
 	UserID   uint `header:"user_id"`
 	FriendID uint `json:"friend_id"`
@@ -26,24 +154,35 @@ type FriendInfoRequest struct {
 }
 
 type FriendInfoResponse struct {
-	UserID   uint   `json:"user_id"`
-	Nickname string `json:"nickname"`
-	Abstract string `json:"abstract"`
-	Avatar   string `json:"avatar"`
-	Notice   string `json:"notice"`
-	IsOnline bool   `json:"isOnline"` // 是否在线
+	UserID     uint   `json:"user_id"`
+	Nickname   string `json:"nickname"`
+	Handle     string `json:"handle"`
+	Abstract   string `json:"abstract"`
+	Avatar     string `json:"avatar"`
+	Notice     string `json:"notice"`
+	IsOnline   bool   `json:"isOnline"` // 是否在线
+	Gender     int8   `json:"gender"`   // 性别 0 未知 1 男 2 女，扩展资料按对方设置的可见范围返回
+	Birthday   string `json:"birthday"`
+	Region     string `json:"region"`
+	Signature  string `json:"signature"`
+	CoverImage string `json:"coverImage"`
+	Addr       string `json:"addr"`     // IP归属地
+	Presence   int8   `json:"presence"` // 状态 0 在线 1 离开 2 忙碌
+	Letter     string `json:"letter"`   // 索引字母 A-Z，其他字符为 #
 }
 
 type FriendListRequest struct {
-	UserID uint `header:"user_id"`
-	Role   int8 `header:"Role"`
-	Page   int  `form:"page,optional"`
-	Limit  int  `form:"limit,optional"`
+	UserID uint   `header:"user_id"`
+	Role   int8   `header:"Role"`
+	Page   int    `form:"page,optional"`
+	Limit  int    `form:"limit,optional"`
+	Key    string `form:"key,optional"` // 按昵称、备注过滤，支持汉字、全拼和拼音首字母
 }
 
 type FriendListResponse struct {
-	List  []FriendInfoResponse `json:"list"`
-	Count int                  `json:"count"`
+	List    []FriendInfoResponse `json:"list"`
+	Count   int                  `json:"count"`
+	Version int64                `json:"version"` // 当前的联系人版本，增量同步的起点
 }
 
 type FriendNoticeUpdateRequest struct {
@@ -55,6 +194,47 @@ type FriendNoticeUpdateRequest struct {
 type FriendNoticeUpdateResponse struct {
 }
 
+type FriendRecommendDismissRequest struct {
+	UserID        uint `header:"user_id"`
+	DismissUserID uint `json:"dismiss_user_id"` // 不再推荐的用户id
+}
+
+type FriendRecommendDismissResponse struct {
+}
+
+type FriendRecommendInfo struct {
+	UserID            uint   `json:"user_id"`
+	Nickname          string `json:"nickname"`
+	Abstract          string `json:"abstract"`
+	Avatar            string `json:"avatar"`
+	MutualFriendCount int    `json:"mutual_friend_count"` // 共同好友数
+	SharedGroupCount  int    `json:"shared_group_count"`  // 共同群聊数
+}
+
+type FriendRecommendRequest struct {
+	UserID uint `header:"user_id"`
+	Page   int  `form:"page,optional"`
+	Limit  int  `form:"limit,optional"`
+}
+
+type FriendRecommendResponse struct {
+	List  []FriendRecommendInfo `json:"list"`
+	Count int                   `json:"count"`
+}
+
+type FriendSyncRequest struct {
+	UserID  uint  `header:"user_id"`
+	Version int64 `form:"version,optional"` // 客户端本地的联系人版本，0表示全量同步
+}
+
+type FriendSyncResponse struct {
+	Version int64                `json:"version"` // 服务端当前的联系人版本
+	Full    bool                 `json:"full"`    // 是否为全量数据，为true时客户端需要用add替换本地列表
+	Add     []FriendInfoResponse `json:"add"`     // 新增的好友
+	Update  []FriendInfoResponse `json:"update"`  // 备注发生变化的好友
+	Remove  []uint               `json:"remove"`  // 删除的好友id
+}
+
 type FriendValidInfo struct {
 	UserID               uint                  `json:"user_id"`
 	Nickname             string                `json:"nickname"`
@@ -68,6 +248,7 @@ type FriendValidInfo struct {
 	ID                   uint                  `json:"id"`                    // 验证id
 	Flag                 string                `json:"flag"`                  // 标识 send or rev
 	CreatedAt            string                `json:"created_at"`            // 创建时间
+	IsExpired            bool                  `json:"isExpired"`             // 未处理的请求是否已过期
 }
 
 type FriendValidRequest struct {
@@ -90,17 +271,133 @@ type FriendValidStatusRequest struct {
 type FriendValidStatusResponse struct {
 }
 
+type LoginLogInfo struct {
+	IP        string `json:"ip"`
+	Addr      string `json:"addr"`      // IP归属地
+	Source    string `json:"source"`    // 登录方式 password 账号密码 qq QQ登录 reactivate 恢复账号
+	UserAgent string `json:"userAgent"` // 登录设备
+	CreatedAt string `json:"createdAt"` // 登录时间
+}
+
+type LoginLogRequest struct {
+	UserID uint `header:"user_id"`
+	Page   int  `form:"page,optional"`
+	Limit  int  `form:"limit,optional"`
+}
+
+type LoginLogResponse struct {
+	List  []LoginLogInfo `json:"list"`
+	Count int64          `json:"count"`
+}
+
+type NotificationInfo struct {
+	ID         uint   `json:"id"`
+	Type       int8   `json:"type"`       // 通知类型
+	SourceID   uint   `json:"sourceID"`   // 来源id 好友验证id或者群验证id
+	SendUserID uint   `json:"sendUserID"` // 触发通知的用户 系统通知为0
+	Nickname   string `json:"nickname"`   // 触发通知的用户昵称
+	Avatar     string `json:"avatar"`     // 触发通知的用户头像
+	GroupID    uint   `json:"groupID"`    // 相关的群
+	Title      string `json:"title"`
+	Content    string `json:"content"`
+	IsRead     bool   `json:"isRead"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type NotificationListRequest struct {
+	UserID uint `header:"user_id"`
+	Type   int8 `form:"type,optional"`   // 通知类型 1 好友请求 2 好友请求处理结果 3 加群申请 4 加群申请处理结果 5 系统通知 不传为全部
+	Unread bool `form:"unread,optional"` // 只看未读
+	Page   int  `form:"page,optional"`
+	Limit  int  `form:"limit,optional"`
+}
+
+type NotificationListResponse struct {
+	List  []NotificationInfo `json:"list"`
+	Count int64              `json:"count"`
+}
+
+type NotificationReadRequest struct {
+	UserID uint   `header:"user_id"`
+	IDList []uint `json:"idList,optional"` // 要标记已读的通知id
+	Type   int8   `json:"type,optional"`   // 把这个类型的通知全部标记已读，id和类型都不传的时候全部标记已读
+}
+
+type NotificationReadResponse struct {
+}
+
+type NotificationUnreadRequest struct {
+	UserID uint `header:"user_id"`
+}
+
+type NotificationUnreadResponse struct {
+	Total  int64 `json:"total"`  // 未读总数
+	Friend int64 `json:"friend"` // 好友请求相关的未读数
+	Group  int64 `json:"group"`  // 群验证相关的未读数
+	System int64 `json:"system"` // 系统通知的未读数
+}
+
+type PreferenceItem struct {
+	Namespace string `json:"namespace"` // 命名空间
+	Key       string `json:"key"`       // 配置项
+	Value     string `json:"value"`     // 配置值
+	Version   int64  `json:"version"`   // 最近一次修改时的偏好版本
+	IsRemoved bool   `json:"isRemoved"` // 是否已经删除
+}
+
+type PreferenceListRequest struct {
+	UserID    uint   `header:"user_id"`
+	Namespace string `form:"namespace,optional"` // 命名空间，不传为全部
+	Version   int64  `form:"version,optional"`   // 客户端本地的偏好版本，0表示全量同步
+}
+
+type PreferenceListResponse struct {
+	Version int64            `json:"version"` // 服务端当前的偏好版本
+	Full    bool             `json:"full"`    // 是否为全量数据，为true时客户端需要用list替换本地配置
+	List    []PreferenceItem `json:"list"`    // 变化的配置项，全量的时候不包含已删除的
+}
+
+type PreferenceUpdateRequest struct {
+	UserID    uint              `header:"user_id"`
+	Namespace string            `json:"namespace"`
+	Version   int64             `json:"version,optional"` // 客户端本地的偏好版本，要修改的配置项在这之后被改过会返回冲突，0表示强制覆盖
+	Set       map[string]string `json:"set,optional"`     // 新增或者修改的配置项
+	Remove    []string          `json:"remove,optional"`  // 删除的配置项
+}
+
+type PreferenceUpdateResponse struct {
+	Version      int64            `json:"version"`      // 服务端当前的偏好版本
+	Conflict     bool             `json:"conflict"`     // 是否冲突，冲突的时候不会做任何修改
+	ConflictList []PreferenceItem `json:"conflictList"` // 冲突的配置项在服务端的当前值
+}
+
+type ProfileVisibility struct {
+	Gender     *int8 `json:"gender,optional" user_conf:"gender"`
+	Birthday   *int8 `json:"birthday,optional" user_conf:"birthday"`
+	Region     *int8 `json:"region,optional" user_conf:"region"`
+	Signature  *int8 `json:"signature,optional" user_conf:"signature"`
+	CoverImage *int8 `json:"coverImage,optional" user_conf:"cover_image"`
+	Addr       *int8 `json:"addr,optional" user_conf:"addr"` // IP归属地
+}
+
 type SearchInfo struct {
-	UserID   uint   `json:"user_id"`
-	Nickname string `json:"nickname"`
-	Abstract string `json:"abstract"`
-	Avatar   string `json:"avatar"`
-	IsFriend bool   `json:"isFriend"` // 是否是好友
+	UserID     uint   `json:"user_id"`
+	Nickname   string `json:"nickname"`
+	Handle     string `json:"handle"`
+	Abstract   string `json:"abstract"`
+	Avatar     string `json:"avatar"`
+	IsFriend   bool   `json:"isFriend"` // 是否是好友
+	Gender     int8   `json:"gender"`   // 性别 0 未知 1 男 2 女，扩展资料按对方设置的可见范围返回
+	Birthday   string `json:"birthday"`
+	Region     string `json:"region"`
+	Signature  string `json:"signature"`
+	CoverImage string `json:"coverImage"`
+	Addr       string `json:"addr"` // IP归属地
 }
 
 type SearchRequest struct {
-	UserID uint   `header:"user_id "`
-	Key    string `form:"key,optional"`    // 用户id和昵称
+	UserID uint   `header:"user_id"`
+	Key    string `form:"key,optional"`    // 用户id、昵称、昵称拼音或简介
 	Online bool   `form:"online,optional"` // 搜索在线的用户
 	Page   int    `form:"page,optional"`
 	Limit  int    `form:"limit,optional"`
@@ -111,6 +408,77 @@ type SearchResponse struct {
 	Count int64        `json:"count"`
 }
 
+type UserBlockInfo struct {
+	UserID    uint   `json:"user_id"`
+	Nickname  string `json:"nickname"`
+	Avatar    string `json:"avatar"`
+	CreatedAt string `json:"created_at"` // 拉黑时间
+}
+
+type UserBlockListRequest struct {
+	UserID uint `header:"user_id"`
+	Page   int  `form:"page,optional"`
+	Limit  int  `form:"limit,optional"`
+}
+
+type UserBlockListResponse struct {
+	List  []UserBlockInfo `json:"list"`
+	Count int64           `json:"count"`
+}
+
+type UserBlockRemoveRequest struct {
+	UserID      uint `header:"user_id"`
+	BlockUserID uint `json:"block_user_id"` // 要移出黑名单的用户id
+}
+
+type UserBlockRemoveResponse struct {
+}
+
+type UserBlockRequest struct {
+	UserID      uint `header:"user_id"`
+	BlockUserID uint `json:"block_user_id"` // 要拉黑的用户id
+}
+
+type UserBlockResponse struct {
+}
+
+type UserDeactivateRequest struct {
+	UserID uint `header:"user_id"`
+}
+
+type UserDeactivateResponse struct {
+}
+
+type UserDestroyRequest struct {
+	UserID uint `header:"user_id"`
+}
+
+type UserDestroyResponse struct {
+	DeleteAt string `json:"deleteAt"` // 注销生效时间，之前可以通过恢复账号撤销
+}
+
+type UserExportRequest struct {
+	UserID uint `header:"user_id"`
+}
+
+type UserHandleCheckRequest struct {
+	UserID uint   `header:"user_id"`
+	Handle string `form:"handle"`
+}
+
+type UserHandleCheckResponse struct {
+	Available bool   `json:"available"` // 是否可用
+	Reason    string `json:"reason"`    // 不可用的原因
+}
+
+type UserHandleUpdateRequest struct {
+	UserID uint   `header:"user_id"`
+	Handle string `json:"handle"` // 用户号，6-20位，字母开头，不区分大小写
+}
+
+type UserHandleUpdateResponse struct {
+}
+
 type UserInfoRequest struct {
 	UserID uint `header:"user_id"`
 	Role   int8 `header:"Role"`
@@ -119,6 +487,8 @@ type UserInfoRequest struct {
 type UserInfoResponse struct {
 	UserID               uint                  `json:"user_id"`
 	Nickname             string                `json:"nickname"`
+	Handle               string                `json:"handle"`           // 用户号，没设置的时候为空
+	HandleUpdateAt       string                `json:"handle_update_at"` // 下次可以修改用户号的时间，为空表示现在就可以修改
 	Abstract             string                `json:"abstract"`
 	Avatar               string                `json:"avatar"`
 	RecallMessage        *string               `json:"recall_message"`
@@ -129,6 +499,16 @@ type UserInfoResponse struct {
 	SearchUser           int8                  `json:"search_user"`
 	Verification         int8                  `json:"verification"`
 	VerificationQuestion *VerificationQuestion `json:"verification_question"`
+	Gender               int8                  `json:"gender"` // 性别 0 未知 1 男 2 女
+	Birthday             string                `json:"birthday"`
+	Region               string                `json:"region"`
+	Signature            string                `json:"signature"`
+	CoverImage           string                `json:"coverImage"`
+	Addr                 string                `json:"addr"`              // 最近一次登录的IP归属地
+	ProfileVisibility    *ProfileVisibility    `json:"profileVisibility"` // 扩展资料的可见范围
+	Presence             int8                  `json:"presence"`          // 状态 0 在线 1 离开 2 忙碌
+	AutoReply            *AutoReply            `json:"autoReply"`         // 自动回复设置
+	HideReadReceipt      bool                  `json:"hide_read_receipt"` // 不发送已读回执
 }
 
 type UserInfoUpdateRequest struct {
@@ -136,6 +516,11 @@ type UserInfoUpdateRequest struct {
 	Nickname             *string               `json:"nickname,optional" user:"nickname"`
 	Abstract             *string               `json:"abstract,optional" user:"abstract"`
 	Avatar               *string               `json:"avatar,optional" user:"avatar"`
+	Gender               *int8                 `json:"gender,optional" user:"gender"`
+	Birthday             *string               `json:"birthday,optional" user:"birthday"`
+	Region               *string               `json:"region,optional" user:"region"`
+	Signature            *string               `json:"signature,optional" user:"signature"`
+	CoverImage           *string               `json:"coverImage,optional" user:"cover_image"`
 	RecallMessage        *string               `json:"recallMessage,optional" user_conf:"recall_message"`
 	FriendOnline         *bool                 `json:"friendOnline,optional" user_conf:"friend_online"`
 	Sound                *bool                 `json:"sound,optional" user_conf:"sound"`
@@ -144,11 +529,64 @@ type UserInfoUpdateRequest struct {
 	SearchUser           *int8                 `json:"searchUser,optional" user_conf:"search_user"`
 	Verification         *int8                 `json:"verification,optional" user_conf:"verification"`
 	VerificationQuestion *VerificationQuestion `json:"verificationQuestion,optional" user_conf:"verification_question"`
+	ProfileVisibility    *ProfileVisibility    `json:"profileVisibility,optional" user_conf:"profile_visibility"`
+	Presence             *int8                 `json:"presence,optional" user_conf:"presence"` // 状态 0 在线 1 离开 2 忙碌
+	AutoReply            *AutoReply            `json:"autoReply,optional" user_conf:"auto_reply"`
+	HideReadReceipt      *bool                 `json:"hideReadReceipt,optional" user_conf:"hide_read_receipt"` // 不发送已读回执
 }
 
 type UserInfoUpdateResponse struct {
 }
 
+type UserInviteAcceptRequest struct {
+	UserID               uint                  `header:"user_id"`
+	Token                string                `json:"token,optional"`
+	Handle               string                `json:"handle,optional"` // 通过用户号打开的邀请，和token二选一
+	Verify               string                `json:"verify,optional"` // 验证信息
+	VerificationQuestion *VerificationQuestion `json:"verification_question,optional"`
+}
+
+type UserInviteAcceptResponse struct {
+}
+
+type UserInviteInfoRequest struct {
+	UserID uint   `header:"user_id"`
+	Token  string `form:"token,optional"`
+	Handle string `form:"handle,optional"` // 通过用户号打开的邀请，和token二选一
+}
+
+type UserInviteInfoResponse struct {
+	UserID               uint                  `json:"user_id"`
+	Nickname             string                `json:"nickname"`
+	Handle               string                `json:"handle"`
+	Avatar               string                `json:"avatar"`
+	Abstract             string                `json:"abstract"`
+	Verification         int8                  `json:"verification"`          // 好友验证方式
+	VerificationQuestion *VerificationQuestion `json:"verification_question"` // 验证问题，不包含答案
+	IsFriend             bool                  `json:"is_friend"`
+	ExpireAt             string                `json:"expire_at"`
+	RemainUse            int                   `json:"remain_use"` // 剩余使用次数，-1表示不限制
+}
+
+type UserInviteQrcodeRequest struct {
+	Token  string `form:"token,optional"`
+	Handle string `form:"handle,optional"` // 生成用户号名片的二维码，和token二选一
+	Size   int    `form:"size,optional"`   // 二维码尺寸，默认256
+}
+
+type UserInviteRequest struct {
+	UserID uint `header:"user_id"`
+	Hours  int  `json:"hours,optional"`   // 有效期，单位小时，不传使用默认值
+	MaxUse int  `json:"max_use,optional"` // 最大使用次数，0表示不限制
+}
+
+type UserInviteResponse struct {
+	Token    string `json:"token"`
+	Link     string `json:"link"` // 邀请链接
+	ExpireAt string `json:"expire_at"`
+	MaxUse   int    `json:"max_use"`
+}
+
 type UserVaildRequest struct {
 	UserID   uint `header:"user_id"`
 	FriendID uint `json:"friend_id"`
@@ -7,6 +7,7 @@ import (
 	"fim/fim_user/user_api/internal/config"
 	"fim/fim_user/user_api/internal/handler"
 	"fim/fim_user/user_api/internal/svc"
+	"fim/fim_user/user_api/internal/task"
 
 	"github.com/zeromicro/go-zero/core/conf"
 	"github.com/zeromicro/go-zero/rest"
@@ -25,6 +26,10 @@ func main() {
 
 	ctx := svc.NewServiceContext(c)
 	handler.RegisterHandlers(server, ctx)
+	// 定时清理已到期的注销账号
+	go task.UserDestroyTask(ctx)
+	// 定时解除到期的用户限制
+	go task.UserCurtailTask(ctx)
 
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
 	server.Start()
@@ -0,0 +1,68 @@
+package user_models
+
+import (
+	"fim/common/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 联系人变更类型
+const (
+	ContactAdd    int8 = 1 // 新增好友
+	ContactUpdate int8 = 2 // 修改备注
+	ContactRemove int8 = 3 // 删除好友
+)
+
+// ContactChangeModel 联系人变更记录表，用于客户端增量同步好友列表
+// 每个用户的每个联系人只保留最新的一条记录
+type ContactChangeModel struct {
+	models.Model
+	UserID     uint  `gorm:"uniqueIndex:idx_contact_change" json:"userID"`   // 联系人列表的所有者
+	FriendID   uint  `gorm:"uniqueIndex:idx_contact_change" json:"friendID"` // 发生变化的联系人
+	Version    int64 `gorm:"index" json:"version"`                           // 最近一次变化时的联系人版本
+	AddVersion int64 `json:"addVersion"`                                     // 成为好友时的联系人版本
+	IsRemoved  bool  `json:"isRemoved"`                                      // 是否已经删除
+}
+
+// BumpContactVersion 用户的联系人发生变化，联系人版本加一并记录变更
+func BumpContactVersion(db *gorm.DB, userID, friendID uint, changeType int8) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// 更新的时候锁住用户配置这一行，同一个用户的版本号不会重复
+		err := tx.Model(&UserConfModel{}).Where("user_id = ?", userID).
+			UpdateColumn("contact_version", gorm.Expr("contact_version + 1")).Error
+		if err != nil {
+			return err
+		}
+		var version int64
+		err = tx.Model(&UserConfModel{}).Where("user_id = ?", userID).Select("contact_version").Scan(&version).Error
+		if err != nil {
+			return err
+		}
+
+		change := ContactChangeModel{
+			UserID:    userID,
+			FriendID:  friendID,
+			Version:   version,
+			IsRemoved: changeType == ContactRemove,
+		}
+		columns := []string{"version", "is_removed", "updated_at"}
+		if changeType == ContactAdd {
+			change.AddVersion = version
+			columns = append(columns, "add_version")
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "friend_id"}},
+			DoUpdates: clause.AssignmentColumns(columns),
+		}).Create(&change).Error
+	})
+}
+
+// BumpFriendContactVersion 好友关系建立或解除，双方的联系人版本都要变化
+func BumpFriendContactVersion(db *gorm.DB, userA, userB uint, changeType int8) error {
+	err := BumpContactVersion(db, userA, userB, changeType)
+	if err != nil {
+		return err
+	}
+	return BumpContactVersion(db, userB, userA, changeType)
+}
@@ -7,12 +7,16 @@ import (
 
 type FriendModel struct {
 	models.Model
-	SendUserID    uint      `json:"sendUserID"`                     // 发起验证方
-	SendUserModel UserModel `gorm:"foreignKey:SendUserID" json:"-"` // 发起验证方
-	RevUserID     uint      `json:"revUserID"`                      // 接受验证方
-	RevUserModel  UserModel `gorm:"foreignKey:RevUserID" json:"-"`  // 接受验证方
-	SenUserNotice string    `gorm:"size:128" json:"senUserNotice"`  // 发送方备注
-	RevUserNotice string    `gorm:"size:128" json:"revUserNotice"`  // 接收方备注
+	SendUserID            uint      `json:"sendUserID"`                     // 发起验证方
+	SendUserModel         UserModel `gorm:"foreignKey:SendUserID" json:"-"` // 发起验证方
+	RevUserID             uint      `json:"revUserID"`                      // 接受验证方
+	RevUserModel          UserModel `gorm:"foreignKey:RevUserID" json:"-"`  // 接受验证方
+	SenUserNotice         string    `gorm:"size:128" json:"senUserNotice"`  // 发送方备注
+	RevUserNotice         string    `gorm:"size:128" json:"revUserNotice"`  // 接收方备注
+	SenUserNoticePinyin   string    `gorm:"size:768" json:"-"`              // 发送方备注的全拼，修改备注时计算，一个汉字最多6个字母
+	SenUserNoticeInitials string    `gorm:"size:128" json:"-"`              // 发送方备注的拼音首字母
+	RevUserNoticePinyin   string    `gorm:"size:768" json:"-"`              // 接收方备注的全拼
+	RevUserNoticeInitials string    `gorm:"size:128" json:"-"`              // 接收方备注的拼音首字母
 
 }
 
@@ -29,7 +33,7 @@ type FriendModel struct {
 //	bool: A和B是否互为好友
 func (f *FriendModel) IsFriend(db *gorm.DB, A, B uint) bool {
 	// 尝试根据用户ID组合查询是否存在好友关系
-	err := db.Take(&f, "(send_user_id =? AND rev_user_id =?)or (send_user_id =? AND rev_user_id =?)", A, B, B, A)
+	err := db.Take(f, "(send_user_id =? AND rev_user_id =?)or (send_user_id =? AND rev_user_id =?)", A, B, B, A).Error
 
 	// 如果没有错误，即找到了相关记录，说明A和B是好友
 	if err == nil {
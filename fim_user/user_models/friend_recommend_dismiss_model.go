@@ -0,0 +1,10 @@
+package user_models
+
+import "fim/common/models"
+
+// FriendRecommendDismissModel 好友推荐忽略表，被忽略的用户不会再出现在推荐列表中
+type FriendRecommendDismissModel struct {
+	models.Model
+	UserID        uint `json:"userID"`        // 用户id
+	DismissUserID uint `json:"dismissUserID"` // 被忽略的推荐用户id
+}
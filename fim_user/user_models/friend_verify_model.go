@@ -3,6 +3,7 @@ package user_models
 import (
 	"fim/common/models"
 	"fim/common/models/ctype"
+	"time"
 )
 
 // FriendVerifyModel 好友验证表
@@ -17,4 +18,10 @@ type FriendVerifyModel struct {
 	RevStatus            int8                        `json:"revStatus"`                          // 接收方状态 0 未操作 1 同意 2 拒绝 3 忽略 4 删除
 	AdditionalMessages   string                      `gorm:"size:128" json:"additionalMessages"` // 附加消息
 	VerificationQuestion *ctype.VerificationQuestion `json:"verificationQuestion"`               // 验证问题  为3和4的时候需要
+	ExpireAt             *time.Time                  `json:"expireAt"`                           // 过期时间，过期后接收方不能再处理
+}
+
+// IsExpired 未处理的好友请求是否已经过期
+func (fv FriendVerifyModel) IsExpired() bool {
+	return fv.RevStatus == 0 && fv.ExpireAt != nil && fv.ExpireAt.Before(time.Now())
 }
@@ -0,0 +1,63 @@
+package user_models
+
+import (
+	"errors"
+	"fim/common/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InviteModel 邀请链接表，好友邀请和群聊邀请共用
+type InviteModel struct {
+	models.Model
+	CreatorID uint      `gorm:"index" json:"creatorID"`                  // 创建邀请的用户
+	Type      int8      `gorm:"index:idx_invite_target" json:"type"`     // 邀请类型 1 好友 2 群聊
+	TargetID  uint      `gorm:"index:idx_invite_target" json:"targetID"` // 邀请人或群聊的ID
+	ExpireAt  time.Time `json:"expireAt"`                                // 过期时间
+	MaxUse    int       `json:"maxUse"`                                  // 最大使用次数，0表示不限制
+	UseCount  int       `json:"useCount"`                                // 已使用次数
+}
+
+// Check 判断邀请是否还可以使用
+func (invite InviteModel) Check() error {
+	if invite.ExpireAt.Before(time.Now()) {
+		return errors.New("邀请链接已过期")
+	}
+	if invite.MaxUse > 0 && invite.UseCount >= invite.MaxUse {
+		return errors.New("邀请链接已达到使用次数上限")
+	}
+	return nil
+}
+
+// RemainUse 剩余使用次数，-1表示不限制
+func (invite InviteModel) RemainUse() int {
+	if invite.MaxUse == 0 {
+		return -1
+	}
+	return invite.MaxUse - invite.UseCount
+}
+
+// Use 占用一次使用次数，并发的时候通过条件更新保证不超过上限
+func (invite *InviteModel) Use(db *gorm.DB) error {
+	if err := invite.Check(); err != nil {
+		return err
+	}
+	result := db.Model(invite).
+		Where("expire_at > ? and (max_use = 0 or use_count < max_use)", time.Now()).
+		UpdateColumn("use_count", gorm.Expr("use_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("邀请链接已达到使用次数上限")
+	}
+	invite.UseCount++
+	return nil
+}
+
+// Release 加好友或者加群失败的时候，归还占用的使用次数
+func (invite *InviteModel) Release(db *gorm.DB) {
+	db.Model(invite).Where("use_count > 0").UpdateColumn("use_count", gorm.Expr("use_count - 1"))
+	invite.UseCount--
+}
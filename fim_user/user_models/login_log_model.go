@@ -0,0 +1,15 @@
+package user_models
+
+import (
+	"fim/common/models"
+)
+
+// LoginLogModel 登录记录表，记录每次登录的IP和归属地
+type LoginLogModel struct {
+	models.Model
+	UserID    uint   `gorm:"index" json:"userID"`       // 登录的用户
+	IP        string `gorm:"size:32" json:"ip"`         // 登录IP
+	Addr      string `gorm:"size:64" json:"addr"`       // IP归属地
+	Source    string `gorm:"size:16" json:"source"`     // 登录方式 password 账号密码 qq QQ登录 reactivate 恢复账号
+	UserAgent string `gorm:"size:256" json:"userAgent"` // 登录设备的User-Agent
+}
@@ -0,0 +1,89 @@
+package user_models
+
+import (
+	"fim/common/models"
+	"fim/common/models/ctype"
+	"fim/common/service/redis_service"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// 通知类型
+const (
+	NotifyFriendVerify       int8 = 1 // 好友请求
+	NotifyFriendVerifyResult int8 = 2 // 好友请求处理结果
+	NotifyGroupVerify        int8 = 3 // 加群申请
+	NotifyGroupVerifyResult  int8 = 4 // 加群申请处理结果
+	NotifySystem             int8 = 5 // 系统通知
+)
+
+// NotificationModel 通知表，好友请求、群验证和系统事件统一记录在这里
+type NotificationModel struct {
+	models.Model
+	UserID     uint       `gorm:"index:idx_notification_user" json:"userID"` // 接收通知的用户
+	Type       int8       `json:"type"`                                      // 通知类型 1 好友请求 2 好友请求处理结果 3 加群申请 4 加群申请处理结果 5 系统通知
+	SourceID   uint       `json:"sourceID"`                                  // 来源id 好友验证id或者群验证id
+	SendUserID uint       `json:"sendUserID"`                                // 触发通知的用户 系统通知为0
+	GroupID    uint       `json:"groupID"`                                   // 相关的群
+	Title      string     `gorm:"size:32" json:"title"`                      // 标题
+	Content    string     `gorm:"size:256" json:"content"`                   // 内容
+	IsRead     bool       `gorm:"index:idx_notification_user" json:"isRead"` // 是否已读
+	ReadAt     *time.Time `json:"readAt"`                                    // 已读时间
+}
+
+// IsValidNotifyType 判断通知类型是否合法
+func IsValidNotifyType(notifyType int8) bool {
+	return notifyType >= NotifyFriendVerify && notifyType <= NotifySystem
+}
+
+// UnreadNotificationCount 用户未读的通知数
+func UnreadNotificationCount(db *gorm.DB, userID uint) (count int64) {
+	db.Model(&NotificationModel{}).Where("user_id = ? and is_read = ?", userID, false).Count(&count)
+	return
+}
+
+// ReadNotificationBySource 请求被处理之后，把这个请求产生的通知都标记为已读
+func ReadNotificationBySource(db *gorm.DB, notifyType int8, sourceID uint) error {
+	return db.Model(&NotificationModel{}).
+		Where("type = ? and source_id = ? and is_read = ?", notifyType, sourceID, false).
+		Updates(map[string]any{
+			"is_read": true,
+			"read_at": time.Now(),
+		}).Error
+}
+
+// Create 保存通知，返回推送给客户端的通知消息，消息里带上保存之后的未读总数
+func (n *NotificationModel) Create(db *gorm.DB) (msg ctype.NotificationMsg, err error) {
+	err = db.Create(n).Error
+	if err != nil {
+		return
+	}
+	return n.Msg(UnreadNotificationCount(db, n.UserID)), nil
+}
+
+// SendNotification 保存通知并推送给在线的用户，失败只记录日志，不影响触发通知的操作
+func SendNotification(db *gorm.DB, client *redis.Client, notification NotificationModel) {
+	msg, err := notification.Create(db)
+	if err != nil {
+		logx.Error(err)
+		return
+	}
+	redis_service.PublishNotification(client, notification.UserID, msg)
+}
+
+// Msg 转换成推送给客户端的通知消息
+func (n NotificationModel) Msg(unreadCount int64) ctype.NotificationMsg {
+	return ctype.NotificationMsg{
+		ID:          n.ID,
+		Type:        n.Type,
+		SourceID:    n.SourceID,
+		SendUserID:  n.SendUserID,
+		GroupID:     n.GroupID,
+		Title:       n.Title,
+		Content:     n.Content,
+		UnreadCount: unreadCount,
+	}
+}
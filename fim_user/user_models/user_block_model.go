@@ -0,0 +1,58 @@
+package user_models
+
+import (
+	"fim/common/models"
+	"gorm.io/gorm"
+)
+
+// UserBlockModel 用户黑名单表
+type UserBlockModel struct {
+	models.Model
+	UserID         uint      `gorm:"uniqueIndex:idx_user_block" json:"userID"`      // 拉黑方
+	UserModel      UserModel `gorm:"foreignKey:UserID" json:"-"`                    // 拉黑方
+	BlockUserID    uint      `gorm:"uniqueIndex:idx_user_block" json:"blockUserID"` // 被拉黑方
+	BlockUserModel UserModel `gorm:"foreignKey:BlockUserID" json:"-"`               // 被拉黑方
+}
+
+// IsBlock 检查A和B之间是否存在拉黑关系，任意一方拉黑了对方都返回true
+// 参数:
+//
+//	db: GORM数据库实例
+//	A: 用户A的ID
+//	B: 用户B的ID
+//
+// 返回值:
+//
+//	bool: A和B之间是否存在拉黑关系
+func (b *UserBlockModel) IsBlock(db *gorm.DB, A, B uint) bool {
+	err := db.Take(b, "(user_id = ? and block_user_id = ?) or (user_id = ? and block_user_id = ?)", A, B, B, A).Error
+	return err == nil
+}
+
+// IsBlockBy 检查用户userID是否拉黑了blockUserID，只判断单个方向
+func (b *UserBlockModel) IsBlockBy(db *gorm.DB, userID, blockUserID uint) bool {
+	err := db.Take(b, "user_id = ? and block_user_id = ?", userID, blockUserID).Error
+	return err == nil
+}
+
+// BlockUserIDList 获取和userID存在拉黑关系的所有用户id，包括他拉黑的人和拉黑他的人
+// 参数:
+//
+//	db: GORM数据库实例
+//	userID: 查询用户的ID
+//
+// 返回值:
+//
+//	[]uint: 用户id列表
+func (b *UserBlockModel) BlockUserIDList(db *gorm.DB, userID uint) (list []uint) {
+	var blockList []UserBlockModel
+	db.Find(&blockList, "user_id = ? or block_user_id = ?", userID, userID)
+	for _, model := range blockList {
+		if model.UserID == userID {
+			list = append(list, model.BlockUserID)
+			continue
+		}
+		list = append(list, model.UserID)
+	}
+	return
+}
@@ -3,6 +3,7 @@ package user_models
 import (
 	"fim/common/models"
 	"fim/common/models/ctype"
+	"time"
 )
 
 type UserConfModel struct {
@@ -17,9 +18,16 @@ type UserConfModel struct {
 	SearchUser           int8                        `json:"search_user"`                   //别人查找你的方式 0不允许别人查找 1通过用户号 2通过昵称
 	Verification         int8                        `json:"verification"`                  //好友验证 0不允许任何人添加 1允许任何人添加 2需要验证消息 3需要回答问题 4需要正确回答问题
 	VerificationQuestion *ctype.VerificationQuestion `json:"verification_question"`         //验证问题 3 4
+	ProfileVisibility    *ctype.ProfileVisibility    `json:"profile_visibility"`            //扩展资料的可见范围
 	Online               bool                        `json:"online"`                        //在线状态
 	CurtailChat          bool                        `json:"curtail_chat"`                  //限制聊天
 	CurtailAddUser       bool                        `json:"curtail_add_user"`              //限制添加好友
 	CurtailCreateGroup   bool                        `json:"curtail_create_group"`          //限制建群
 	CurtailInGroupChat   bool                        `json:"curtail_in_group_chat"`         //限制群聊
+	ContactVersion       int64                       `json:"contact_version"`               //联系人版本，好友增删和备注修改时加一
+	PreferenceVersion    int64                       `json:"preference_version"`            //偏好设置版本，每次修改偏好设置时加一
+	Presence             int8                        `json:"presence"`                      //状态 0 在线 1 离开 2 忙碌
+	PresenceUpdateAt     *time.Time                  `json:"presence_update_at"`            //设置状态的时间
+	AutoReply            *ctype.AutoReply            `json:"auto_reply"`                    //自动回复设置
+	HideReadReceipt      bool                        `json:"hide_read_receipt"`             //不发送已读回执，别人看不到自己是否已读
 }
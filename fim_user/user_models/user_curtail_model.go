@@ -0,0 +1,147 @@
+package user_models
+
+import (
+	"errors"
+	"fim/common/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserCurtailModel 用户限制表，记录管理员对用户生效中的限制和封禁
+type UserCurtailModel struct {
+	models.Model
+	UserID       uint       `gorm:"index" json:"userID"`        // 被限制的用户
+	UserModel    UserModel  `gorm:"foreignKey:UserID" json:"-"` // 被限制的用户
+	AdminID      uint       `json:"adminID"`                    // 操作的管理员
+	Type         int8       `json:"type"`                       // 限制类型 1 限制聊天 2 限制添加好友 3 限制建群 4 限制群聊 5 封禁账号
+	Reason       string     `gorm:"size:128" json:"reason"`     // 限制原因
+	ExpireAt     *time.Time `gorm:"index" json:"expireAt"`      // 到期时间，为空表示永久
+	PrevStatus   int8       `json:"-"`                          // 封禁账号之前的账号状态，解除封禁的时候恢复
+	PrevDeleteAt *time.Time `json:"-"`                          // 封禁账号之前的注销生效时间，解除封禁的时候恢复
+}
+
+// ErrCurtailBanStatus 只有正常、停用和注销中的账号可以封禁
+var ErrCurtailBanStatus = errors.New("账号当前状态不能封禁")
+
+// UserCurtailLogModel 用户限制操作日志表
+type UserCurtailLogModel struct {
+	models.Model
+	UserID   uint       `gorm:"index" json:"userID"`    // 被操作的用户
+	AdminID  uint       `json:"adminID"`                // 操作的管理员，到期自动解除的时候为0
+	Type     int8       `json:"type"`                   // 限制类型 1 限制聊天 2 限制添加好友 3 限制建群 4 限制群聊 5 封禁账号
+	Action   int8       `json:"action"`                 // 操作 1 添加限制 2 解除限制 3 到期自动解除
+	Reason   string     `gorm:"size:128" json:"reason"` // 操作原因
+	ExpireAt *time.Time `json:"expireAt"`               // 添加限制时设置的到期时间
+}
+
+// curtailColumns 限制类型对应的用户配置字段
+var curtailColumns = map[int8]string{
+	1: "curtail_chat",
+	2: "curtail_add_user",
+	3: "curtail_create_group",
+	4: "curtail_in_group_chat",
+}
+
+// curtailNames 限制类型的名称
+var curtailNames = map[int8]string{
+	1: "限制聊天",
+	2: "限制添加好友",
+	3: "限制建群",
+	4: "限制群聊",
+	5: "封禁账号",
+}
+
+// Name 限制类型的名称
+func (c UserCurtailModel) Name() string {
+	return curtailNames[c.Type]
+}
+
+// IsValidCurtailType 判断限制类型是否合法
+func IsValidCurtailType(curtailType int8) bool {
+	_, ok := curtailColumns[curtailType]
+	return ok || curtailType == 5
+}
+
+// Apply 让限制生效或者解除限制
+// 限制聊天、添加好友、建群、群聊修改用户配置里对应的字段，封禁账号修改用户的账号状态
+// 封禁的时候把原来的账号状态和注销时间记到限制记录上，解除封禁的时候原样恢复，注销中的账号解封之后继续注销
+// 参数:
+//
+//	db: GORM数据库实例
+//	enable: true 生效 false 解除
+//
+// 返回值:
+//
+//	error: 更新失败时返回错误，账号状态不能封禁时返回ErrCurtailBanStatus
+func (c *UserCurtailModel) Apply(db *gorm.DB, enable bool) error {
+	if c.Type != 5 {
+		return db.Model(&UserConfModel{}).Where("user_id = ?", c.UserID).Update(curtailColumns[c.Type], enable).Error
+	}
+	var user UserModel
+	err := db.Take(&user, c.UserID).Error
+	if err != nil {
+		return err
+	}
+	if !enable {
+		// 账号在封禁期间已经被注销了，不能再恢复
+		if user.Status != 4 {
+			return nil
+		}
+		return db.Model(&user).Updates(map[string]any{
+			"status":    c.PrevStatus,
+			"delete_at": c.PrevDeleteAt,
+		}).Error
+	}
+	// 已经封禁的账号只更新封禁的原因和时长，保留第一次封禁之前的状态
+	if user.Status == 4 {
+		return nil
+	}
+	if user.Status > 2 {
+		return ErrCurtailBanStatus
+	}
+	c.PrevStatus = user.Status
+	c.PrevDeleteAt = user.DeleteAt
+	err = db.Model(c).Updates(map[string]any{
+		"prev_status":    c.PrevStatus,
+		"prev_delete_at": c.PrevDeleteAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+	return db.Model(&user).Updates(map[string]any{
+		"status":    4,
+		"delete_at": nil,
+	}).Error
+}
+
+// Remove 解除限制，删除限制记录并写入操作日志
+// 参数:
+//
+//	db: GORM数据库实例
+//	adminID: 操作的管理员，到期自动解除的时候为0
+//	action: 日志操作类型 2 解除限制 3 到期自动解除
+//	reason: 解除原因
+//
+// 返回值:
+//
+//	error: 操作失败时返回错误
+func (c *UserCurtailModel) Remove(db *gorm.DB, adminID uint, action int8, reason string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := c.Apply(tx, false)
+		if err != nil {
+			return err
+		}
+		err = tx.Delete(c).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&UserCurtailLogModel{
+			UserID:  c.UserID,
+			AdminID: adminID,
+			Type:    c.Type,
+			Action:  action,
+			Reason:  reason,
+		}).Error
+	})
+}
@@ -1,22 +1,66 @@
 package user_models
 
-import "fim/common/models"
+import (
+	"fim/common/models"
+	"time"
+)
 
 // UserModel 用户表
 type UserModel struct {
 	models.Model
 	Pwd            string         `gorm:"size:64" json:"-"`
 	Nickname       string         `gorm:"size:32" json:"nickname"`
+	Handle         *string        `gorm:"size:20;uniqueIndex" json:"handle"` // 用户号，全局唯一，统一小写存储，没设置的时候为空
+	HandleUpdateAt *time.Time     `json:"handleUpdateAt"`                    // 上次修改用户号的时间
 	Abstract       string         `gorm:"size:128" json:"abstract"`
 	Avatar         string         `gorm:"size:256" json:"avatar"`
+	Gender         int8           `json:"gender"`                     // 性别 0 未知 1 男 2 女
+	Birthday       string         `gorm:"size:10" json:"birthday"`    // 生日 2006-01-02
+	Region         string         `gorm:"size:64" json:"region"`      // 地区
+	Signature      string         `gorm:"size:128" json:"signature"`  // 个性签名
+	CoverImage     string         `gorm:"size:256" json:"coverImage"` // 资料卡背景图
 	IP             string         `gorm:"size:32" json:"ip"`
 	Addr           string         `gorm:"size:64" json:"addr"`
 	Role           int8           `json:"role"`                          // 角色 1 管理员  2 普通用户
 	OpenID         string         `gorm:"size:64" json:"-"`              // 第三方平台登录的凭证
 	RegisterSource string         `gorm:"size:16" json:"registerSource"` // 注册来源
+	Status         int8           `json:"status"`                        // 账号状态 0 正常 1 已停用 2 注销中 3 已注销 4 已封禁
+	DeleteAt       *time.Time     `json:"deleteAt"`                      // 注销生效时间，注销中的账号到期后清理数据
 	UserConfModel  *UserConfModel `gorm:"foreignKey:UserID" json:"UserConfModel"`
 }
 
+// IsActive 账号是否正常，停用、注销中和已注销的账号不对外展示资料
+func (u UserModel) IsActive() bool {
+	return u.Status == 0
+}
+
+// FilterProfile 按照用户设置的可见范围，清空查看者不能看到的扩展资料
+// 需要预加载UserConfModel，没有可见范围设置的时候所有人可见
+func (u *UserModel) FilterProfile(isFriend bool) {
+	if u.UserConfModel == nil || u.UserConfModel.ProfileVisibility == nil {
+		return
+	}
+	visibility := *u.UserConfModel.ProfileVisibility
+	if !visibility.Visible(visibility.Gender, isFriend) {
+		u.Gender = 0
+	}
+	if !visibility.Visible(visibility.Birthday, isFriend) {
+		u.Birthday = ""
+	}
+	if !visibility.Visible(visibility.Region, isFriend) {
+		u.Region = ""
+	}
+	if !visibility.Visible(visibility.Signature, isFriend) {
+		u.Signature = ""
+	}
+	if !visibility.Visible(visibility.CoverImage, isFriend) {
+		u.CoverImage = ""
+	}
+	if !visibility.Visible(visibility.Addr, isFriend) {
+		u.Addr = ""
+	}
+}
+
 func (uc UserConfModel) ProblemCount() (c int) {
 	if uc.VerificationQuestion != nil {
 		if uc.VerificationQuestion.Problem1 != nil {
@@ -0,0 +1,138 @@
+package user_models
+
+import (
+	"errors"
+	"fim/common/models"
+	"regexp"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 偏好设置的限制
+const (
+	PreferenceMaxKeys     = 100  // 每个命名空间最多的配置项
+	PreferenceMaxValueLen = 2048 // 配置值的最大长度
+)
+
+var (
+	preferenceNamespaceRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+	preferenceKeyRegexp       = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+)
+
+var (
+	// ErrPreferenceConflict 要修改的配置项在客户端的版本之后被其他设备修改过
+	ErrPreferenceConflict = errors.New("配置已在其他设备修改")
+	// ErrPreferenceTooMany 命名空间下的配置项超过上限
+	ErrPreferenceTooMany = errors.New("配置项数量超过上限")
+)
+
+// UserPreferenceModel 用户偏好设置表，按命名空间保存客户端的配置，多设备之间同步
+// 每个配置项记录最近一次修改时的偏好版本，删除的配置项保留记录，用于客户端增量同步
+type UserPreferenceModel struct {
+	models.Model
+	UserID    uint   `gorm:"uniqueIndex:idx_user_preference" json:"userID"`            // 配置所属的用户
+	Namespace string `gorm:"size:32;uniqueIndex:idx_user_preference" json:"namespace"` // 命名空间 例如 theme、notify、chat
+	Key       string `gorm:"size:64;uniqueIndex:idx_user_preference" json:"key"`       // 配置项
+	Value     string `gorm:"type:text" json:"value"`                                   // 配置值，由客户端自行解析
+	Version   int64  `gorm:"index" json:"version"`                                     // 最近一次修改时的偏好版本
+	IsRemoved bool   `json:"isRemoved"`                                                // 是否已经删除
+}
+
+// ValidPreferenceNamespace 校验命名空间
+func ValidPreferenceNamespace(namespace string) error {
+	if !preferenceNamespaceRegexp.MatchString(namespace) {
+		return errors.New("命名空间只能是小写字母开头的字母、数字和下划线，最长32位")
+	}
+	return nil
+}
+
+// ValidPreferenceKey 校验配置项和配置值
+func ValidPreferenceKey(key, value string) error {
+	if !preferenceKeyRegexp.MatchString(key) {
+		return errors.New("配置项只能是字母、数字、下划线、点和横线，最长64位")
+	}
+	if len(value) > PreferenceMaxValueLen {
+		return errors.New("配置值过长")
+	}
+	return nil
+}
+
+// PatchPreference 修改用户某个命名空间下的配置，偏好版本加一，修改和删除的配置项都记录为新的版本
+// baseVersion 为客户端本地的偏好版本，大于0的时候，要修改的配置项在这个版本之后被改过就返回冲突的配置项，不做任何修改
+// 参数:
+//
+//	db: GORM数据库实例
+//	userID: 用户id
+//	namespace: 命名空间
+//	baseVersion: 客户端本地的偏好版本 0表示强制覆盖
+//	set: 新增或者修改的配置项
+//	remove: 删除的配置项
+//
+// 返回值:
+//
+//	version: 修改之后的偏好版本
+//	conflictList: 冲突的配置项的当前值，不为空的时候err为ErrPreferenceConflict
+//	err: 修改失败时返回错误
+func PatchPreference(db *gorm.DB, userID uint, namespace string, baseVersion int64, set map[string]string, remove []string) (version int64, conflictList []UserPreferenceModel, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// 更新的时候锁住用户配置这一行，同一个用户的修改串行执行
+		err1 := tx.Model(&UserConfModel{}).Where("user_id = ?", userID).
+			UpdateColumn("preference_version", gorm.Expr("preference_version + 1")).Error
+		if err1 != nil {
+			return err1
+		}
+		err1 = tx.Model(&UserConfModel{}).Where("user_id = ?", userID).Select("preference_version").Scan(&version).Error
+		if err1 != nil {
+			return err1
+		}
+
+		var keyList = make([]string, 0, len(set)+len(remove))
+		for key := range set {
+			keyList = append(keyList, key)
+		}
+		keyList = append(keyList, remove...)
+		if baseVersion > 0 {
+			tx.Find(&conflictList, "user_id = ? and namespace = ? and `key` in ? and version > ?", userID, namespace, keyList, baseVersion)
+			if len(conflictList) > 0 {
+				return ErrPreferenceConflict
+			}
+		}
+
+		for key, value := range set {
+			err1 = tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "namespace"}, {Name: "key"}},
+				DoUpdates: clause.AssignmentColumns([]string{"value", "version", "is_removed", "updated_at"}),
+			}).Create(&UserPreferenceModel{
+				UserID:    userID,
+				Namespace: namespace,
+				Key:       key,
+				Value:     value,
+				Version:   version,
+			}).Error
+			if err1 != nil {
+				return err1
+			}
+		}
+		if len(remove) > 0 {
+			err1 = tx.Model(&UserPreferenceModel{}).
+				Where("user_id = ? and namespace = ? and `key` in ? and is_removed = ?", userID, namespace, remove, false).
+				Updates(map[string]any{
+					"value":      "",
+					"version":    version,
+					"is_removed": true,
+				}).Error
+			if err1 != nil {
+				return err1
+			}
+		}
+
+		var count int64
+		tx.Model(&UserPreferenceModel{}).Where("user_id = ? and namespace = ? and is_removed = ?", userID, namespace, false).Count(&count)
+		if count > PreferenceMaxKeys {
+			return ErrPreferenceTooMany
+		}
+		return nil
+	})
+	return
+}
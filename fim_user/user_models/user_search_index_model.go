@@ -0,0 +1,54 @@
+package user_models
+
+import (
+	"fim/common/models"
+	"fim/utils/pinyins"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserSearchIndexModel 用户搜索索引表，用户资料变化时同步更新
+type UserSearchIndexModel struct {
+	models.Model
+	UserID   uint   `gorm:"uniqueIndex" json:"userID"`
+	Nickname string `gorm:"size:32;index" json:"nickname"`                                                        // 昵称
+	Pinyin   string `gorm:"size:256;index" json:"pinyin"`                                                         // 昵称全拼
+	Initials string `gorm:"size:32;index" json:"initials"`                                                        // 昵称拼音首字母
+	Keywords string `gorm:"type:text;index:idx_keywords,class:FULLTEXT,option:WITH PARSER ngram" json:"keywords"` // 全文检索内容，昵称、简介及其拼音
+}
+
+// SyncUserSearchIndex 根据用户最新资料重建该用户的搜索索引
+// 参数:
+//
+//	db: GORM数据库实例
+//	userID: 用户ID
+//
+// 返回值:
+//
+//	error: 用户不存在或写入失败时返回错误
+func SyncUserSearchIndex(db *gorm.DB, userID uint) error {
+	var user UserModel
+	err := db.Take(&user, userID).Error
+	if err != nil {
+		return err
+	}
+	index := UserSearchIndexModel{
+		UserID:   user.ID,
+		Nickname: user.Nickname,
+		Pinyin:   pinyins.Full(user.Nickname),
+		Initials: pinyins.Initials(user.Nickname),
+	}
+	index.Keywords = strings.Join([]string{
+		user.Nickname,
+		user.Abstract,
+		index.Pinyin,
+		index.Initials,
+		pinyins.Full(user.Abstract),
+	}, " ")
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "nickname", "pinyin", "initials", "keywords"}),
+	}).Create(&index).Error
+}
@@ -2,6 +2,8 @@ package logic
 
 import (
 	"context"
+	"errors"
+	"fim/fim_user/user_models"
 
 	"fim/fim_user/user_rpc/internal/svc"
 	"fim/fim_user/user_rpc/types/user_rpc"
@@ -23,8 +25,22 @@ func NewUserBaseInfoLogic(ctx context.Context, svcCtx *svc.ServiceContext) *User
 	}
 }
 
+// UserBaseInfo 获取用户的昵称和头像，停用和注销的用户返回匿名信息
 func (l *UserBaseInfoLogic) UserBaseInfo(in *user_rpc.UserBaseInfoRequest) (*user_rpc.UserBaseInfoResponse, error) {
-	// todo: add your logic here and delete this line
-
-	return &user_rpc.UserBaseInfoResponse{}, nil
+	var user user_models.UserModel
+	err := l.svcCtx.DB.Take(&user, in.UserId).Error
+	if err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if !user.IsActive() {
+		return &user_rpc.UserBaseInfoResponse{
+			UserId:   in.UserId,
+			NickName: "已注销用户",
+		}, nil
+	}
+	return &user_rpc.UserBaseInfoResponse{
+		UserId:   in.UserId,
+		NickName: user.Nickname,
+		Avatar:   user.Avatar,
+	}, nil
 }
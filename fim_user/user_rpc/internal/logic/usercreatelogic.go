@@ -32,11 +32,10 @@ func (l *UserCreateLogic) UserCreate(in *user_rpc.UserCreateRequest) (*user_rpc.
 	// 初始化一个 UserModel 对象，用于后续的数据操作。
 	var user user_models.UserModel
 
-	// 尝试根据 OpenID 查找已存在的用户，如果存在则更新，否则创建新用户。
+	// 尝试根据 OpenID 查找已存在的用户，已经注册过的直接返回，否则创建新用户。
 	err := l.svcCtx.DB.Take(&user, "open_id =?", in.OpenId).Error
-	if err != nil {
-		// 如果查找失败，返回“用户未找到”的错误。
-		return nil, errors.New("user not found")
+	if err == nil {
+		return &user_rpc.UserCreateResponse{UserId: int32(user.ID)}, nil
 	}
 
 	// 更新用户信息，准备创建新用户。
@@ -68,6 +67,11 @@ func (l *UserCreateLogic) UserCreate(in *user_rpc.UserCreateRequest) (*user_rpc.
 
 		Online: true,
 	})
+	// 建立用户搜索索引
+	err = user_models.SyncUserSearchIndex(l.svcCtx.DB, user.ID)
+	if err != nil {
+		logx.Error(err)
+	}
 	// 返回创建成功的用户ID。
 	return &user_rpc.UserCreateResponse{UserId: int32(user.ID)}, nil
 }
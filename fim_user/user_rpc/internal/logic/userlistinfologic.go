@@ -2,6 +2,8 @@ package logic
 
 import (
 	"context"
+	"errors"
+	"fim/fim_user/user_models"
 
 	"fim/fim_user/user_rpc/internal/svc"
 	"fim/fim_user/user_rpc/types/user_rpc"
@@ -23,8 +25,27 @@ func NewUserListInfoLogic(ctx context.Context, svcCtx *svc.ServiceContext) *User
 	}
 }
 
+// UserListInfo 批量获取用户的昵称和头像，一次查询数据库，停用和注销的用户返回匿名信息
 func (l *UserListInfoLogic) UserListInfo(in *user_rpc.UserListInfoRequest) (*user_rpc.UserListInfoResponse, error) {
-	// todo: add your logic here and delete this line
-
-	return &user_rpc.UserListInfoResponse{}, nil
+	resp := &user_rpc.UserListInfoResponse{UserInfo: map[uint32]*user_rpc.UserInfo{}}
+	if len(in.UserIdList) == 0 {
+		return resp, nil
+	}
+	var userList []user_models.UserModel
+	err := l.svcCtx.DB.Select("id", "nickname", "avatar", "status").Find(&userList, "id in ?", in.UserIdList).Error
+	if err != nil {
+		logx.Error(err)
+		return nil, errors.New("获取用户信息失败")
+	}
+	for _, user := range userList {
+		if !user.IsActive() {
+			resp.UserInfo[uint32(user.ID)] = &user_rpc.UserInfo{NickName: "已注销用户"}
+			continue
+		}
+		resp.UserInfo[uint32(user.ID)] = &user_rpc.UserInfo{
+			NickName: user.Nickname,
+			Avatar:   user.Avatar,
+		}
+	}
+	return resp, nil
 }
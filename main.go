@@ -6,12 +6,16 @@ import (
 	"fim/fim_file/file_model"
 	"fim/fim_group/group_models"
 	"fim/fim_user/user_models"
+	"fim/utils/pinyins"
 	"flag"
 	"fmt"
+	"gorm.io/gorm"
 )
 
 type Options struct {
-	DB bool
+	DB          bool
+	SearchIndex bool
+	MsgSeq      bool
 }
 
 func main() {
@@ -24,6 +28,8 @@ func main() {
 	// - false: 参数的默认值，表示在未明确指定时，默认不使用数据库
 	// - "db": 对命令行参数的描述信息
 	flag.BoolVar(&opt.DB, "db", false, "db")
+	flag.BoolVar(&opt.SearchIndex, "search_index", false, "重建用户搜索索引，补全备注和群昵称的拼音")
+	flag.BoolVar(&opt.MsgSeq, "msg_seq", false, "给没有序号的历史私聊和群聊消息补上会话序号")
 	flag.Parse() // 解析命令行参数
 
 	if opt.DB {
@@ -39,20 +45,36 @@ func main() {
 		// err - 执行迁移过程中遇到的任何错误。
 
 		err := db.AutoMigrate(
-			&user_models.UserModel{},                // 用户表
-			&user_models.FriendModel{},              // 好友表
-			&user_models.FriendVerifyModel{},        // 好友验证表
-			&user_models.UserConfModel{},            // 用户配置表
-			&chat_models.ChatModel{},                // 对话表
-			&chat_models.TopUserModel{},             // 置顶用户表
-			&chat_models.UserChatDeleteModel{},      // 用户删除聊天记录表
-			&group_models.GroupModel{},              // 群组表
-			&group_models.GroupMsgModel{},           // 群消息表
-			&group_models.GroupVerifyModel{},        // 群验证表
-			&group_models.GroupMemberModel{},        // 群成员表
-			&group_models.GroupUserMsgDeleteModel{}, // 用户删除聊天记录表
-			&group_models.GroupUserTopModel{},       // 用户置顶群聊表
-			&file_model.FileModel{},                 // 文件表
+			&user_models.UserModel{},                   // 用户表
+			&user_models.FriendModel{},                 // 好友表
+			&user_models.FriendVerifyModel{},           // 好友验证表
+			&user_models.UserConfModel{},               // 用户配置表
+			&user_models.UserBlockModel{},              // 用户黑名单表
+			&user_models.FriendRecommendDismissModel{}, // 好友推荐忽略表
+			&user_models.UserSearchIndexModel{},        // 用户搜索索引表
+			&user_models.UserCurtailModel{},            // 用户限制表
+			&user_models.UserCurtailLogModel{},         // 用户限制日志表
+			&user_models.InviteModel{},                 // 邀请链接表
+			&user_models.ContactChangeModel{},          // 联系人变更记录表
+			&user_models.NotificationModel{},           // 通知表
+			&user_models.UserPreferenceModel{},         // 用户偏好设置表
+			&user_models.LoginLogModel{},               // 登录记录表
+			&chat_models.ChatModel{},                   // 对话表
+			&chat_models.TopUserModel{},                // 置顶用户表
+			&chat_models.UserChatDeleteModel{},         // 用户删除聊天记录表
+			&chat_models.ChatSeqModel{},                // 私聊会话序号表
+			&chat_models.ChatReadModel{},               // 私聊已读位置表
+			&chat_models.ChatRevisionModel{},           // 私聊消息编辑历史表
+			&chat_models.ChatReactionModel{},           // 私聊消息表情回应表
+			&group_models.GroupModel{},                 // 群组表
+			&group_models.GroupMsgModel{},              // 群消息表
+			&group_models.GroupMsgRevisionModel{},      // 群消息编辑历史表
+			&group_models.GroupMsgReactionModel{},      // 群消息表情回应表
+			&group_models.GroupVerifyModel{},           // 群验证表
+			&group_models.GroupMemberModel{},           // 群成员表
+			&group_models.GroupUserMsgDeleteModel{},    // 用户删除聊天记录表
+			&group_models.GroupUserTopModel{},          // 用户置顶群聊表
+			&file_model.FileModel{},                    // 文件表
 			//&logs_model.LogModel{},                  // 日志表
 			//&settings_model.SettingsModel{},         // 系统表
 		)
@@ -64,4 +86,82 @@ func main() {
 
 	}
 
+	if opt.SearchIndex {
+		db := core.InitGorm("root:root@tcp(localhost:3306)/fim_db?charset=utf8mb4&parseTime=True&loc=Local")
+		// 为所有已存在的用户重建搜索索引
+		var userIDList []uint
+		db.Model(&user_models.UserModel{}).Pluck("id", &userIDList)
+		for _, userID := range userIDList {
+			err := user_models.SyncUserSearchIndex(db, userID)
+			if err != nil {
+				fmt.Println("用户搜索索引重建失败", userID, err)
+			}
+		}
+		fmt.Printf("用户搜索索引重建完成，共%d个用户\n", len(userIDList))
+
+		// 补全好友备注和群昵称的拼音
+		var friendList []user_models.FriendModel
+		db.Find(&friendList, "sen_user_notice <> '' or rev_user_notice <> ''")
+		for _, friend := range friendList {
+			db.Model(&friend).Updates(map[string]any{
+				"sen_user_notice_pinyin":   pinyins.Full(friend.SenUserNotice),
+				"sen_user_notice_initials": pinyins.Initials(friend.SenUserNotice),
+				"rev_user_notice_pinyin":   pinyins.Full(friend.RevUserNotice),
+				"rev_user_notice_initials": pinyins.Initials(friend.RevUserNotice),
+			})
+		}
+		var memberList []group_models.GroupMemberModel
+		db.Find(&memberList, "member_nickname <> ''")
+		for _, member := range memberList {
+			db.Model(&member).Updates(map[string]any{
+				"member_nickname_pinyin":   pinyins.Full(member.MemberNickname),
+				"member_nickname_initials": pinyins.Initials(member.MemberNickname),
+			})
+		}
+		fmt.Printf("拼音补全完成，共%d个好友备注，%d个群昵称\n", len(friendList), len(memberList))
+	}
+
+	if opt.MsgSeq {
+		db := core.InitGorm("root:root@tcp(localhost:3306)/fim_db?charset=utf8mb4&parseTime=True&loc=Local")
+		// 按消息id的顺序分配序号，需要在新版本上线之前执行，否则历史消息会排在新消息之后
+		var chatList []chat_models.ChatModel
+		db.Select("id", "send_user_id", "rev_user_id").Where("seq = 0").Order("id").Find(&chatList)
+		for _, chat := range chatList {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				seq, err := chat_models.NextChatSeq(tx, chat.SendUserID, chat.RevUserID)
+				if err != nil {
+					return err
+				}
+				return tx.Model(&chat).Updates(map[string]any{
+					"conversation_id": chat_models.ConversationID(chat.SendUserID, chat.RevUserID),
+					"seq":             seq,
+				}).Error
+			})
+			if err != nil {
+				fmt.Println("私聊消息序号补全失败", chat.ID, err)
+			}
+		}
+		var groupMsgList []group_models.GroupMsgModel
+		db.Select("id", "group_id").Where("seq = 0").Order("id").Find(&groupMsgList)
+		for _, groupMsg := range groupMsgList {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				err := tx.Model(&group_models.GroupModel{}).Where("id = ?", groupMsg.GroupID).
+					UpdateColumn("msg_seq", gorm.Expr("msg_seq + 1")).Error
+				if err != nil {
+					return err
+				}
+				var seq int64
+				err = tx.Model(&group_models.GroupModel{}).Where("id = ?", groupMsg.GroupID).Select("msg_seq").Scan(&seq).Error
+				if err != nil {
+					return err
+				}
+				return tx.Model(&groupMsg).UpdateColumn("seq", seq).Error
+			})
+			if err != nil {
+				fmt.Println("群消息序号补全失败", groupMsg.ID, err)
+			}
+		}
+		fmt.Printf("消息序号补全完成，共%d条私聊消息，%d条群消息\n", len(chatList), len(groupMsgList))
+	}
+
 }
@@ -0,0 +1,43 @@
+package handles
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// 用户号以字母开头，6到20位，只能包含字母、数字、下划线和减号
+var handleRegexp = regexp.MustCompile(`^[a-z][a-z0-9_-]{5,19}$`)
+
+// reservedWords 保留的用户号，不能被用户占用
+var reservedWords = []string{
+	"root", "support", "service", "null", "undefined", "anonymous", "guest",
+}
+
+// reservedPrefixes 容易被冒充成官方账号的前缀
+var reservedPrefixes = []string{
+	"admin", "system", "official", "fim_", "fim-",
+}
+
+// Normalize 用户号不区分大小写，统一转成小写之后存储和比较
+func Normalize(handle string) string {
+	return strings.ToLower(strings.TrimSpace(handle))
+}
+
+// Validate 校验用户号的格式和保留字，传入之前需要先Normalize
+func Validate(handle string) error {
+	if !handleRegexp.MatchString(handle) {
+		return errors.New("用户号需要以字母开头，6-20位字母、数字、下划线或减号")
+	}
+	for _, word := range reservedWords {
+		if handle == word {
+			return errors.New("该用户号为系统保留，请换一个")
+		}
+	}
+	for _, prefix := range reservedPrefixes {
+		if strings.HasPrefix(handle, prefix) {
+			return errors.New("该用户号为系统保留，请换一个")
+		}
+	}
+	return nil
+}
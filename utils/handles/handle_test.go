@@ -0,0 +1,34 @@
+package handles
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	list := map[string]bool{
+		"barton_01":             true,
+		"Barton_01":             false, // 没有Normalize
+		"1barton":               false,
+		"abc":                   false,
+		"abcdefghijklmnopqrstu": false,
+		"abc.def":               false,
+		"admin_user":            false,
+		"fim_user01":            false,
+		"guest_user":            true,
+		"support":               false,
+	}
+	for handle, ok := range list {
+		err := Validate(handle)
+		fmt.Println(handle, err)
+		if (err == nil) != ok {
+			t.Error("用户号校验错误", handle)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if Normalize(" Barton_01 ") != "barton_01" {
+		t.Error("用户号格式化错误")
+	}
+}
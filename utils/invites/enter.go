@@ -0,0 +1,82 @@
+package invites
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/skip2/go-qrcode"
+)
+
+// 邀请类型
+const (
+	UserInvite  int8 = 1 // 加好友邀请
+	GroupInvite int8 = 2 // 加群邀请
+)
+
+// InvitePayLoad 邀请链接中携带的信息
+type InvitePayLoad struct {
+	InviteID uint `json:"inviteID"` // 邀请记录ID，用于校验使用次数和撤销
+	Type     int8 `json:"type"`     // 邀请类型 1 好友 2 群聊
+	TargetID uint `json:"targetID"` // 邀请人或群聊的ID
+}
+
+type CustomClaims struct {
+	InvitePayLoad
+	jwt.RegisteredClaims
+}
+
+// GenerateToken 生成签名的邀请token
+// @param payload InvitePayLoad 邀请信息
+// @param secret string，用于签名的密钥
+// @param expireAt time.Time，过期时间
+func GenerateToken(payload InvitePayLoad, secret string, expireAt time.Time) (string, error) {
+	claim := CustomClaims{
+		InvitePayLoad: payload,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expireAt),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
+	return token.SignedString([]byte(secret))
+}
+
+// ParseToken 校验邀请token的签名和有效期，并返回邀请信息
+func ParseToken(tokenString string, secret string) (*CustomClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("签名方式错误")
+		}
+		return []byte(secret), nil
+	})
+	if err != nil {
+		var ve *jwt.ValidationError
+		if errors.As(err, &ve) && ve.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, errors.New("邀请链接已过期")
+		}
+		return nil, errors.New("邀请链接无效")
+	}
+	if claims, ok := token.Claims.(*CustomClaims); ok && token.Valid {
+		return claims, nil
+	}
+	return nil, errors.New("邀请链接无效")
+}
+
+// TokenLink 拼接邀请链接
+func TokenLink(base string, token string) string {
+	return base + "?" + url.Values{"token": {token}}.Encode()
+}
+
+// HandleLink 拼接用户号名片链接，通过用户号打开的邀请不限次数，长期有效
+func HandleLink(base string, handle string) string {
+	return base + "?" + url.Values{"handle": {handle}}.Encode()
+}
+
+// QRCode 把邀请链接生成二维码图片，返回png数据
+func QRCode(link string, size int) ([]byte, error) {
+	if size <= 0 {
+		size = 256
+	}
+	return qrcode.Encode(link, qrcode.Medium, size)
+}
@@ -0,0 +1,49 @@
+package invites
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestGenerateToken(t *testing.T) {
+	token, err := GenerateToken(InvitePayLoad{
+		InviteID: 1,
+		Type:     UserInvite,
+		TargetID: 2,
+	}, "123456", time.Now().Add(time.Hour))
+	fmt.Println(token, err)
+	claims, err := ParseToken(token, "123456")
+	if err != nil || claims.InviteID != 1 || claims.Type != UserInvite || claims.TargetID != 2 {
+		t.Error("邀请token解析错误", err)
+	}
+	_, err = ParseToken(token, "654321")
+	if err == nil {
+		t.Error("密钥错误的token不应该通过校验")
+	}
+}
+
+func TestParseExpiredToken(t *testing.T) {
+	token, _ := GenerateToken(InvitePayLoad{InviteID: 1, Type: GroupInvite, TargetID: 2}, "123456", time.Now().Add(-time.Minute))
+	_, err := ParseToken(token, "123456")
+	fmt.Println(err)
+	if err == nil || err.Error() != "邀请链接已过期" {
+		t.Error("过期的token不应该通过校验")
+	}
+}
+
+func TestQRCode(t *testing.T) {
+	byteData, err := QRCode("fim://invite?token=abc", 0)
+	if err != nil || len(byteData) < 8 || string(byteData[1:4]) != "PNG" {
+		t.Error("二维码生成错误", err)
+	}
+}
+
+func TestLink(t *testing.T) {
+	if TokenLink("http://127.0.0.1/invite", "a.b_c") != "http://127.0.0.1/invite?token=a.b_c" {
+		t.Error("邀请链接拼接错误")
+	}
+	if HandleLink("http://127.0.0.1/invite", "barton_01") != "http://127.0.0.1/invite?handle=barton_01" {
+		t.Error("用户号链接拼接错误")
+	}
+}
@@ -0,0 +1,204 @@
+package ips
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// ip2region xdb 数据文件的结构
+const (
+	headerInfoLength      = 256
+	vectorIndexRows       = 256
+	vectorIndexCols       = 256
+	vectorIndexSize       = 8
+	segmentIndexBlockSize = 14
+)
+
+// Region 离线IP归属地查询，使用ip2region的xdb数据文件，整个文件加载到内存里，可以并发查询
+type Region struct {
+	content []byte
+}
+
+// NewRegion 从本地文件加载ip2region的xdb数据
+func NewRegion(path string) (*Region, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewRegionWithBuffer(content)
+}
+
+// NewRegionWithBuffer 使用已经读取到内存的xdb数据
+func NewRegionWithBuffer(content []byte) (*Region, error) {
+	if len(content) < headerInfoLength+vectorIndexRows*vectorIndexCols*vectorIndexSize {
+		return nil, errors.New("ip2region数据文件格式错误")
+	}
+	return &Region{content: content}, nil
+}
+
+// Search 查询IPv4地址的原始归属地信息，格式为 国家|区域|省份|城市|ISP，未知的部分为0
+func (r *Region) Search(ip string) (string, error) {
+	ipv4 := net.ParseIP(ip).To4()
+	if ipv4 == nil {
+		return "", errors.New("不是IPv4地址")
+	}
+	ipNum := binary.BigEndian.Uint32(ipv4)
+
+	// 先通过前两个字节定位段索引的范围，再在范围内二分查找
+	idx := headerInfoLength + int(ipv4[0])*vectorIndexCols*vectorIndexSize + int(ipv4[1])*vectorIndexSize
+	sPtr := int(binary.LittleEndian.Uint32(r.content[idx:]))
+	ePtr := int(binary.LittleEndian.Uint32(r.content[idx+4:]))
+	if sPtr == 0 && ePtr == 0 {
+		return "", nil
+	}
+
+	low, high := 0, (ePtr-sPtr)/segmentIndexBlockSize
+	for low <= high {
+		mid := (low + high) / 2
+		p := sPtr + mid*segmentIndexBlockSize
+		if p+segmentIndexBlockSize > len(r.content) {
+			return "", errors.New("ip2region数据文件格式错误")
+		}
+		startIP := binary.LittleEndian.Uint32(r.content[p:])
+		if ipNum < startIP {
+			high = mid - 1
+			continue
+		}
+		endIP := binary.LittleEndian.Uint32(r.content[p+4:])
+		if ipNum > endIP {
+			low = mid + 1
+			continue
+		}
+		dataLen := int(binary.LittleEndian.Uint16(r.content[p+8:]))
+		dataPtr := int(binary.LittleEndian.Uint32(r.content[p+10:]))
+		if dataPtr+dataLen > len(r.content) {
+			return "", errors.New("ip2region数据文件格式错误")
+		}
+		return string(r.content[dataPtr : dataPtr+dataLen]), nil
+	}
+	return "", nil
+}
+
+// Addr 查询IP的归属地，国内的返回 省份 城市，国外的返回 国家 省份，内网地址返回 内网地址，查不到返回空字符串
+// 没有加载数据文件的时候(r为nil)只能识别内网地址
+func (r *Region) Addr(ip string) string {
+	netIP := net.ParseIP(ip)
+	if netIP == nil {
+		return ""
+	}
+	if netIP.IsLoopback() || netIP.IsPrivate() {
+		return "内网地址"
+	}
+	if r == nil {
+		return ""
+	}
+	info, err := r.Search(ip)
+	if err != nil || info == "" {
+		return ""
+	}
+	return formatRegion(info)
+}
+
+// formatRegion 把 国家|区域|省份|城市|ISP 格式化成展示用的归属地
+func formatRegion(info string) string {
+	parts := strings.Split(info, "|")
+	for len(parts) < 5 {
+		parts = append(parts, "0")
+	}
+	country, province, city := parts[0], parts[2], parts[3]
+	if city == "内网IP" {
+		return "内网地址"
+	}
+
+	var list []string
+	if country != "中国" && country != "0" {
+		list = append(list, country)
+	}
+	if province != "0" {
+		list = append(list, province)
+	}
+	// 直辖市的省份和城市重复，只保留城市
+	if city != "0" && country == "中国" {
+		if len(list) > 0 && strings.HasPrefix(city, list[len(list)-1]) {
+			list = list[:len(list)-1]
+		}
+		list = append(list, city)
+	}
+	if len(list) == 0 && country != "0" {
+		list = append(list, country)
+	}
+	return strings.Join(list, " ")
+}
+
+// ParseTrustedProxies 解析受信任的代理地址，支持单个IP和CIDR网段，例如 "127.0.0.1" "10.0.0.0/8"
+func ParseTrustedProxies(list []string) ([]*net.IPNet, error) {
+	var netList []*net.IPNet
+	for _, item := range list {
+		item = strings.TrimSpace(item)
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, errors.New("受信任的代理地址错误：" + item)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			netList = append(netList, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, errors.New("受信任的代理地址错误：" + item)
+		}
+		netList = append(netList, ipNet)
+	}
+	return netList, nil
+}
+
+// ClientIP 获取请求的客户端IP
+// 直接连过来的地址不是受信任的代理时，就是客户端的地址，X-Forwarded-For 可以被客户端伪造，不能使用
+// 经过受信任的代理时，从 X-Forwarded-For 的最右边往左找，第一个不是受信任代理的地址就是客户端的地址
+func ClientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	if !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+	var hopList []string
+	for _, forwarded := range r.Header.Values("X-Forwarded-For") {
+		hopList = append(hopList, strings.Split(forwarded, ",")...)
+	}
+	for i := len(hopList) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hopList[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !isTrustedProxy(hop, trustedProxies) {
+			break
+		}
+	}
+	return ip
+}
+
+// isTrustedProxy 判断地址是不是受信任的代理
+func isTrustedProxy(ip string, trustedProxies []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, ipNet := range trustedProxies {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
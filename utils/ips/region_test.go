@@ -0,0 +1,107 @@
+package ips
+
+import (
+	"encoding/binary"
+	"net"
+	"net/http"
+	"testing"
+)
+
+// buildXdb 按照xdb的格式生成测试数据，每个段的前两个字节必须相同
+func buildXdb(t *testing.T, segments [][3]string) []byte {
+	content := make([]byte, headerInfoLength+vectorIndexRows*vectorIndexCols*vectorIndexSize)
+	type block struct {
+		start, end uint32
+		ptr        int
+		size       int
+	}
+	var blocks []block
+	for _, seg := range segments {
+		ptr := len(content)
+		content = append(content, seg[2]...)
+		blocks = append(blocks, block{
+			start: binary.BigEndian.Uint32(net.ParseIP(seg[0]).To4()),
+			end:   binary.BigEndian.Uint32(net.ParseIP(seg[1]).To4()),
+			ptr:   ptr,
+			size:  len(seg[2]),
+		})
+	}
+	for _, b := range blocks {
+		p := len(content)
+		buf := make([]byte, segmentIndexBlockSize)
+		binary.LittleEndian.PutUint32(buf, b.start)
+		binary.LittleEndian.PutUint32(buf[4:], b.end)
+		binary.LittleEndian.PutUint16(buf[8:], uint16(b.size))
+		binary.LittleEndian.PutUint32(buf[10:], uint32(b.ptr))
+		content = append(content, buf...)
+
+		idx := headerInfoLength + int(b.start>>24)*vectorIndexCols*vectorIndexSize + int(b.start>>16&0xff)*vectorIndexSize
+		if binary.LittleEndian.Uint32(content[idx:]) == 0 {
+			binary.LittleEndian.PutUint32(content[idx:], uint32(p))
+		}
+		binary.LittleEndian.PutUint32(content[idx+4:], uint32(p))
+	}
+	return content
+}
+
+func TestRegion(t *testing.T) {
+	content := buildXdb(t, [][3]string{
+		{"1.2.0.0", "1.2.0.255", "中国|0|广东省|深圳市|电信"},
+		{"1.2.1.0", "1.2.3.255", "中国|0|北京|北京市|联通"},
+		{"1.2.4.0", "1.2.255.255", "美国|0|加利福尼亚|0|0"},
+	})
+	region, err := NewRegionWithBuffer(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"1.2.0.8":     "广东省 深圳市",
+		"1.2.2.1":     "北京市",
+		"1.2.100.1":   "美国 加利福尼亚",
+		"8.8.8.8":     "",
+		"192.168.1.1": "内网地址",
+		"127.0.0.1":   "内网地址",
+		"abc":         "",
+	}
+	for ip, want := range cases {
+		if got := region.Addr(ip); got != want {
+			t.Errorf("Addr(%s) = %q, want %q", ip, got, want)
+		}
+	}
+
+	var empty *Region
+	if got := empty.Addr("1.2.0.8"); got != "" {
+		t.Errorf("nil region Addr = %q", got)
+	}
+	if _, err = NewRegionWithBuffer([]byte("xdb")); err == nil {
+		t.Error("short content should fail")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:5678"
+	if ip := ClientIP(r, nil); ip != "10.0.0.1" {
+		t.Errorf("ClientIP = %s", ip)
+	}
+	// 没有配置受信任的代理，客户端自己传的 X-Forwarded-For 不能使用
+	r.Header.Set("X-Forwarded-For", "1.2.3.4, 10.0.0.2")
+	if ip := ClientIP(r, nil); ip != "10.0.0.1" {
+		t.Errorf("ClientIP = %s", ip)
+	}
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ip := ClientIP(r, trusted); ip != "1.2.3.4" {
+		t.Errorf("ClientIP = %s", ip)
+	}
+	// 客户端在最左边伪造的地址会被跳过，取最右边第一个不受信任的地址
+	r.Header.Set("X-Forwarded-For", "8.8.8.8, 1.2.3.4, 10.0.0.2")
+	if ip := ClientIP(r, trusted); ip != "1.2.3.4" {
+		t.Errorf("ClientIP = %s", ip)
+	}
+	if _, err = ParseTrustedProxies([]string{"abc"}); err == nil {
+		t.Error("invalid proxy should fail")
+	}
+}
@@ -37,6 +37,7 @@ func GenerateToken(payload JwtPayLoad, accessSecret string, expires int) (string
 		JwtPayLoad: payload,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(expires))), // 设置Token过期时间
+			IssuedAt:  jwt.NewNumericDate(time.Now()),                                         // 签发时间，账号停用之前签发的Token按它判断失效
 		},
 	}
 	// 使用HS256算法和声明创建一个新的Token
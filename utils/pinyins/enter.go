@@ -0,0 +1,68 @@
+package pinyins
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// args 拼音转换配置，非汉字字符原样保留（转成小写），方便混合昵称的检索
+var args = pinyin.Args{
+	Style: pinyin.Normal,
+	Fallback: func(r rune, a pinyin.Args) []string {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return []string{strings.ToLower(string(r))}
+		}
+		return []string{}
+	},
+}
+
+// Full 获取字符串的全拼，例如 "张三abc" -> "zhangsanabc"
+func Full(s string) string {
+	return strings.Join(pinyin.LazyPinyin(s, args), "")
+}
+
+// Initials 获取字符串的拼音首字母，例如 "张三abc" -> "zsabc"
+func Initials(s string) string {
+	var builder strings.Builder
+	for _, item := range pinyin.LazyPinyin(s, args) {
+		if item == "" {
+			continue
+		}
+		builder.WriteString(item[:1])
+	}
+	return builder.String()
+}
+
+// Letter 获取拼音的索引字母，A-Z之外的统一归到 "#"
+func Letter(full string) string {
+	if full == "" || full[0] < 'a' || full[0] > 'z' {
+		return "#"
+	}
+	return strings.ToUpper(full[:1])
+}
+
+// Less 按索引字母排序，"#" 排在最后，同一个字母内按全拼排序
+func Less(fullA, fullB string) bool {
+	letterA, letterB := Letter(fullA), Letter(fullB)
+	if letterA != letterB {
+		if letterA == "#" || letterB == "#" {
+			return letterB == "#"
+		}
+		return letterA < letterB
+	}
+	return fullA < fullB
+}
+
+// Match 判断关键字是否匹配，支持汉字、全拼和首字母，例如 "张三" "zhangsan" "zs" 都能匹配到张三
+func Match(key string, text string, full string, initials string) bool {
+	if key == "" {
+		return true
+	}
+	if strings.Contains(text, key) {
+		return true
+	}
+	lowerKey := strings.ToLower(key)
+	return strings.Contains(full, lowerKey) || strings.HasPrefix(initials, lowerKey)
+}
@@ -0,0 +1,44 @@
+package pinyins
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFull(t *testing.T) {
+	fmt.Println(Full("张三abc"))
+	if Full("张三abc") != "zhangsanabc" {
+		t.Error("全拼转换错误")
+	}
+}
+
+func TestInitials(t *testing.T) {
+	fmt.Println(Initials("张三abc"))
+	if Initials("张三abc") != "zsabc" {
+		t.Error("首字母转换错误")
+	}
+}
+
+func TestLetter(t *testing.T) {
+	if Letter(Full("张三")) != "Z" || Letter(Full("123")) != "#" || Letter("") != "#" {
+		t.Error("索引字母错误")
+	}
+}
+
+func TestLess(t *testing.T) {
+	if !Less(Full("阿三"), Full("张三")) || !Less(Full("张三"), Full("123")) || Less(Full("123"), Full("张三")) {
+		t.Error("拼音排序错误")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	full, initials := Full("张三"), Initials("张三")
+	for _, key := range []string{"张", "zhangsan", "ZhangS", "zs", ""} {
+		if !Match(key, "张三", full, initials) {
+			t.Error("拼音匹配错误", key)
+		}
+	}
+	if Match("ls", "张三", full, initials) {
+		t.Error("拼音匹配错误")
+	}
+}
@@ -0,0 +1,79 @@
+package typings
+
+import (
+	"sync"
+	"time"
+)
+
+// Tracker 正在输入状态的跟踪，状态只在内存里，不入库
+// 同一个key在interval之内重复的开始输入不转发，超过expire没有收到新的开始输入或者停止输入，自动按停止输入处理
+type Tracker struct {
+	interval time.Duration
+	expire   time.Duration
+	mu       sync.Mutex
+	items    map[string]*item
+}
+
+type item struct {
+	relayAt time.Time   // 上一次转发开始输入的时间
+	timer   *time.Timer // 自动过期的定时器
+}
+
+// NewTracker 创建正在输入状态的跟踪
+// @param interval time.Duration 同一个key转发开始输入的最小间隔
+// @param expire time.Duration 没有收到停止输入的时候自动过期的时间
+func NewTracker(interval, expire time.Duration) *Tracker {
+	return &Tracker{
+		interval: interval,
+		expire:   expire,
+		items:    map[string]*item{},
+	}
+}
+
+// Expire 自动过期的时间，转发给客户端，客户端也按这个时间隐藏正在输入
+func (t *Tracker) Expire() time.Duration {
+	return t.expire
+}
+
+// Start 收到开始输入，每次都会重新计算过期时间
+// 返回true表示需要转发，过期的时候调用onExpire，调用之前状态已经被清除
+func (t *Tracker) Start(key string, onExpire func()) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	it, ok := t.items[key]
+	if !ok {
+		it = &item{}
+		t.items[key] = it
+	} else {
+		it.timer.Stop()
+	}
+	it.timer = time.AfterFunc(t.expire, func() {
+		t.mu.Lock()
+		if t.items[key] != it {
+			t.mu.Unlock()
+			return
+		}
+		delete(t.items, key)
+		t.mu.Unlock()
+		onExpire()
+	})
+	if ok && now.Sub(it.relayAt) < t.interval {
+		return false
+	}
+	it.relayAt = now
+	return true
+}
+
+// Stop 收到停止输入，返回true表示之前正在输入，需要转发
+func (t *Tracker) Stop(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	it, ok := t.items[key]
+	if !ok {
+		return false
+	}
+	it.timer.Stop()
+	delete(t.items, key)
+	return true
+}
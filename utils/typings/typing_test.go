@@ -0,0 +1,45 @@
+package typings
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	tracker := NewTracker(50*time.Millisecond, 100*time.Millisecond)
+	if !tracker.Start("1_2", func() {}) {
+		t.Error("第一次开始输入需要转发")
+	}
+	if tracker.Start("1_2", func() {}) {
+		t.Error("间隔之内的开始输入不转发")
+	}
+	if !tracker.Start("1_3", func() {}) {
+		t.Error("不同的key互不影响")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if !tracker.Start("1_2", func() {}) {
+		t.Error("超过间隔需要转发")
+	}
+	if !tracker.Stop("1_2") {
+		t.Error("正在输入的时候停止需要转发")
+	}
+	if tracker.Stop("1_2") {
+		t.Error("没有在输入的时候停止不转发")
+	}
+
+	expired := make(chan string, 1)
+	tracker.Start("2_3", func() {
+		expired <- "2_3"
+	})
+	select {
+	case key := <-expired:
+		if key != "2_3" {
+			t.Errorf("过期的key = %s", key)
+		}
+	case <-time.After(time.Second):
+		t.Error("没有自动过期")
+	}
+	if tracker.Stop("2_3") {
+		t.Error("过期之后状态应该被清除")
+	}
+}